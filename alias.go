@@ -0,0 +1,28 @@
+package rc5
+
+import "unsafe"
+
+// anyOverlap reports whether x and y share memory at any (not necessarily
+// corresponding) index.
+func anyOverlap(x, y []byte) bool {
+	return len(x) > 0 && len(y) > 0 &&
+		uintptr(unsafe.Pointer(&x[0])) <= uintptr(unsafe.Pointer(&y[len(y)-1])) &&
+		uintptr(unsafe.Pointer(&y[0])) <= uintptr(unsafe.Pointer(&x[len(x)-1]))
+}
+
+// inexactOverlap reports whether x and y share memory at any non-corresponding
+// index.  Exact aliasing, where x[i] and y[i] are the same element, is allowed.
+func inexactOverlap(x, y []byte) bool {
+	if len(x) == 0 || len(y) == 0 || &x[0] == &y[0] {
+		return false
+	}
+	return anyOverlap(x, y)
+}
+
+// OverlapSafe reports whether dst and src may be passed together to Encrypt,
+// Decrypt or the block modes in this package: either they do not share memory
+// or they start at the same address.  Otherwise those functions panic, and the
+// caller should encrypt via a temporary buffer instead.
+func OverlapSafe(dst, src []byte) bool {
+	return !inexactOverlap(dst, src)
+}
@@ -0,0 +1,40 @@
+package rc5
+
+import "testing"
+
+func TestOverlapSafe(t *testing.T) {
+
+	buf := make([]byte, 32)
+
+	for _, tst := range []struct {
+		name     string
+		dst, src []byte
+		want     bool
+	}{
+		{"identical", buf[:16], buf[:16], true},
+		{"same start", buf[8:16], buf[8:24], true},
+		{"disjoint", buf[:16], buf[16:], true},
+		{"separate arrays", buf, make([]byte, 32), true},
+		{"empty", buf[:0], buf, true},
+		{"dst ahead", buf[1:17], buf[:16], false},
+		{"src ahead", buf[:16], buf[8:24], false},
+		{"one byte shared", buf[:16], buf[15:31], false},
+	} {
+		if got := OverlapSafe(tst.dst, tst.src); got != tst.want {
+			t.Errorf("OverlapSafe (%s)=%v, want %v", tst.name, got, tst.want)
+		}
+	}
+
+	// it agrees with Encrypt, which panics on an unsafe overlap
+	c, _ := New(tests[0].key)
+	for _, off := range []int{0, 1, 7, 8} {
+		func() {
+			defer func() {
+				if panicked := recover() != nil; panicked == OverlapSafe(buf[off:off+8], buf[:8]) {
+					t.Errorf("Encrypt with dst offset %d: panicked=%v", off, panicked)
+				}
+			}()
+			c.Encrypt(buf[off:off+8], buf[:8])
+		}()
+	}
+}
@@ -0,0 +1,51 @@
+package rc5
+
+// EncryptBlock returns the encryption of the block src.  Taking and returning
+// arrays avoids the length and overlap checks of Encrypt.  The cipher must
+// have a 32-bit word size, so that its block is eight bytes.
+func (c *Cipher) EncryptBlock(src [8]byte) [8]byte {
+
+	c.checkArray()
+
+	var dst [8]byte
+
+	switch {
+	case c.rounds == 12 && useAsm() && !c.bigEndian:
+		encrypt12(&c.rk32[0], &dst[0], &src[0])
+	case c.rounds == 12:
+		c.encrypt32r12(dst[:], src[:])
+	default:
+		c.encrypt32(dst[:], src[:])
+	}
+
+	return dst
+}
+
+// DecryptBlock returns the decryption of the block src.  It is the inverse of
+// EncryptBlock.
+func (c *Cipher) DecryptBlock(src [8]byte) [8]byte {
+
+	c.checkArray()
+
+	var dst [8]byte
+
+	switch {
+	case c.rounds == 12 && useAsm() && !c.bigEndian:
+		decrypt12(&c.rk32[0], &dst[0], &src[0])
+	case c.rounds == 12:
+		c.decrypt32r12(dst[:], src[:])
+	default:
+		c.decrypt32(dst[:], src[:])
+	}
+
+	return dst
+}
+
+func (c *Cipher) checkArray() {
+	if c.wiped {
+		panic("rc5: use of wiped cipher")
+	}
+	if c.w != 32 {
+		panic("rc5: block arrays require an 8-byte block")
+	}
+}
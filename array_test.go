@@ -0,0 +1,67 @@
+package rc5
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"testing"
+)
+
+func TestEncryptBlock(t *testing.T) {
+
+	c12, _ := New(tests[0].key)
+	c20, _ := New32_20(tests[0].key)
+	cbe, _ := NewWithByteOrder(binary.BigEndian, tests[0].key)
+
+	for _, c := range []*Cipher{c12.(*Cipher), c20.(*Cipher), cbe.(*Cipher)} {
+		for i := 0; i < 100; i++ {
+			var src, want [8]byte
+			rand.Read(src[:])
+			c.Encrypt(want[:], src[:])
+
+			got := c.EncryptBlock(src)
+			if got != want {
+				t.Errorf("%v EncryptBlock:\ngot : % 02x\nwant: % 02x", c, got, want)
+			}
+
+			if p := c.DecryptBlock(got); p != src {
+				t.Errorf("%v DecryptBlock:\ngot : % 02x\nwant: % 02x", c, p, src)
+			}
+		}
+	}
+
+	c64, _ := NewWithParameters(64, 12, tests[0].key)
+	defer func() {
+		if recover() == nil {
+			t.Error("EncryptBlock with a 16-byte block did not panic")
+		}
+	}()
+	c64.(*Cipher).EncryptBlock([8]byte{})
+}
+
+// The benchmarks encrypt independent blocks, as when processing tokens, rather
+// than feeding each output back in.
+
+var sinkBlock [8]byte
+
+func BenchmarkEncryptBlock(b *testing.B) {
+	c, _ := New(tests[0].key)
+	rc := c.(*Cipher)
+	var blk [8]byte
+	b.SetBytes(8)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		blk[0] = byte(i)
+		sinkBlock = rc.EncryptBlock(blk)
+	}
+}
+
+func BenchmarkEncryptSlice(b *testing.B) {
+	c, _ := New(tests[0].key)
+	src := make([]byte, 8)
+	b.SetBytes(8)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		src[0] = byte(i)
+		c.Encrypt(sinkBlock[:], src)
+	}
+}
@@ -0,0 +1,52 @@
+package rc5
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func TestAsm(t *testing.T) {
+
+	if !haveAsm {
+		t.Skip("no assembly implementation")
+	}
+
+	for i := 0; i < 100; i++ {
+
+		key := make([]byte, 16)
+		rand.Read(key)
+
+		b, _ := New(key)
+		c := b.(*Cipher)
+
+		src := make([]byte, 8*100)
+		rand.Read(src)
+
+		for j := 0; j < len(src); j += 8 {
+
+			var want, got [8]byte
+
+			c.encrypt32(want[:], src[j:])
+			encrypt12(&c.rk32[0], &got[0], &src[j])
+			if got != want {
+				t.Fatalf("encrypt12(% 02x)=% 02x, want % 02x", src[j:j+8], got[:], want[:])
+			}
+
+			c.decrypt32(want[:], src[j:])
+			decrypt12(&c.rk32[0], &got[0], &src[j])
+			if got != want {
+				t.Fatalf("decrypt12(% 02x)=% 02x, want % 02x", src[j:j+8], got[:], want[:])
+			}
+		}
+	}
+}
+
+func BenchmarkEncryptGo(b *testing.B) {
+	block, _ := New(tests[0].key)
+	c := block.(*Cipher)
+	var buf [8]byte
+	b.SetBytes(8)
+	for i := 0; i < b.N; i++ {
+		c.encrypt32(buf[:], buf[:])
+	}
+}
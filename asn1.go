@@ -0,0 +1,110 @@
+package rc5
+
+import (
+	"encoding/asn1"
+	"errors"
+)
+
+// CBCParametersVersion is the only version of RC5-CBC-Parameters defined by
+// RFC 2040, v1-0.
+const CBCParametersVersion = 16
+
+var (
+	errCBCParameters = errors.New("rc5: invalid RC5-CBC-Parameters")
+	errTrailingData  = errors.New("rc5: trailing data after RC5-CBC-Parameters")
+)
+
+// CBCParameters holds the algorithm parameters for RC5-CBC and RC5-CBC-Pad, as
+// defined in section 6 of RFC 2040:
+//
+//	RC5-CBC-Parameters ::= SEQUENCE {
+//	    version           INTEGER (v1-0(16)),
+//	    rounds            INTEGER (8..127),
+//	    blockSizeInBits   INTEGER (64, 128),
+//	    iv                OCTET STRING OPTIONAL }
+//
+// A nil IV is omitted from the encoding.
+type CBCParameters struct {
+	Version         int
+	Rounds          int
+	BlockSizeInBits int
+	IV              []byte `asn1:"optional"`
+}
+
+func (p *CBCParameters) validate() error {
+
+	if p.Version != CBCParametersVersion || p.Rounds < 8 || p.Rounds > 127 {
+		return errCBCParameters
+	}
+
+	switch p.BlockSizeInBits {
+	case 64, 128:
+	default:
+		return errCBCParameters
+	}
+
+	if p.IV != nil && len(p.IV) != p.BlockSizeInBits/8 {
+		return errIVSize
+	}
+
+	return nil
+}
+
+// MarshalASN1 returns the DER encoding of p.
+func (p *CBCParameters) MarshalASN1() ([]byte, error) {
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(*p)
+}
+
+// ParseASN1 replaces p with the parameters decoded from the DER encoding der.
+func (p *CBCParameters) ParseASN1(der []byte) error {
+
+	var q CBCParameters
+
+	rest, err := asn1.Unmarshal(der, &q)
+	if err != nil {
+		return err
+	}
+
+	if len(rest) != 0 {
+		return errTrailingData
+	}
+
+	if err := q.validate(); err != nil {
+		return err
+	}
+
+	*p = q
+
+	return nil
+}
+
+var errNoIV = errors.New("rc5: RC5-CBC-Parameters has no IV")
+
+// DecryptWithParams decrypts RC5-CBC-Pad ciphertext, as used by CMS, with the
+// rounds, block size and IV given by the DER-encoded RC5-CBC-Parameters
+// paramsDER, and removes the RFC 2040 padding.  A 64-bit block selects a
+// 32-bit word size and a 128-bit block a 64-bit word size.  The parameters
+// must include an IV.
+func DecryptWithParams(key, paramsDER, ciphertext []byte) ([]byte, error) {
+
+	var p CBCParameters
+	if err := p.ParseASN1(paramsDER); err != nil {
+		return nil, err
+	}
+
+	if p.IV == nil {
+		return nil, errNoIV
+	}
+
+	block, err := NewWithParameters(p.BlockSizeInBits/2, p.Rounds, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return decryptCBCPad(block, p.IV, ciphertext)
+}
@@ -0,0 +1,126 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/asn1"
+	"encoding/hex"
+	"testing"
+)
+
+func TestCBCParameters(t *testing.T) {
+
+	for _, tst := range []struct {
+		p   CBCParameters
+		der string
+	}{
+		{
+			CBCParameters{16, 12, 64, []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}},
+			"301302011002010c02014004080102030405060708",
+		},
+		{
+			CBCParameters{16, 16, 128, nil},
+			"300a02011002011002020080",
+		},
+	} {
+		want, _ := hex.DecodeString(tst.der)
+
+		der, err := tst.p.MarshalASN1()
+		if err != nil {
+			t.Fatalf("MarshalASN1 failed: %v", err)
+		}
+
+		if !bytes.Equal(der, want) {
+			t.Errorf("MarshalASN1:\ngot : % 02x\nwant: % 02x", der, want)
+		}
+
+		var p CBCParameters
+		if err := p.ParseASN1(der); err != nil {
+			t.Fatalf("ParseASN1 failed: %v", err)
+		}
+
+		if p.Version != tst.p.Version || p.Rounds != tst.p.Rounds || p.BlockSizeInBits != tst.p.BlockSizeInBits || !bytes.Equal(p.IV, tst.p.IV) || (p.IV == nil) != (tst.p.IV == nil) {
+			t.Errorf("ParseASN1 round trip:\ngot : %+v\nwant: %+v", p, tst.p)
+		}
+	}
+
+	for _, p := range []CBCParameters{
+		{15, 12, 64, nil},
+		{16, 7, 64, nil},
+		{16, 128, 64, nil},
+		{16, 12, 32, nil},
+		{16, 12, 64, make([]byte, 16)},
+	} {
+		if _, err := p.MarshalASN1(); err == nil {
+			t.Errorf("MarshalASN1(%+v) succeeded", p)
+		}
+	}
+
+	good := CBCParameters{16, 12, 64, nil}
+	der, _ := good.MarshalASN1()
+
+	var p CBCParameters
+	if err := p.ParseASN1(append(der, 0)); err != errTrailingData {
+		t.Errorf("ParseASN1 with trailing data: got %v, want %v", err, errTrailingData)
+	}
+
+	if err := p.ParseASN1(der[:len(der)-1]); err == nil {
+		t.Errorf("ParseASN1 of truncated encoding succeeded")
+	}
+}
+
+func TestDecryptWithParams(t *testing.T) {
+
+	key := seq(0, 16)
+	plain := []byte("content encrypted with RC5-CBC-Pad")
+
+	for _, tst := range []struct {
+		wordSize, rounds int
+	}{
+		{32, 12},
+		{32, 16},
+		{64, 8},
+		{64, 20},
+	} {
+		bs := tst.wordSize / 4
+
+		p := CBCParameters{CBCParametersVersion, tst.rounds, 8 * bs, seq(0x40, bs)}
+		der, err := p.MarshalASN1()
+		if err != nil {
+			t.Fatalf("MarshalASN1(%+v) failed: %v", p, err)
+		}
+
+		block, _ := NewWithParameters(tst.wordSize, tst.rounds, key)
+
+		padLen := bs - len(plain)%bs
+		ct := append(bytes.Clone(plain), bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+		cipher.NewCBCEncrypter(block, p.IV).CryptBlocks(ct, ct)
+
+		got, err := DecryptWithParams(key, der, ct)
+		if err != nil {
+			t.Fatalf("DecryptWithParams(RC5-%d/%d) failed: %v", tst.wordSize, tst.rounds, err)
+		}
+
+		if !bytes.Equal(got, plain) {
+			t.Errorf("DecryptWithParams(RC5-%d/%d):\ngot : % 02x\nwant: % 02x", tst.wordSize, tst.rounds, got, plain)
+		}
+	}
+
+	ct := make([]byte, 16)
+
+	for _, p := range []CBCParameters{
+		{16, 12, 32, make([]byte, 4)},
+		{16, 7, 64, make([]byte, 8)},
+		{16, 128, 64, make([]byte, 8)},
+	} {
+		der, _ := asn1.Marshal(p)
+		if _, err := DecryptWithParams(key, der, ct); err != errCBCParameters {
+			t.Errorf("DecryptWithParams(%+v): got %v, want %v", p, err, errCBCParameters)
+		}
+	}
+
+	der, _ := (&CBCParameters{16, 12, 64, nil}).MarshalASN1()
+	if _, err := DecryptWithParams(key, der, ct); err != errNoIV {
+		t.Errorf("DecryptWithParams without an IV: got %v, want %v", err, errNoIV)
+	}
+}
@@ -0,0 +1,57 @@
+package rc5
+
+import (
+	"math/bits"
+	"math/rand/v2"
+)
+
+// Avalanche estimates how well RC5-wordSize/rounds diffuses its input.  For
+// each of samples random keys and blocks it flips one random bit of the block
+// and counts the ciphertext bits that change, returning the mean fraction
+// flipped.  A well-diffused cipher gives a value close to 0.5; too few rounds
+// give markedly less.  The keys are 16 bytes and come from a non-cryptographic
+// generator, so this is only an analysis aid.
+//
+// Avalanche returns 0 if samples is not positive and panics if wordSize or
+// rounds is not valid for NewWithParameters.
+func Avalanche(wordSize, rounds, samples int) float64 {
+
+	key := make([]byte, 16)
+	if err := checkParameters(wordSize, rounds, key); err != nil {
+		panic(err)
+	}
+
+	if samples <= 0 {
+		return 0
+	}
+
+	bs := BlockSizeFor(wordSize)
+	src := make([]byte, bs)
+	dst := make([]byte, 2*bs)
+
+	var flipped int
+	for i := 0; i < samples; i++ {
+		fillRandom(key)
+		fillRandom(src)
+
+		c, _ := newCipher(params{wordSize: wordSize, rounds: rounds}, key)
+		c.Encrypt(dst[:bs], src)
+
+		bit := rand.IntN(8 * bs)
+		src[bit/8] ^= 1 << (bit % 8)
+		c.Encrypt(dst[bs:], src)
+
+		for j := 0; j < bs; j++ {
+			flipped += bits.OnesCount8(dst[j] ^ dst[bs+j])
+		}
+	}
+
+	return float64(flipped) / float64(samples*8*bs)
+}
+
+// fillRandom fills b from the non-cryptographic math/rand generator.
+func fillRandom(b []byte) {
+	for i := range b {
+		b[i] = byte(rand.Uint32())
+	}
+}
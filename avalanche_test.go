@@ -0,0 +1,30 @@
+package rc5
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAvalanche(t *testing.T) {
+
+	for _, w := range []int{16, 32, 64} {
+		if got := Avalanche(w, 12, 2000); math.Abs(got-0.5) > 0.02 {
+			t.Errorf("Avalanche(%d, 12): got %.3f, want about 0.5", w, got)
+		}
+
+		if got := Avalanche(w, 1, 2000); got > 0.4 {
+			t.Errorf("Avalanche(%d, 1): got %.3f, want well below 0.5", w, got)
+		}
+	}
+
+	if got := Avalanche(32, 12, 0); got != 0 {
+		t.Errorf("Avalanche with no samples: got %v, want 0", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Avalanche with word size 24 did not panic")
+		}
+	}()
+	Avalanche(24, 12, 1)
+}
@@ -0,0 +1,85 @@
+package rc5
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/big"
+)
+
+var (
+	errBigInt        = errors.New("rc5: invalid encrypted integer")
+	errBigIntTooLong = errors.New("rc5: integer too large to encrypt")
+)
+
+// EncryptBigInt encrypts v with RC5-32/12/16 in the RC5-CBC-Pad mode under a
+// random IV, which it returns in front of the ciphertext.  The plaintext is a
+// sign byte, 0 or 1 for a negative v, the big-endian 32-bit length of the
+// magnitude, and the big-endian magnitude, so zero is encrypted as an empty
+// magnitude.  The ciphertext is not authenticated.  WithRand selects the
+// source of the IV.
+func EncryptBigInt(key []byte, v *big.Int, opts ...RandOption) ([]byte, error) {
+
+	block, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64((v.BitLen()+7)/8) > math.MaxUint32 {
+		return nil, errBigIntTooLong
+	}
+
+	mag := v.Bytes()
+
+	plain := make([]byte, 5, 5+len(mag))
+	if v.Sign() < 0 {
+		plain[0] = 1
+	}
+	binary.BigEndian.PutUint32(plain[1:], uint32(len(mag)))
+	plain = append(plain, mag...)
+
+	iv := make([]byte, BlockSize)
+	if err := readRandom(iv, opts); err != nil {
+		return nil, err
+	}
+
+	return encryptCBCPadAppend(iv, block, iv, plain)
+}
+
+// DecryptBigInt decrypts an integer encrypted by EncryptBigInt.  Each integer
+// has exactly one valid plaintext encoding; negative zero and magnitudes with
+// leading zero bytes are rejected.
+func DecryptBigInt(key, data []byte) (*big.Int, error) {
+
+	block, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < BlockSize {
+		return nil, errInputSize
+	}
+
+	plain, err := decryptCBCPad(block, data[:BlockSize], data[BlockSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(plain) < 5 || plain[0] > 1 || uint64(binary.BigEndian.Uint32(plain[1:])) != uint64(len(plain)-5) {
+		return nil, errBigInt
+	}
+
+	// only the encoding EncryptBigInt produces is accepted: no negative zero
+	// and no leading zero bytes in the magnitude
+	mag := plain[5:]
+	if (plain[0] == 1 && len(mag) == 0) || (len(mag) > 0 && mag[0] == 0) {
+		return nil, errBigInt
+	}
+
+	v := new(big.Int).SetBytes(mag)
+	if plain[0] == 1 {
+		v.Neg(v)
+	}
+
+	return v, nil
+}
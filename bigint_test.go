@@ -0,0 +1,69 @@
+package rc5
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestEncryptBigInt(t *testing.T) {
+
+	key := tests[0].key
+
+	// 300 digits
+	huge, _ := new(big.Int).SetString(strings.Repeat("1234567890", 30), 10)
+
+	for _, v := range []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(-1),
+		big.NewInt(255),
+		big.NewInt(256),
+		new(big.Int).Lsh(big.NewInt(1), 64),
+		huge,
+		new(big.Int).Neg(huge),
+	} {
+		ct, err := EncryptBigInt(key, v)
+		if err != nil {
+			t.Fatalf("EncryptBigInt(%v) failed: %v", v, err)
+		}
+
+		if len(ct)%BlockSize != 0 {
+			t.Errorf("EncryptBigInt(%v): %d bytes is not a whole number of blocks", v, len(ct))
+		}
+
+		got, err := DecryptBigInt(key, ct)
+		if err != nil {
+			t.Fatalf("DecryptBigInt(%v) failed: %v", v, err)
+		}
+
+		if got.Cmp(v) != 0 {
+			t.Errorf("EncryptBigInt round trip: got %v, want %v", got, v)
+		}
+	}
+
+	if len(huge.String()) != 300 {
+		t.Errorf("test integer has %d digits, want 300", len(huge.String()))
+	}
+
+	// well-padded plaintexts that EncryptBigInt never produces
+	for _, tst := range []struct {
+		name  string
+		plain []byte
+	}{
+		{"bad length", []byte{0, 0, 0, 0, 2, 1}},
+		{"bad sign", []byte{2, 0, 0, 0, 1, 1}},
+		{"negative zero", []byte{1, 0, 0, 0, 0}},
+		{"leading zero", []byte{0, 0, 0, 0, 2, 0, 1}},
+		{"negative leading zero", []byte{1, 0, 0, 0, 1, 0}},
+	} {
+		bad, _ := EncryptCBCPadAppend(seq(0x40, 8), key, seq(0x40, 8), tst.plain)
+		if _, err := DecryptBigInt(key, bad); err != errBigInt {
+			t.Errorf("DecryptBigInt with %s: got %v, want %v", tst.name, err, errBigInt)
+		}
+	}
+
+	if _, err := DecryptBigInt(key, seq(0, 7)); err != errInputSize {
+		t.Errorf("DecryptBigInt of 7 bytes: got %v, want %v", err, errInputSize)
+	}
+}
@@ -0,0 +1,116 @@
+package rc5
+
+func (c *Cipher) checkBlocks(dst, src []byte) {
+	if c.wiped {
+		panic("rc5: use of wiped cipher")
+	}
+	if len(src)%c.BlockSize() != 0 {
+		panic("rc5: input not full blocks")
+	}
+	if len(dst) < len(src) {
+		panic("rc5: output smaller than input")
+	}
+	if inexactOverlap(dst[:len(src)], src) {
+		panic("rc5: invalid buffer overlap")
+	}
+}
+
+// EncryptBlocks encrypts each block of src into the corresponding block of dst
+// in ECB fashion.  The length of src must be a multiple of the block size and
+// dst must be at least as long as src.
+func (c *Cipher) EncryptBlocks(dst, src []byte) {
+	c.checkBlocks(dst, src)
+
+	bs := c.BlockSize()
+
+	switch c.w {
+	case 16:
+		for ; len(src) > 0; src, dst = src[bs:], dst[bs:] {
+			c.encrypt16(dst, src)
+		}
+	case 32:
+		c.encryptBlocks32(dst, src)
+	case 64:
+		for ; len(src) > 0; src, dst = src[bs:], dst[bs:] {
+			c.encrypt64(dst, src)
+		}
+	}
+}
+
+func (c *Cipher) encryptBlocks32(dst, src []byte) {
+
+	if useAsm() && c.rounds == 12 && !c.bigEndian {
+		for i := 0; i+8 <= len(src); i += 8 {
+			encrypt12(&c.rk32[0], &dst[i], &src[i])
+		}
+		return
+	}
+
+	if c.rounds == 12 {
+		for i := 0; i+8 <= len(src); i += 8 {
+			c.encrypt32r12(dst[i:i+8:i+8], src[i:i+8:i+8])
+		}
+		return
+	}
+
+	for i := 0; i+8 <= len(src); i += 8 {
+
+		s := src[i : i+8 : i+8]
+		d := dst[i : i+8 : i+8]
+
+		A, B := encryptWords(c.rk32, c.load32(s[:4]), c.load32(s[4:]))
+
+		c.store32(d[:4], A)
+		c.store32(d[4:], B)
+	}
+}
+
+// DecryptBlocks decrypts each block of src into the corresponding block of dst
+// in ECB fashion.  The length of src must be a multiple of the block size and
+// dst must be at least as long as src.
+func (c *Cipher) DecryptBlocks(dst, src []byte) {
+	c.checkBlocks(dst, src)
+
+	bs := c.BlockSize()
+
+	switch c.w {
+	case 16:
+		for ; len(src) > 0; src, dst = src[bs:], dst[bs:] {
+			c.decrypt16(dst, src)
+		}
+	case 32:
+		c.decryptBlocks32(dst, src)
+	case 64:
+		for ; len(src) > 0; src, dst = src[bs:], dst[bs:] {
+			c.decrypt64(dst, src)
+		}
+	}
+}
+
+func (c *Cipher) decryptBlocks32(dst, src []byte) {
+
+	if useAsm() && c.rounds == 12 && !c.bigEndian {
+		for i := 0; i+8 <= len(src); i += 8 {
+			decrypt12(&c.rk32[0], &dst[i], &src[i])
+		}
+		return
+	}
+
+	if c.rounds == 12 {
+		for i := 0; i+8 <= len(src); i += 8 {
+			c.decrypt32r12(dst[i:i+8:i+8], src[i:i+8:i+8])
+		}
+		return
+	}
+
+	for i := 0; i+8 <= len(src); i += 8 {
+
+		s := src[i : i+8 : i+8]
+		d := dst[i : i+8 : i+8]
+
+		A, B := decryptWords(c.rk32, c.load32(s[:4]), c.load32(s[4:]))
+
+		c.store32(d[:4], A)
+		c.store32(d[4:], B)
+	}
+}
@@ -0,0 +1,143 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestEncryptBlocks(t *testing.T) {
+
+	for _, w := range []int{16, 32, 64} {
+
+		b, _ := NewWithParameters(w, 12, tests[0].key)
+		c := b.(*Cipher)
+		bs := c.BlockSize()
+
+		src := make([]byte, 10*bs)
+		rand.Read(src)
+
+		want := make([]byte, len(src))
+		for i := 0; i < len(src); i += bs {
+			c.Encrypt(want[i:], src[i:])
+		}
+
+		got := make([]byte, len(src))
+		c.EncryptBlocks(got, src)
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("RC5-%d EncryptBlocks differs from Encrypt:\ngot : % 02x\nwant: % 02x", w, got, want)
+		}
+
+		c.EncryptBlocks(src, src)
+		if !bytes.Equal(src, want) {
+			t.Errorf("RC5-%d in-place EncryptBlocks differs from Encrypt:\ngot : % 02x\nwant: % 02x", w, src, want)
+		}
+	}
+}
+
+func TestEncryptBlocksPanics(t *testing.T) {
+
+	b, _ := New(tests[0].key)
+	c := b.(*Cipher)
+
+	defer func() {
+		if r := recover(); r != "rc5: input not full blocks" {
+			t.Errorf("EncryptBlocks with partial block: got panic %v", r)
+		}
+	}()
+
+	c.EncryptBlocks(make([]byte, 12), make([]byte, 12))
+}
+
+func TestDecryptBlocks(t *testing.T) {
+
+	for _, w := range []int{16, 32, 64} {
+		for _, r := range []int{0, 12, 20} {
+
+			b, _ := NewWithParameters(w, r, tests[0].key)
+			c := b.(*Cipher)
+			bs := c.BlockSize()
+
+			src := make([]byte, 10*bs)
+			rand.Read(src)
+
+			want := make([]byte, len(src))
+			for i := 0; i < len(src); i += bs {
+				c.Decrypt(want[i:], src[i:])
+			}
+
+			got := make([]byte, len(src))
+			c.DecryptBlocks(got, src)
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("RC5-%d/%d DecryptBlocks differs from Decrypt:\ngot : % 02x\nwant: % 02x", w, r, got, want)
+			}
+
+			c.DecryptBlocks(src, src)
+			if !bytes.Equal(src, want) {
+				t.Errorf("RC5-%d/%d in-place DecryptBlocks differs from Decrypt:\ngot : % 02x\nwant: % 02x", w, r, src, want)
+			}
+		}
+	}
+}
+
+func TestDecryptBlocksPanics(t *testing.T) {
+
+	b, _ := New(tests[0].key)
+	c := b.(*Cipher)
+
+	defer func() {
+		if r := recover(); r != "rc5: input not full blocks" {
+			t.Errorf("DecryptBlocks with partial block: got panic %v", r)
+		}
+	}()
+
+	c.DecryptBlocks(make([]byte, 12), make([]byte, 12))
+}
+
+func BenchmarkEncryptBlocks(b *testing.B) {
+	block, _ := New(tests[0].key)
+	c := block.(*Cipher)
+	buf := make([]byte, 8192)
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.EncryptBlocks(buf, buf)
+	}
+}
+
+func BenchmarkEncryptSingleBlocks(b *testing.B) {
+	block, _ := New(tests[0].key)
+	buf := make([]byte, 8192)
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < len(buf); j += 8 {
+			block.Encrypt(buf[j:j+8], buf[j:j+8])
+		}
+	}
+}
+
+func BenchmarkDecryptBlocks(b *testing.B) {
+	block, _ := New(tests[0].key)
+	c := block.(*Cipher)
+	buf := make([]byte, 8192)
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.DecryptBlocks(buf, buf)
+	}
+}
+
+func BenchmarkDecryptSingleBlocks(b *testing.B) {
+	block, _ := New(tests[0].key)
+	buf := make([]byte, 8192)
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < len(buf); j += 8 {
+			block.Decrypt(buf[j:j+8], buf[j:j+8])
+		}
+	}
+}
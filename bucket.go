@@ -0,0 +1,50 @@
+package rc5
+
+import "errors"
+
+var errBucket = errors.New("rc5: bucket size must be a positive multiple of the block size")
+
+// SealBucketed encrypts plaintext with RC5-32/12/16 in the RC5-CBC-Pad mode,
+// first padding it so the ciphertext length is the smallest multiple of bucket
+// that fits, to hide the exact length of the plaintext.  The padding is a 0x80
+// byte followed by zeros, as in ISO/IEC 7816-4, and leaves one byte for the
+// CBC-Pad padding, so the ciphertext is always at least len(plaintext)+2 bytes.
+// The bucket size must be a positive multiple of the block size.  Only the
+// length is hidden; the ciphertext is not authenticated.
+func SealBucketed(key, iv, plaintext []byte, bucket int) ([]byte, error) {
+
+	if bucket <= 0 || bucket%BlockSize != 0 {
+		return nil, errBucket
+	}
+
+	// the plaintext, the 0x80 marker and the one byte of CBC-Pad padding
+	total := (len(plaintext) + 2 + bucket - 1) / bucket * bucket
+
+	padded := make([]byte, total-1)
+	copy(padded, plaintext)
+	padded[len(plaintext)] = 0x80
+
+	return EncryptCBCPadAppend(padded[:0], key, iv, padded)
+}
+
+// OpenBucketed decrypts ciphertext produced by SealBucketed and removes both
+// paddings, recovering the original plaintext exactly.
+func OpenBucketed(key, iv, ciphertext []byte) ([]byte, error) {
+
+	padded, err := DecryptCBCPad(key, iv, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(padded) - 1; i >= 0; i-- {
+		switch padded[i] {
+		case 0x80:
+			return padded[:i], nil
+		case 0:
+		default:
+			return nil, errPadding
+		}
+	}
+
+	return nil, errPadding
+}
@@ -0,0 +1,54 @@
+package rc5
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSealBucketed(t *testing.T) {
+
+	key := tests[0].key
+	iv := seq(0x40, 8)
+
+	for _, bucket := range []int{8, 16, 64, 256} {
+		for _, l := range []int{0, 1, 6, 7, 8, 14, 15, 16, 63, 100, 254, 255, 1000} {
+
+			plain := bytes.Repeat([]byte{0x80}, l)
+			if l > 0 {
+				plain[0] = 0
+			}
+
+			ct, err := SealBucketed(key, iv, plain, bucket)
+			if err != nil {
+				t.Fatalf("SealBucketed(%d bytes, bucket %d) failed: %v", l, bucket, err)
+			}
+
+			if want := (l + 2 + bucket - 1) / bucket * bucket; len(ct) != want {
+				t.Errorf("SealBucketed(%d bytes, bucket %d) gave %d bytes, want %d", l, bucket, len(ct), want)
+			}
+
+			p, err := OpenBucketed(key, iv, ct)
+			if err != nil {
+				t.Fatalf("OpenBucketed(%d bytes, bucket %d) failed: %v", l, bucket, err)
+			}
+
+			if !bytes.Equal(p, plain) {
+				t.Errorf("OpenBucketed(%d bytes, bucket %d):\ngot : % 02x\nwant: % 02x", l, bucket, p, plain)
+			}
+		}
+	}
+
+	for _, bucket := range []int{0, -8, 4, 12} {
+		if _, err := SealBucketed(key, iv, nil, bucket); err != errBucket {
+			t.Errorf("SealBucketed(bucket %d): got %v, want %v", bucket, err, errBucket)
+		}
+	}
+
+	// CBC-Pad ciphertext without the marker is rejected
+	for _, plain := range [][]byte{make([]byte, 7), {1, 2, 3}} {
+		ct, _ := EncryptCBCPad(key, iv, plain)
+		if _, err := OpenBucketed(key, iv, ct); err != errPadding {
+			t.Errorf("OpenBucketed(% 02x without marker): got %v, want %v", plain, err, errPadding)
+		}
+	}
+}
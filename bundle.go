@@ -0,0 +1,75 @@
+package rc5
+
+import "crypto/cipher"
+
+// An RC5 is an RC5-32/12/16 cipher.Block with factories for the common modes,
+// so the key is expanded once and not passed again for each message.  It has
+// all the methods of Cipher.
+type RC5 struct {
+	*Cipher
+}
+
+// NewRC5 returns an RC5 using the given 16-byte key.
+func NewRC5(key []byte) (*RC5, error) {
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+	return &RC5{b.(*Cipher)}, nil
+}
+
+// CBCEncrypter returns a CBC encrypter as from NewCBCEncrypter.  The length of
+// iv must be the same as the block size.
+func (r *RC5) CBCEncrypter(iv []byte) (cipher.BlockMode, error) {
+	x, err := newCBCBlock(r.Cipher, iv)
+	if err != nil {
+		return nil, err
+	}
+	return (*cbcEncrypter)(x), nil
+}
+
+// CBCDecrypter returns a CBC decrypter as from NewCBCDecrypter.  The length of
+// iv must be the same as the block size.
+func (r *RC5) CBCDecrypter(iv []byte) (cipher.BlockMode, error) {
+	x, err := newCBCBlock(r.Cipher, iv)
+	if err != nil {
+		return nil, err
+	}
+	return (*cbcDecrypter)(x), nil
+}
+
+// CTR returns a counter mode stream as from NewCTR.  The length of iv must be
+// the same as the block size.
+func (r *RC5) CTR(iv []byte) (cipher.Stream, error) {
+	x, err := newCTR(r.Cipher, iv)
+	if err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// OFB returns an output feedback mode stream as from NewOFB.  The length of iv
+// must be the same as the block size.
+func (r *RC5) OFB(iv []byte) (cipher.Stream, error) {
+	if len(iv) != r.BlockSize() {
+		return nil, errIVSize
+	}
+	return cipher.NewOFB(r.Cipher, iv), nil
+}
+
+// blockView exposes only the cipher.Block methods of a cipher.
+type blockView struct {
+	c *Cipher
+}
+
+func (b blockView) BlockSize() int          { return b.c.BlockSize() }
+func (b blockView) Encrypt(dst, src []byte) { b.c.Encrypt(dst, src) }
+func (b blockView) Decrypt(dst, src []byte) { b.c.Decrypt(dst, src) }
+
+// Block returns a view of r with only the methods of cipher.Block, for passing
+// to code which should not be able to call Wipe, Rekey or the other methods of
+// Cipher, even with a type assertion.  The view shares r's key schedule, so it
+// stops working if r is wiped and follows r if it is rekeyed.
+func (r *RC5) Block() cipher.Block {
+	return blockView{r.Cipher}
+}
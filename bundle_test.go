@@ -0,0 +1,131 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"testing"
+)
+
+var _ cipher.Block = (*RC5)(nil)
+
+func TestRC5Factories(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+	r, err := NewRC5(key)
+	if err != nil {
+		t.Fatalf("NewRC5 failed: %v", err)
+	}
+
+	block, _ := New(key)
+
+	plain := make([]byte, 64)
+	for i := range plain {
+		plain[i] = byte(i)
+	}
+
+	var ct [8]byte
+	r.Encrypt(ct[:], tests[0].plain)
+	if !bytes.Equal(ct[:], tests[0].cipher) {
+		t.Errorf("Encrypt:\ngot : % 02x\nwant: % 02x", ct[:], tests[0].cipher)
+	}
+
+	enc, err := r.CBCEncrypter(iv)
+	if err != nil {
+		t.Fatalf("CBCEncrypter failed: %v", err)
+	}
+	got := make([]byte, len(plain))
+	enc.CryptBlocks(got, plain)
+
+	want := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(want, plain)
+	if !bytes.Equal(got, want) {
+		t.Errorf("CBCEncrypter:\ngot : % 02x\nwant: % 02x", got, want)
+	}
+
+	dec, err := r.CBCDecrypter(iv)
+	if err != nil {
+		t.Fatalf("CBCDecrypter failed: %v", err)
+	}
+	dec.CryptBlocks(got, want)
+	if !bytes.Equal(got, plain) {
+		t.Errorf("CBCDecrypter:\ngot : % 02x\nwant: % 02x", got, plain)
+	}
+
+	for _, tst := range []struct {
+		name string
+		mk   func([]byte) (cipher.Stream, error)
+		std  cipher.Stream
+	}{
+		{"CTR", r.CTR, cipher.NewCTR(block, iv)},
+		{"OFB", r.OFB, cipher.NewOFB(block, iv)},
+	} {
+		s, err := tst.mk(iv)
+		if err != nil {
+			t.Fatalf("%s failed: %v", tst.name, err)
+		}
+
+		got := make([]byte, len(plain))
+		s.XORKeyStream(got, plain)
+
+		want := make([]byte, len(plain))
+		tst.std.XORKeyStream(want, plain)
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s:\ngot : % 02x\nwant: % 02x", tst.name, got, want)
+		}
+
+		if _, err := tst.mk(iv[:7]); err != errIVSize {
+			t.Errorf("%s with short IV: got %v, want %v", tst.name, err, errIVSize)
+		}
+	}
+
+	if _, err := r.CBCEncrypter(iv[:7]); err != errIVSize {
+		t.Errorf("CBCEncrypter with short IV: got %v, want %v", err, errIVSize)
+	}
+
+	if _, err := r.CBCDecrypter(nil); err != errIVSize {
+		t.Errorf("CBCDecrypter with no IV: got %v, want %v", err, errIVSize)
+	}
+
+	if _, err := NewRC5(key[:8]); err == nil {
+		t.Error("NewRC5 accepted an 8-byte key")
+	}
+}
+
+func TestRC5Block(t *testing.T) {
+
+	key := tests[0].key
+	iv := seq(0x40, 8)
+	plain := seq(0, 50)
+
+	r, _ := NewRC5(key)
+	b := r.Block()
+
+	switch b.(type) {
+	case *Cipher, Wiper, interface{ Rekey([]byte) error }:
+		t.Errorf("Block() returned %T, which exposes more than cipher.Block", b)
+	}
+
+	got := make([]byte, len(plain))
+	cipher.NewCTR(b, iv).XORKeyStream(got, plain)
+
+	want := make([]byte, len(plain))
+	s, _ := NewCTR(key, iv)
+	s.XORKeyStream(want, plain)
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("cipher.NewCTR over Block():\ngot : % 02x\nwant: % 02x", got, want)
+	}
+
+	if b.BlockSize() != BlockSize {
+		t.Errorf("Block().BlockSize()=%d, want %d", b.BlockSize(), BlockSize)
+	}
+
+	var pt [8]byte
+	b.Decrypt(pt[:], tests[0].cipher)
+	if !bytes.Equal(pt[:], tests[0].plain) {
+		t.Errorf("Block().Decrypt:\ngot : % 02x\nwant: % 02x", pt[:], tests[0].plain)
+	}
+}
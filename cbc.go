@@ -0,0 +1,204 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+type cbc struct {
+	b  *Cipher
+	bs int
+	iv []byte
+}
+
+func newCBC(key, iv []byte) (*cbc, error) {
+
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCBCBlock(b.(*Cipher), iv)
+}
+
+func newCBCBlock(b *Cipher, iv []byte) (*cbc, error) {
+
+	bs := b.BlockSize()
+
+	if len(iv) != bs {
+		return nil, errIVSize
+	}
+
+	return &cbc{
+		b:  b,
+		bs: bs,
+		iv: append([]byte(nil), iv...),
+	}, nil
+}
+
+// An IVSetter is a cipher.BlockMode whose chaining value can be reset, so one
+// mode can be reused for several messages without expanding the key again.
+// The modes returned by NewCBCEncrypter and NewCBCDecrypter implement
+// IVSetter.
+type IVSetter interface {
+	cipher.BlockMode
+
+	// SetIV resets the chaining value to iv, whose length must be the same as
+	// the block size.
+	SetIV(iv []byte) error
+}
+
+// A CheckedBlockMode is a cipher.BlockMode which can report invalid input as
+// an error rather than panicking, for processing untrusted input without
+// recover.  The modes returned by NewCBCEncrypter and NewCBCDecrypter
+// implement CheckedBlockMode.
+type CheckedBlockMode interface {
+	cipher.BlockMode
+
+	// CryptBlocksErr is like CryptBlocks, but returns an error without
+	// touching dst if the length of src is not a multiple of the block size,
+	// dst is shorter than src, or the two overlap inexactly.
+	CryptBlocksErr(dst, src []byte) error
+}
+
+var errOutputSize = errors.New("rc5: output smaller than input")
+
+func (x *cbc) check(dst, src []byte) error {
+	if len(src)%x.bs != 0 {
+		return errInputSize
+	}
+	if len(dst) < len(src) {
+		return errOutputSize
+	}
+	if inexactOverlap(dst[:len(src)], src) {
+		return errOverlap
+	}
+	return nil
+}
+
+func (x *cbc) setIV(iv []byte) error {
+	if len(iv) != x.bs {
+		return errIVSize
+	}
+	copy(x.iv, iv)
+	return nil
+}
+
+type cbcEncrypter cbc
+
+// NewCBCEncrypter returns a cipher.BlockMode which encrypts with RC5-32/12/16
+// in the RC5-CBC mode of RFC 2040.  No padding is applied, so the input to
+// CryptBlocks must be a multiple of the block size.  The length of iv must be
+// the same as the block size.
+func NewCBCEncrypter(key, iv []byte) (cipher.BlockMode, error) {
+	c, err := newCBC(key, iv)
+	if err != nil {
+		return nil, err
+	}
+	return (*cbcEncrypter)(c), nil
+}
+
+func (x *cbcEncrypter) BlockSize() int { return x.bs }
+
+func (x *cbcEncrypter) SetIV(iv []byte) error { return (*cbc)(x).setIV(iv) }
+
+func (x *cbcEncrypter) CryptBlocksErr(dst, src []byte) error {
+	if err := (*cbc)(x).check(dst, src); err != nil {
+		return err
+	}
+	x.CryptBlocks(dst, src)
+	return nil
+}
+
+func (x *cbcEncrypter) CryptBlocks(dst, src []byte) {
+
+	if len(src)%x.bs != 0 {
+		panic("rc5: input not full blocks")
+	}
+
+	if len(dst) < len(src) {
+		panic("rc5: output smaller than input")
+	}
+
+	if inexactOverlap(dst[:len(src)], src) {
+		panic("rc5: invalid buffer overlap")
+	}
+
+	iv := x.iv
+
+	for len(src) > 0 {
+		subtle.XORBytes(dst[:x.bs], src[:x.bs], iv)
+		x.b.Encrypt(dst[:x.bs], dst[:x.bs])
+
+		iv = dst[:x.bs]
+		src = src[x.bs:]
+		dst = dst[x.bs:]
+	}
+
+	copy(x.iv, iv)
+}
+
+type cbcDecrypter cbc
+
+// cbcBatch is the number of bytes the CBC decrypter passes to the block
+// function at once.
+const cbcBatch = 512
+
+// NewCBCDecrypter returns a cipher.BlockMode which decrypts with RC5-32/12/16
+// in the RC5-CBC mode of RFC 2040.  The length of iv must be the same as the
+// block size.
+func NewCBCDecrypter(key, iv []byte) (cipher.BlockMode, error) {
+	c, err := newCBC(key, iv)
+	if err != nil {
+		return nil, err
+	}
+	return (*cbcDecrypter)(c), nil
+}
+
+func (x *cbcDecrypter) BlockSize() int { return x.bs }
+
+func (x *cbcDecrypter) SetIV(iv []byte) error { return (*cbc)(x).setIV(iv) }
+
+func (x *cbcDecrypter) CryptBlocksErr(dst, src []byte) error {
+	if err := (*cbc)(x).check(dst, src); err != nil {
+		return err
+	}
+	x.CryptBlocks(dst, src)
+	return nil
+}
+
+func (x *cbcDecrypter) CryptBlocks(dst, src []byte) {
+
+	if len(src)%x.bs != 0 {
+		panic("rc5: input not full blocks")
+	}
+
+	if len(dst) < len(src) {
+		panic("rc5: output smaller than input")
+	}
+
+	if inexactOverlap(dst[:len(src)], src) {
+		panic("rc5: invalid buffer overlap")
+	}
+
+	// Unlike encryption, every block can be decrypted independently, so
+	// decrypt a batch at a time with the block function and apply the
+	// chaining afterwards.
+	var saved [cbcBatch]byte
+
+	for len(src) > 0 {
+		n := min(len(src), cbcBatch)
+
+		// save the ciphertext in case dst and src are the same
+		copy(saved[:n], src[:n])
+
+		x.b.DecryptBlocks(dst[:n], src[:n])
+		subtle.XORBytes(dst[:x.bs], dst[:x.bs], x.iv)
+		subtle.XORBytes(dst[x.bs:n], dst[x.bs:n], saved[:n-x.bs])
+
+		copy(x.iv, saved[n-x.bs:n])
+		src = src[n:]
+		dst = dst[n:]
+	}
+}
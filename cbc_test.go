@@ -0,0 +1,251 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+)
+
+func TestCBC(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+	block, _ := New(key)
+
+	for _, l := range []int{0, 8, 16, 64} {
+
+		plain := make([]byte, l)
+		for i := range plain {
+			plain[i] = byte(i)
+		}
+
+		want := make([]byte, l)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(want, plain)
+
+		enc, err := NewCBCEncrypter(key, iv)
+		if err != nil {
+			t.Fatalf("NewCBCEncrypter failed: %v", err)
+		}
+
+		// encrypt in two calls to check the chaining value is carried over
+		ct := make([]byte, l)
+		enc.CryptBlocks(ct[:l/16*8], plain[:l/16*8])
+		enc.CryptBlocks(ct[l/16*8:], plain[l/16*8:])
+
+		if !bytes.Equal(ct, want) {
+			t.Errorf("CBC encrypt failed (len=%d):\ngot : % 02x\nwant: % 02x", l, ct, want)
+		}
+
+		dec, err := NewCBCDecrypter(key, iv)
+		if err != nil {
+			t.Fatalf("NewCBCDecrypter failed: %v", err)
+		}
+
+		p := make([]byte, l)
+		dec.CryptBlocks(p[:l/16*8], ct[:l/16*8])
+		dec.CryptBlocks(p[l/16*8:], ct[l/16*8:])
+
+		if !bytes.Equal(p, plain) {
+			t.Errorf("CBC decrypt failed (len=%d):\ngot : % 02x\nwant: % 02x", l, p, plain)
+		}
+
+		// in place
+		dec, _ = NewCBCDecrypter(key, iv)
+		dec.CryptBlocks(ct, ct)
+		if !bytes.Equal(ct, plain) {
+			t.Errorf("CBC in-place decrypt failed (len=%d):\ngot : % 02x\nwant: % 02x", l, ct, plain)
+		}
+	}
+}
+
+func TestCBCInvalid(t *testing.T) {
+
+	key := tests[0].key
+
+	if _, err := NewCBCEncrypter(key, make([]byte, 7)); err != errIVSize {
+		t.Errorf("NewCBCEncrypter with short IV: got %v, want %v", err, errIVSize)
+	}
+
+	if _, err := NewCBCDecrypter(key, make([]byte, 9)); err != errIVSize {
+		t.Errorf("NewCBCDecrypter with long IV: got %v, want %v", err, errIVSize)
+	}
+
+	enc, _ := NewCBCEncrypter(key, make([]byte, 8))
+
+	defer func() {
+		if r := recover(); r != "rc5: input not full blocks" {
+			t.Errorf("CryptBlocks with partial block: got panic %v", r)
+		}
+	}()
+
+	enc.CryptBlocks(make([]byte, 12), make([]byte, 12))
+}
+
+func TestCBCCryptBlocksErr(t *testing.T) {
+
+	key := tests[0].key
+	iv := seq(0x10, 8)
+	plain := seq(0, 32)
+
+	want := make([]byte, len(plain))
+	enc, _ := NewCBCEncrypter(key, iv)
+	enc.CryptBlocks(want, plain)
+
+	for _, newMode := range []func(key, iv []byte) (cipher.BlockMode, error){NewCBCEncrypter, NewCBCDecrypter} {
+
+		m, _ := newMode(key, iv)
+		x := m.(CheckedBlockMode)
+
+		buf := make([]byte, 40)
+		for _, tst := range []struct {
+			dst, src []byte
+			err      error
+		}{
+			{buf[:12], plain[:12], errInputSize},
+			{buf[:7], plain[:7], errInputSize},
+			{buf[:8], plain[:16], errOutputSize},
+			{buf[1:17], buf[:16], errOverlap},
+		} {
+			before := bytes.Clone(buf)
+			if err := x.CryptBlocksErr(tst.dst, tst.src); err != tst.err {
+				t.Errorf("CryptBlocksErr(%d bytes into %d): got %v, want %v", len(tst.src), len(tst.dst), err, tst.err)
+			}
+			if !bytes.Equal(buf, before) {
+				t.Errorf("CryptBlocksErr(%d bytes into %d) changed dst after an error", len(tst.src), len(tst.dst))
+			}
+		}
+	}
+
+	// whole blocks, in two calls so the chaining carries over
+	enc.(IVSetter).SetIV(iv)
+	x := enc.(CheckedBlockMode)
+	ct := make([]byte, len(plain))
+	if err := x.CryptBlocksErr(ct[:8], plain[:8]); err != nil {
+		t.Fatalf("CryptBlocksErr failed: %v", err)
+	}
+	if err := x.CryptBlocksErr(ct[8:], plain[8:]); err != nil {
+		t.Fatalf("CryptBlocksErr failed: %v", err)
+	}
+	if !bytes.Equal(ct, want) {
+		t.Errorf("CryptBlocksErr encrypt:\ngot : % 02x\nwant: % 02x", ct, want)
+	}
+
+	dec, _ := NewCBCDecrypter(key, iv)
+	if err := dec.(CheckedBlockMode).CryptBlocksErr(ct, ct); err != nil || !bytes.Equal(ct, plain) {
+		t.Errorf("CryptBlocksErr decrypt in place: got (% 02x, %v), want % 02x", ct, err, plain)
+	}
+}
+
+func TestCBCSetIV(t *testing.T) {
+
+	key := tests[0].key
+	iv := seq(0x10, 8)
+
+	src := make([]byte, 40)
+	rand.Read(src)
+
+	enc, _ := NewCBCEncrypter(key, iv)
+	dec, _ := NewCBCDecrypter(key, iv)
+
+	want := make([]byte, len(src))
+	enc.CryptBlocks(want, src)
+
+	// without a reset the chaining continues from the last block
+	got := make([]byte, len(src))
+	enc.CryptBlocks(got, src)
+	if bytes.Equal(got, want) {
+		t.Errorf("CBC repeated ciphertext without SetIV")
+	}
+
+	if err := enc.(IVSetter).SetIV(iv); err != nil {
+		t.Fatalf("SetIV failed: %v", err)
+	}
+
+	enc.CryptBlocks(got, src)
+	if !bytes.Equal(got, want) {
+		t.Errorf("CBC after SetIV:\ngot : % 02x\nwant: % 02x", got, want)
+	}
+
+	pt := make([]byte, len(src))
+	dec.CryptBlocks(pt, want)
+	dec.(IVSetter).SetIV(iv)
+	dec.CryptBlocks(pt, want)
+	if !bytes.Equal(pt, src) {
+		t.Errorf("CBC decrypt after SetIV:\ngot : % 02x\nwant: % 02x", pt, src)
+	}
+
+	for _, m := range []cipher.BlockMode{enc, dec} {
+		if err := m.(IVSetter).SetIV(iv[:7]); err != errIVSize {
+			t.Errorf("SetIV with short IV: got %v, want %v", err, errIVSize)
+		}
+	}
+
+	// a rejected IV leaves the chaining value alone
+	enc.(IVSetter).SetIV(iv)
+	enc.(IVSetter).SetIV(make([]byte, 16))
+	enc.CryptBlocks(got, src)
+	if !bytes.Equal(got, want) {
+		t.Errorf("CBC after rejected SetIV:\ngot : % 02x\nwant: % 02x", got, want)
+	}
+}
+
+func TestCBCDecryptBatches(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+	block, _ := New(key)
+
+	ct := make([]byte, 3*cbcBatch+24)
+	rand.Read(ct)
+
+	want := make([]byte, len(ct))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(want, ct)
+
+	// split points either side of and across the batch boundaries
+	for _, split := range []int{0, 8, cbcBatch - 8, cbcBatch, cbcBatch + 8, 2*cbcBatch + 16, len(ct)} {
+
+		dec, _ := NewCBCDecrypter(key, iv)
+
+		p := make([]byte, len(ct))
+		dec.CryptBlocks(p[:split], ct[:split])
+		dec.CryptBlocks(p[split:], ct[split:])
+
+		if !bytes.Equal(p, want) {
+			t.Errorf("CBC batch decrypt differs from sequential (split=%d)", split)
+		}
+
+		dec, _ = NewCBCDecrypter(key, iv)
+
+		p = bytes.Clone(ct)
+		dec.CryptBlocks(p[:split], p[:split])
+		dec.CryptBlocks(p[split:], p[split:])
+
+		if !bytes.Equal(p, want) {
+			t.Errorf("in-place CBC batch decrypt differs from sequential (split=%d)", split)
+		}
+	}
+}
+
+func BenchmarkCBCDecrypt(b *testing.B) {
+	dec, _ := NewCBCDecrypter(tests[0].key, make([]byte, 8))
+	buf := make([]byte, 8192)
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dec.CryptBlocks(buf, buf)
+	}
+}
+
+func BenchmarkStdlibCBCDecrypt(b *testing.B) {
+	block, _ := New(tests[0].key)
+	dec := cipher.NewCBCDecrypter(block, make([]byte, 8))
+	buf := make([]byte, 8192)
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dec.CryptBlocks(buf, buf)
+	}
+}
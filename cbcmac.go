@@ -0,0 +1,79 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"hash"
+)
+
+type cbcMAC struct {
+	b   cipher.Block
+	x   []byte // chaining value
+	buf []byte // pending input, never more than one block
+}
+
+// NewCBCMAC returns a hash.Hash computing the raw CBC-MAC of its input under
+// RC5-32/12/16 with the given key: the last block of the CBC encryption of
+// the input with a zero IV.  A final partial block is padded with zeros, and
+// empty input is treated as a single zero block.  The tag is one 8-byte block.
+//
+// WARNING: CBC-MAC is only secure when every message authenticated under a key
+// has the same length, fixed in advance.  With variable-length messages tags
+// can be forged, and zero padding makes messages differing only in trailing
+// zeros share a tag.  Use NewCMAC unless a protocol requires CBC-MAC.
+func NewCBCMAC(key []byte) (hash.Hash, error) {
+
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	bs := b.BlockSize()
+
+	return &cbcMAC{
+		b:   b,
+		x:   make([]byte, bs),
+		buf: make([]byte, 0, bs),
+	}, nil
+}
+
+func (m *cbcMAC) Size() int      { return m.b.BlockSize() }
+func (m *cbcMAC) BlockSize() int { return m.b.BlockSize() }
+
+func (m *cbcMAC) Reset() {
+	clear(m.x)
+	m.buf = m.buf[:0]
+}
+
+func (m *cbcMAC) Write(p []byte) (int, error) {
+
+	n := len(p)
+	bs := m.b.BlockSize()
+
+	for len(p) > 0 {
+		// hold back a full block until more input follows, so Sum can tell
+		// empty input, which needs a padding block, from a full final block
+		if len(m.buf) == bs {
+			subtle.XORBytes(m.x, m.x, m.buf)
+			m.b.Encrypt(m.x, m.x)
+			m.buf = m.buf[:0]
+		}
+
+		k := min(bs-len(m.buf), len(p))
+		m.buf = append(m.buf, p[:k]...)
+		p = p[k:]
+	}
+
+	return n, nil
+}
+
+func (m *cbcMAC) Sum(in []byte) []byte {
+
+	last := make([]byte, m.b.BlockSize())
+	copy(last, m.buf)
+
+	subtle.XORBytes(last, last, m.x)
+	m.b.Encrypt(last, last)
+
+	return append(in, last...)
+}
@@ -0,0 +1,83 @@
+package rc5
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCBCMAC(t *testing.T) {
+
+	key := seq(0, 16)
+	zero := make([]byte, 8)
+
+	for _, n := range []int{8, 16, 64, 200} {
+
+		msg := seq(byte(n), n)
+
+		// for whole blocks, the MAC is the last block of CBC with a zero IV
+		ct := make([]byte, len(msg))
+		enc, _ := NewCBCEncrypter(key, zero)
+		enc.CryptBlocks(ct, msg)
+		want := ct[len(ct)-8:]
+
+		m, err := NewCBCMAC(key)
+		if err != nil {
+			t.Fatalf("NewCBCMAC failed: %v", err)
+		}
+		m.Write(msg)
+
+		if got := m.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("CBC-MAC of %d bytes:\ngot : % 02x\nwant: % 02x", n, got, want)
+		}
+	}
+
+	// empty input is one zero block, and partial blocks are zero padded
+	for _, tst := range []struct {
+		msg, padded []byte
+	}{
+		{nil, zero},
+		{seq(1, 3), append(seq(1, 3), zero[:5]...)},
+		{seq(1, 11), append(seq(1, 11), zero[:5]...)},
+	} {
+		m, _ := NewCBCMAC(key)
+		m.Write(tst.msg)
+		got := m.Sum(nil)
+
+		m.Reset()
+		m.Write(tst.padded)
+		want := m.Sum(nil)
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("CBC-MAC of % 02x:\ngot : % 02x\nwant: % 02x", tst.msg, got, want)
+		}
+	}
+}
+
+func TestCBCMACChunked(t *testing.T) {
+
+	key := seq(0, 16)
+	msg := seq(0, 100)
+
+	m, _ := NewCBCMAC(key)
+	m.Write(msg)
+	want := m.Sum(nil)
+
+	for _, chunk := range []int{1, 3, 7, 8, 9, 16, 33} {
+
+		m.Reset()
+		for p := msg; len(p) > 0; {
+			k := min(chunk, len(p))
+			m.Write(p[:k])
+			p = p[k:]
+		}
+
+		if got := m.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("CBC-MAC written in %d-byte chunks:\ngot : % 02x\nwant: % 02x", chunk, got, want)
+		}
+
+		// Sum does not change the state
+		if got := m.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("second Sum after %d-byte chunks:\ngot : % 02x\nwant: % 02x", chunk, got, want)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+var (
+	errIVSize    = errors.New("rc5: IV length must equal block size")
+	errInputSize = errors.New("rc5: input not a multiple of the block size")
+	errPadding   = errors.New("rc5: invalid padding")
+)
+
+// A PadScheme selects how CBC plaintext is padded to a multiple of the block
+// size.  The zero value is RFC2040.
+type PadScheme int
+
+const (
+	// RFC2040 pads with between 1 and 8 bytes, each equal to the number of
+	// padding bytes, as in the RC5-CBC-Pad mode of RFC 2040.
+	RFC2040 PadScheme = iota
+
+	// PKCS7 is the padding of RFC 5652, which for an 8-byte block is the
+	// same as RFC2040.
+	PKCS7
+
+	// ZeroPad pads with between 0 and 7 zero bytes.  It cannot be validated:
+	// decryption removes any zero bytes at the end of the final block, up to
+	// 7, so it is only suitable for plaintext which cannot end in a zero byte.
+	ZeroPad
+
+	// ANSIX923 pads with between 0 and 7 zero bytes followed by a byte
+	// holding the number of padding bytes, between 1 and 8.
+	ANSIX923
+)
+
+var errPadScheme = errors.New("rc5: unknown padding scheme")
+
+// A CBCPadder encrypts and decrypts with RC5-32/12/16 in CBC mode using a
+// particular padding scheme.
+type CBCPadder struct {
+	scheme PadScheme
+}
+
+// NewCBCPadder returns a CBCPadder for the given padding scheme, to
+// interoperate with peers that do not use the RFC 2040 padding of
+// EncryptCBCPad.
+func NewCBCPadder(scheme PadScheme) (*CBCPadder, error) {
+
+	switch scheme {
+	case RFC2040, PKCS7, ZeroPad, ANSIX923:
+	default:
+		return nil, errPadScheme
+	}
+
+	return &CBCPadder{scheme}, nil
+}
+
+// rfc2040 is the padder used by EncryptCBCPad and DecryptCBCPad.
+var rfc2040 = &CBCPadder{RFC2040}
+
+// Encrypt pads plaintext and encrypts it in CBC mode under key and iv.
+func (p *CBCPadder) Encrypt(key, iv, plaintext []byte) ([]byte, error) {
+
+	block, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.encryptAppend(nil, block, iv, plaintext)
+}
+
+// Decrypt decrypts ciphertext produced by Encrypt with the same padding
+// scheme and removes the padding, returning an error if the padding is
+// malformed.
+func (p *CBCPadder) Decrypt(key, iv, ciphertext []byte) ([]byte, error) {
+
+	block, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.decrypt(block, iv, ciphertext)
+}
+
+func (p *CBCPadder) encryptAppend(dst []byte, block cipher.Block, iv, plaintext []byte) ([]byte, error) {
+
+	bs := block.BlockSize()
+
+	if len(iv) != bs {
+		return nil, errIVSize
+	}
+
+	padLen := bs - len(plaintext)%bs
+	if p.scheme == ZeroPad {
+		padLen %= bs
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+padLen)
+	copy(out, plaintext)
+
+	pad := out[len(plaintext):]
+	switch p.scheme {
+	case RFC2040, PKCS7:
+		for i := range pad {
+			pad[i] = byte(padLen)
+		}
+	case ZeroPad:
+		clear(pad)
+	case ANSIX923:
+		clear(pad)
+		pad[len(pad)-1] = byte(padLen)
+	}
+
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, out)
+
+	return ret, nil
+}
+
+func (p *CBCPadder) decrypt(block cipher.Block, iv, ciphertext []byte) ([]byte, error) {
+
+	bs := block.BlockSize()
+
+	if len(iv) != bs {
+		return nil, errIVSize
+	}
+
+	if len(ciphertext)%bs != 0 || len(ciphertext) == 0 && p.scheme != ZeroPad {
+		return nil, errInputSize
+	}
+
+	if len(ciphertext) == 0 {
+		return []byte{}, nil
+	}
+
+	dst := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(dst, ciphertext)
+
+	padLen, err := p.unpad(dst[len(dst)-bs:])
+	if err != nil {
+		return nil, err
+	}
+
+	return dst[:len(dst)-padLen], nil
+}
+
+// unpad returns the length of the padding at the end of the final plaintext
+// block last.
+func (p *CBCPadder) unpad(last []byte) (int, error) {
+
+	switch p.scheme {
+	case ZeroPad:
+		n := 0
+		for n < len(last)-1 && last[len(last)-1-n] == 0 {
+			n++
+		}
+		return n, nil
+
+	case ANSIX923:
+		padLen := int(last[len(last)-1])
+		if padLen == 0 || padLen > len(last) {
+			return 0, errPadding
+		}
+		for _, b := range last[len(last)-padLen : len(last)-1] {
+			if b != 0 {
+				return 0, errPadding
+			}
+		}
+		return padLen, nil
+	}
+
+	return checkPadding(last)
+}
+
+// EncryptCBCPad encrypts plaintext with RC5-32/12/16 in the RC5-CBC-Pad mode
+// of RFC 2040.  The plaintext is padded with between 1 and 8 bytes, each equal
+// to the number of padding bytes, so the ciphertext is always longer than the
+// plaintext.  Use NewCBCPadder for other padding schemes.
+func EncryptCBCPad(key, iv, plaintext []byte) ([]byte, error) {
+	return EncryptCBCPadAppend(nil, key, iv, plaintext)
+}
+
+// EncryptCBCPadAppend is like EncryptCBCPad but appends the ciphertext to dst,
+// reusing its capacity, and returns the updated slice.  To reuse plaintext's
+// storage for the ciphertext, use plaintext[:0] as dst.  Otherwise, the
+// remaining capacity of dst must not overlap plaintext.
+func EncryptCBCPadAppend(dst, key, iv, plaintext []byte) ([]byte, error) {
+
+	block, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return encryptCBCPadAppend(dst, block, iv, plaintext)
+}
+
+func encryptCBCPadAppend(dst []byte, block cipher.Block, iv, plaintext []byte) ([]byte, error) {
+	return rfc2040.encryptAppend(dst, block, iv, plaintext)
+}
+
+// DecryptCBCPad decrypts ciphertext produced by EncryptCBCPad and removes the
+// RFC 2040 padding, returning an error if the padding is malformed.
+func DecryptCBCPad(key, iv, ciphertext []byte) ([]byte, error) {
+	return rfc2040.Decrypt(key, iv, ciphertext)
+}
+
+func decryptCBCPad(block cipher.Block, iv, ciphertext []byte) ([]byte, error) {
+	return rfc2040.decrypt(block, iv, ciphertext)
+}
+
+// checkPadding returns the length of the RFC 2040 padding at the end of the
+// final plaintext block last.
+func checkPadding(last []byte) (int, error) {
+
+	padLen := int(last[len(last)-1])
+	if padLen == 0 || padLen > len(last) {
+		return 0, errPadding
+	}
+
+	for _, b := range last[len(last)-padLen:] {
+		if int(b) != padLen {
+			return 0, errPadding
+		}
+	}
+
+	return padLen, nil
+}
+
+// VerifyCBCPad reports the length of the plaintext DecryptCBCPad would return
+// for ciphertext, or its error if the padding is malformed, without
+// allocating the plaintext.  Only the padding depends on the ciphertext's
+// content, and the padding is all in the final block, so that is the only
+// block decrypted.  Like DecryptCBCPad, VerifyCBCPad does not authenticate the
+// ciphertext.
+func VerifyCBCPad(key, iv, ciphertext []byte) (plaintextLen int, err error) {
+
+	block, err := New(key)
+	if err != nil {
+		return 0, err
+	}
+
+	bs := block.BlockSize()
+
+	if len(iv) != bs {
+		return 0, errIVSize
+	}
+
+	if len(ciphertext) == 0 || len(ciphertext)%bs != 0 {
+		return 0, errInputSize
+	}
+
+	prev := iv
+	if len(ciphertext) > bs {
+		prev = ciphertext[len(ciphertext)-2*bs : len(ciphertext)-bs]
+	}
+
+	// a concrete call keeps last on the stack
+	var last [BlockSize]byte
+	block.(*Cipher).Decrypt(last[:], ciphertext[len(ciphertext)-bs:])
+	subtle.XORBytes(last[:], last[:], prev)
+
+	padLen, err := checkPadding(last[:])
+	clear(last[:])
+	if err != nil {
+		return 0, err
+	}
+
+	return len(ciphertext) - padLen, nil
+}
+
+// ValidCBCLength reports whether n is a valid length for RC5-32 CBC
+// ciphertext: a multiple of the 8-byte block size.
+func ValidCBCLength(n int) bool {
+	return n >= 0 && n%BlockSize == 0
+}
+
+// ValidCBCPadLength reports whether n is a valid length for ciphertext from
+// EncryptCBCPad: at least one block and a multiple of the block size.  It does
+// not check the padding.
+func ValidCBCPadLength(n int) bool {
+	return n >= BlockSize && n%BlockSize == 0
+}
@@ -0,0 +1,348 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"testing"
+)
+
+func TestCBCPad(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+	for l := 0; l <= 40; l++ {
+
+		plain := make([]byte, l)
+		for i := range plain {
+			plain[i] = byte(i)
+		}
+
+		ct, err := EncryptCBCPad(key, iv, plain)
+		if err != nil {
+			t.Fatalf("EncryptCBCPad(len=%d) failed: %v", l, err)
+		}
+
+		if want := (l/8 + 1) * 8; len(ct) != want {
+			t.Errorf("EncryptCBCPad(len=%d) produced %d bytes, want %d", l, len(ct), want)
+		}
+
+		p, err := DecryptCBCPad(key, iv, ct)
+		if err != nil {
+			t.Fatalf("DecryptCBCPad(len=%d) failed: %v", l, err)
+		}
+
+		if !bytes.Equal(p, plain) {
+			t.Errorf("CBC-Pad round trip failed (len=%d):\ngot : % 02x\nwant: % 02x", l, p, plain)
+		}
+	}
+}
+
+func TestCBCPadInvalid(t *testing.T) {
+
+	key := tests[0].key
+	iv := make([]byte, 8)
+
+	if _, err := EncryptCBCPad(key, iv[:7], nil); err != errIVSize {
+		t.Errorf("EncryptCBCPad with short IV: got %v, want %v", err, errIVSize)
+	}
+
+	if _, err := DecryptCBCPad(key, iv, make([]byte, 12)); err != errInputSize {
+		t.Errorf("DecryptCBCPad with partial block: got %v, want %v", err, errInputSize)
+	}
+
+	if _, err := DecryptCBCPad(key, iv, nil); err != errInputSize {
+		t.Errorf("DecryptCBCPad with empty input: got %v, want %v", err, errInputSize)
+	}
+
+	block, _ := New(key)
+
+	for _, last := range [][]byte{
+		{0, 0, 0, 0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0, 0, 0, 9},
+		{0, 0, 0, 0, 0, 2, 3, 3},
+	} {
+		ct := make([]byte, 8)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ct, last)
+
+		if _, err := DecryptCBCPad(key, iv, ct); err != errPadding {
+			t.Errorf("DecryptCBCPad(% 02x): got %v, want %v", last, err, errPadding)
+		}
+	}
+}
+
+func TestCBCPadEdges(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+	block, _ := New(key)
+
+	// decryptRaw undoes the CBC layer without touching the padding
+	decryptRaw := func(ct []byte) []byte {
+		p := make([]byte, len(ct))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(p, ct)
+		return p
+	}
+
+	// aligned input gains a whole block of padding
+	aligned := []byte{0xA0, 0xA1, 0xA2, 0xA3, 0xA4, 0xA5, 0xA6, 0xA7}
+	ct, _ := EncryptCBCPad(key, iv, aligned)
+	if want := append(bytes.Clone(aligned), 8, 8, 8, 8, 8, 8, 8, 8); !bytes.Equal(decryptRaw(ct), want) {
+		t.Errorf("aligned padding:\ngot : % 02x\nwant: % 02x", decryptRaw(ct), want)
+	}
+	if p, err := DecryptCBCPad(key, iv, ct); err != nil || !bytes.Equal(p, aligned) {
+		t.Errorf("DecryptCBCPad(aligned)=% 02x, %v, want % 02x", p, err, aligned)
+	}
+
+	// one byte short gains a single padding byte
+	short := aligned[:7]
+	ct, _ = EncryptCBCPad(key, iv, short)
+	if want := append(bytes.Clone(short), 1); !bytes.Equal(decryptRaw(ct), want) {
+		t.Errorf("short padding:\ngot : % 02x\nwant: % 02x", decryptRaw(ct), want)
+	}
+	if p, err := DecryptCBCPad(key, iv, ct); err != nil || !bytes.Equal(p, short) {
+		t.Errorf("DecryptCBCPad(short)=% 02x, %v, want % 02x", p, err, short)
+	}
+
+	// a full block of padding after data is accepted
+	valid := []byte{0xA0, 0xA1, 0xA2, 0xA3, 0xA4, 0xA5, 0xA6, 0xA7, 8, 8, 8, 8, 8, 8, 8, 8}
+	ct = make([]byte, len(valid))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ct, valid)
+	if p, err := DecryptCBCPad(key, iv, ct); err != nil || !bytes.Equal(p, valid[:8]) {
+		t.Errorf("DecryptCBCPad(full pad block)=% 02x, %v, want % 02x", p, err, valid[:8])
+	}
+
+	for _, tst := range []struct {
+		name string
+		last []byte
+	}{
+		{"zero pad", []byte{0xA0, 0xA1, 0xA2, 0xA3, 0xA4, 0xA5, 0xA6, 0x00}},
+		{"pad too long", []byte{0x09, 0x09, 0x09, 0x09, 0x09, 0x09, 0x09, 0x09}},
+		{"wrong last byte", []byte{0xA0, 0xA1, 0xA2, 0xA3, 0xA4, 0x03, 0x03, 0x02}},
+		{"inconsistent full pad", []byte{0x08, 0x08, 0x08, 0x07, 0x08, 0x08, 0x08, 0x08}},
+		{"inconsistent short pad", []byte{0xA0, 0xA1, 0xA2, 0xA3, 0xA4, 0x02, 0x03, 0x03}},
+	} {
+		pt := append(bytes.Clone(aligned), tst.last...)
+		ct := make([]byte, len(pt))
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ct, pt)
+
+		if _, err := DecryptCBCPad(key, iv, ct); err != errPadding {
+			t.Errorf("DecryptCBCPad(%s): got %v, want %v", tst.name, err, errPadding)
+		}
+	}
+}
+
+func TestValidCBCLength(t *testing.T) {
+
+	for _, tst := range []struct {
+		n           int
+		cbc, cbcPad bool
+	}{
+		{-8, false, false},
+		{-1, false, false},
+		{0, true, false},
+		{1, false, false},
+		{7, false, false},
+		{8, true, true},
+		{9, false, false},
+		{15, false, false},
+		{16, true, true},
+		{17, false, false},
+		{1 << 20, true, true},
+	} {
+		if got := ValidCBCLength(tst.n); got != tst.cbc {
+			t.Errorf("ValidCBCLength(%d)=%v, want %v", tst.n, got, tst.cbc)
+		}
+		if got := ValidCBCPadLength(tst.n); got != tst.cbcPad {
+			t.Errorf("ValidCBCPadLength(%d)=%v, want %v", tst.n, got, tst.cbcPad)
+		}
+	}
+
+	// every ciphertext from EncryptCBCPad passes
+	for l := 0; l < 20; l++ {
+		ct, _ := EncryptCBCPad(tests[0].key, make([]byte, 8), make([]byte, l))
+		if !ValidCBCPadLength(len(ct)) {
+			t.Errorf("ValidCBCPadLength(%d) rejects EncryptCBCPad output", len(ct))
+		}
+	}
+}
+
+func TestEncryptCBCPadAppend(t *testing.T) {
+
+	key := seq(0, 16)
+	iv := seq(0x40, 8)
+	buf := make([]byte, 0, 64)
+
+	for n := 0; n <= 20; n++ {
+
+		plain := seq(byte(n), n)
+
+		want, err := EncryptCBCPad(key, iv, plain)
+		if err != nil {
+			t.Fatalf("EncryptCBCPad(%d bytes) failed: %v", n, err)
+		}
+
+		prefix := []byte("hdr")
+		got, err := EncryptCBCPadAppend(append(buf[:0], prefix...), key, iv, plain)
+		if err != nil {
+			t.Fatalf("EncryptCBCPadAppend(%d bytes) failed: %v", n, err)
+		}
+
+		if !bytes.Equal(got[:len(prefix)], prefix) || !bytes.Equal(got[len(prefix):], want) {
+			t.Errorf("EncryptCBCPadAppend(%d bytes):\ngot : % 02x\nwant: % 02x % 02x", n, got, prefix, want)
+		}
+
+		if &got[0] != &buf[:1][0] {
+			t.Errorf("EncryptCBCPadAppend(%d bytes) did not reuse the capacity of dst", n)
+		}
+
+		// in place, over the plaintext's own storage
+		inPlace := make([]byte, n, n+8)
+		copy(inPlace, plain)
+		got, _ = EncryptCBCPadAppend(inPlace[:0], key, iv, inPlace)
+		if !bytes.Equal(got, want) {
+			t.Errorf("EncryptCBCPadAppend(%d bytes) in place:\ngot : % 02x\nwant: % 02x", n, got, want)
+		}
+	}
+}
+
+func TestVerifyCBCPad(t *testing.T) {
+
+	key := seq(0, 16)
+	iv := seq(0x40, 8)
+
+	for n := 0; n <= 33; n++ {
+
+		ct, _ := EncryptCBCPad(key, iv, seq(byte(n), n))
+
+		got, err := VerifyCBCPad(key, iv, ct)
+		if err != nil {
+			t.Fatalf("VerifyCBCPad(%d bytes) failed: %v", n, err)
+		}
+
+		p, _ := DecryptCBCPad(key, iv, ct)
+		if got != len(p) || got != n {
+			t.Errorf("VerifyCBCPad(%d bytes)=%d, DecryptCBCPad gives %d bytes", n, got, len(p))
+		}
+
+		// corrupting the last plaintext byte through the previous block or
+		// the IV breaks the padding
+		bad := bytes.Clone(ct)
+		badIV := bytes.Clone(iv)
+		if len(ct) > 8 {
+			bad[len(bad)-9] ^= 0x80
+		} else {
+			badIV[7] ^= 0x80
+		}
+		if _, err := VerifyCBCPad(key, badIV, bad); err != errPadding {
+			t.Errorf("VerifyCBCPad(%d bytes) with bad padding: got %v, want %v", n, err, errPadding)
+		}
+		if _, err := DecryptCBCPad(key, badIV, bad); err != errPadding {
+			t.Errorf("DecryptCBCPad(%d bytes) with bad padding: got %v, want %v", n, err, errPadding)
+		}
+	}
+
+	for _, l := range []int{0, 7, 12} {
+		if _, err := VerifyCBCPad(key, iv, make([]byte, l)); err != errInputSize {
+			t.Errorf("VerifyCBCPad(%d bytes): got %v, want %v", l, err, errInputSize)
+		}
+	}
+
+	if _, err := VerifyCBCPad(key, iv[:4], make([]byte, 8)); err != errIVSize {
+		t.Errorf("VerifyCBCPad with a short IV: got %v, want %v", err, errIVSize)
+	}
+
+	// nothing is allocated beyond the key schedule
+	ct, _ := EncryptCBCPad(key, iv, seq(0, 1000))
+	setup := testing.AllocsPerRun(10, func() { New(key) })
+	if allocs := testing.AllocsPerRun(10, func() { VerifyCBCPad(key, iv, ct) }); allocs > setup {
+		t.Errorf("VerifyCBCPad made %.0f allocations, want %.0f", allocs, setup)
+	}
+}
+
+func TestCBCPadder(t *testing.T) {
+
+	key := tests[0].key
+	iv := seq(0x40, 8)
+	block, _ := New(key)
+
+	for _, tst := range []struct {
+		scheme PadScheme
+		pad    func(l int) []byte // the padding expected after l bytes
+	}{
+		{RFC2040, func(l int) []byte { return bytes.Repeat([]byte{byte(8 - l%8)}, 8-l%8) }},
+		{PKCS7, func(l int) []byte { return bytes.Repeat([]byte{byte(8 - l%8)}, 8-l%8) }},
+		{ZeroPad, func(l int) []byte { return make([]byte, (8-l%8)%8) }},
+		{ANSIX923, func(l int) []byte { p := make([]byte, 8-l%8); p[len(p)-1] = byte(len(p)); return p }},
+	} {
+		p, err := NewCBCPadder(tst.scheme)
+		if err != nil {
+			t.Fatalf("NewCBCPadder(%d) failed: %v", tst.scheme, err)
+		}
+
+		for l := 0; l <= 17; l++ {
+
+			// plaintext without trailing zeros, so ZeroPad can round trip it
+			plain := seq(1, l)
+
+			ct, err := p.Encrypt(key, iv, plain)
+			if err != nil {
+				t.Fatalf("scheme %d: Encrypt(len=%d) failed: %v", tst.scheme, l, err)
+			}
+
+			padded := make([]byte, len(ct))
+			cipher.NewCBCDecrypter(block, iv).CryptBlocks(padded, ct)
+			if want := append(bytes.Clone(plain), tst.pad(l)...); !bytes.Equal(padded, want) {
+				t.Errorf("scheme %d: padded plaintext (len=%d):\ngot : % 02x\nwant: % 02x", tst.scheme, l, padded, want)
+			}
+
+			got, err := p.Decrypt(key, iv, ct)
+			if err != nil {
+				t.Fatalf("scheme %d: Decrypt(len=%d) failed: %v", tst.scheme, l, err)
+			}
+
+			if !bytes.Equal(got, plain) {
+				t.Errorf("scheme %d: round trip (len=%d):\ngot : % 02x\nwant: % 02x", tst.scheme, l, got, plain)
+			}
+		}
+	}
+
+	// PKCS7 and RFC 2040 are the same for an 8-byte block, and EncryptCBCPad
+	// uses RFC 2040
+	pkcs7, _ := NewCBCPadder(PKCS7)
+	for _, l := range []int{0, 8, 16, 5, 13} {
+		a, _ := pkcs7.Encrypt(key, iv, seq(0, l))
+		b, _ := EncryptCBCPad(key, iv, seq(0, l))
+		if !bytes.Equal(a, b) {
+			t.Errorf("PKCS7 and RFC 2040 differ (len=%d):\ngot : % 02x\nwant: % 02x", l, a, b)
+		}
+	}
+
+	// ANSI X.923 requires the padding bytes before the length to be zero
+	ansi, _ := NewCBCPadder(ANSIX923)
+	for _, last := range [][]byte{
+		{1, 2, 3, 4, 5, 0, 1, 3},
+		{1, 2, 3, 4, 5, 6, 7, 0},
+		{1, 2, 3, 4, 5, 6, 7, 9},
+	} {
+		ct := make([]byte, 8)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ct, last)
+		if _, err := ansi.Decrypt(key, iv, ct); err != errPadding {
+			t.Errorf("ANSI X.923 Decrypt of % 02x: got %v, want %v", last, err, errPadding)
+		}
+	}
+
+	// only zero padding produces empty ciphertext
+	zero, _ := NewCBCPadder(ZeroPad)
+	if p, err := zero.Decrypt(key, iv, nil); err != nil || len(p) != 0 {
+		t.Errorf("ZeroPad Decrypt of empty ciphertext: got (% 02x, %v)", p, err)
+	}
+	if _, err := ansi.Decrypt(key, iv, nil); err != errInputSize {
+		t.Errorf("ANSI X.923 Decrypt of empty ciphertext: got %v, want %v", err, errInputSize)
+	}
+
+	if _, err := NewCBCPadder(ANSIX923 + 1); err != errPadScheme {
+		t.Errorf("NewCBCPadder of an unknown scheme: got %v, want %v", err, errPadScheme)
+	}
+}
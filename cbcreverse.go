@@ -0,0 +1,71 @@
+package rc5
+
+import "crypto/subtle"
+
+// EncryptCBCReverse encrypts data with RC5-32/12/16 in CBC mode, chaining
+// from the last block to the first: the last block is XORed with iv, and each
+// earlier block with the ciphertext of the block after it.  The length of data
+// must be a multiple of the block size, and no padding is added.
+//
+// EncryptCBCReverse is a compatibility shim for a legacy archive format that
+// processes blocks in reverse order.  It is no more secure than ordinary CBC
+// and should not be used in new designs.
+func EncryptCBCReverse(key, iv, data []byte) ([]byte, error) {
+
+	b, bs, err := newCBCReverse(key, iv, data)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := make([]byte, len(data))
+
+	prev := iv
+	for i := len(data) - bs; i >= 0; i -= bs {
+		subtle.XORBytes(dst[i:i+bs], data[i:i+bs], prev)
+		b.Encrypt(dst[i:i+bs], dst[i:i+bs])
+		prev = dst[i : i+bs]
+	}
+
+	return dst, nil
+}
+
+// DecryptCBCReverse reverses EncryptCBCReverse.  It is a compatibility shim
+// for a legacy format, as is EncryptCBCReverse.
+func DecryptCBCReverse(key, iv, ciphertext []byte) ([]byte, error) {
+
+	b, bs, err := newCBCReverse(key, iv, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := make([]byte, len(ciphertext))
+
+	prev := iv
+	for i := len(ciphertext) - bs; i >= 0; i -= bs {
+		b.Decrypt(dst[i:i+bs], ciphertext[i:i+bs])
+		subtle.XORBytes(dst[i:i+bs], dst[i:i+bs], prev)
+		prev = ciphertext[i : i+bs]
+	}
+
+	return dst, nil
+}
+
+func newCBCReverse(key, iv, data []byte) (*Cipher, int, error) {
+
+	b, err := New(key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	bs := b.BlockSize()
+
+	if len(iv) != bs {
+		return nil, 0, errIVSize
+	}
+
+	if len(data)%bs != 0 {
+		return nil, 0, errInputSize
+	}
+
+	return b.(*Cipher), bs, nil
+}
@@ -0,0 +1,191 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	errCCMBlockSize = errors.New("rc5: CCM requires a 128-bit block, use a 64-bit word size")
+	errTagSize      = errors.New("rc5: invalid tag size")
+	errCCMLength    = errors.New("rc5: message too long for CCM nonce size")
+)
+
+type ccm struct {
+	b         cipher.Block
+	nonceSize int
+	tagSize   int
+}
+
+// NewCCM returns block wrapped in the Counter with CBC-MAC mode of NIST SP
+// 800-38C and RFC 3610.  The block size must be 16 bytes, such as RC5-64 from
+// NewWithParameters(64, rounds, key).  The tag size must be 4, 6, 8, 10, 12,
+// 14 or 16 bytes and the nonce size between 7 and 13 bytes; a nonce of n bytes
+// limits messages to 2^(8*(15-n)) - 1 bytes.
+func NewCCM(block cipher.Block, tagSize, nonceSize int) (cipher.AEAD, error) {
+
+	if block.BlockSize() != 16 {
+		return nil, errCCMBlockSize
+	}
+
+	if tagSize < 4 || tagSize > 16 || tagSize%2 != 0 {
+		return nil, errTagSize
+	}
+
+	if nonceSize < 7 || nonceSize > 13 {
+		return nil, errNonceSize
+	}
+
+	return &ccm{b: block, nonceSize: nonceSize, tagSize: tagSize}, nil
+}
+
+func (c *ccm) NonceSize() int { return c.nonceSize }
+func (c *ccm) Overhead() int  { return c.tagSize }
+
+// maxLength returns the longest message whose length fits in the 15-nonceSize
+// length field of B0.
+func (c *ccm) maxLength() uint64 {
+	l := 15 - c.nonceSize
+	if l >= 8 {
+		return 1<<64 - 1
+	}
+	return 1<<(8*l) - 1
+}
+
+// mac returns the unencrypted CBC-MAC of the formatted nonce, additional data
+// and plaintext.
+func (c *ccm) mac(nonce, plaintext, additionalData []byte) [16]byte {
+
+	var x [16]byte
+
+	// B0: flags, nonce and the message length
+	x[0] = byte((c.tagSize-2)/2<<3 | (14 - c.nonceSize))
+	if len(additionalData) > 0 {
+		x[0] |= 0x40
+	}
+	copy(x[1:], nonce)
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(plaintext)))
+	copy(x[1+c.nonceSize:], l[8-(15-c.nonceSize):])
+	c.b.Encrypt(x[:], x[:])
+
+	if len(additionalData) > 0 {
+		// the additional data is prefixed with its encoded length
+		var hdr []byte
+		switch n := uint64(len(additionalData)); {
+		case n < 0xff00:
+			hdr = binary.BigEndian.AppendUint16(nil, uint16(n))
+		case n <= 0xffffffff:
+			hdr = binary.BigEndian.AppendUint32([]byte{0xff, 0xfe}, uint32(n))
+		default:
+			hdr = binary.BigEndian.AppendUint64([]byte{0xff, 0xff}, n)
+		}
+		c.cbcmac(&x, hdr, additionalData)
+	}
+
+	c.cbcmac(&x, plaintext)
+
+	return x
+}
+
+// cbcmac continues the CBC-MAC in x over the concatenation of data, padded
+// with zeros to a whole number of blocks.
+func (c *ccm) cbcmac(x *[16]byte, data ...[]byte) {
+
+	n := 0
+	for _, d := range data {
+		for len(d) > 0 {
+			k := subtle.XORBytes(x[n:], x[n:], d)
+			n, d = n+k, d[k:]
+			if n == len(x) {
+				c.b.Encrypt(x[:], x[:])
+				n = 0
+			}
+		}
+	}
+
+	if n > 0 {
+		c.b.Encrypt(x[:], x[:])
+	}
+}
+
+// counter returns the counter block A0 for nonce.
+func (c *ccm) counter(nonce []byte) []byte {
+	a := make([]byte, 16)
+	a[0] = byte(14 - c.nonceSize)
+	copy(a[1:], nonce)
+	return a
+}
+
+// crypt computes the tag from the MAC x and XORs src with the keystream
+// starting at counter block A1 into dst.
+func (c *ccm) crypt(dst, src, nonce []byte, x *[16]byte) {
+
+	a := c.counter(nonce)
+
+	var s0 [16]byte
+	c.b.Encrypt(s0[:], a)
+	subtle.XORBytes(x[:], x[:], s0[:])
+
+	a[15] = 1
+	cipher.NewCTR(c.b, a).XORKeyStream(dst, src)
+}
+
+func (c *ccm) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+
+	if len(nonce) != c.nonceSize {
+		panic("rc5: incorrect nonce length given to CCM")
+	}
+
+	if uint64(len(plaintext)) > c.maxLength() {
+		panic(errCCMLength.Error())
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+c.tagSize)
+	if inexactOverlap(out, plaintext) {
+		panic("rc5: invalid buffer overlap")
+	}
+
+	tag := c.mac(nonce, plaintext, additionalData)
+	c.crypt(out, plaintext, nonce, &tag)
+
+	copy(out[len(plaintext):], tag[:c.tagSize])
+
+	return ret
+}
+
+func (c *ccm) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+
+	if len(nonce) != c.nonceSize {
+		panic("rc5: incorrect nonce length given to CCM")
+	}
+
+	if len(ciphertext) < c.tagSize || uint64(len(ciphertext)-c.tagSize) > c.maxLength() {
+		return nil, errOpen
+	}
+
+	tag := ciphertext[len(ciphertext)-c.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-c.tagSize]
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	if inexactOverlap(out, ciphertext) {
+		panic("rc5: invalid buffer overlap")
+	}
+
+	// CCM authenticates the plaintext, so it is decrypted before the tag is
+	// checked and cleared if the check fails
+	var x [16]byte
+	c.crypt(out, ciphertext, nonce, &x)
+
+	expected := c.mac(nonce, out, additionalData)
+	subtle.XORBytes(expected[:], expected[:], x[:])
+
+	if subtle.ConstantTimeCompare(expected[:c.tagSize], tag) != 1 {
+		clear(out)
+		return nil, errOpen
+	}
+
+	return ret, nil
+}
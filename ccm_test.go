@@ -0,0 +1,120 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestCCMVectors(t *testing.T) {
+
+	// NIST SP 800-38C appendix C, which uses AES; CCM itself only depends on
+	// the 128-bit block size
+	block, _ := aes.NewCipher(seq(0x40, 16))
+
+	for _, tst := range []struct {
+		nonceLen, adLen, plainLen, tagLen int
+		out                               string
+	}{
+		{7, 8, 4, 4, "7162015b4dac255d"},
+		{8, 16, 16, 6, "d2a1f0e051ea5f62081a7792073d593d1fc64fbfaccd"},
+		{12, 20, 24, 8, "e3b201a9f5b71a7a9b1ceaeccd97e70b6176aad9a4428aa5484392fbc1b09951"},
+	} {
+		aead, err := NewCCM(block, tst.tagLen, tst.nonceLen)
+		if err != nil {
+			t.Fatalf("NewCCM failed: %v", err)
+		}
+
+		nonce := seq(0x10, tst.nonceLen)
+		ad := seq(0x00, tst.adLen)
+		plain := seq(0x20, tst.plainLen)
+
+		want, _ := hex.DecodeString(tst.out)
+
+		got := aead.Seal(nil, nonce, plain, ad)
+		if !bytes.Equal(got, want) {
+			t.Errorf("CCM Seal (nonce=%d):\ngot : % 02x\nwant: % 02x", tst.nonceLen, got, want)
+		}
+
+		pt, err := aead.Open(nil, nonce, want, ad)
+		if err != nil || !bytes.Equal(pt, plain) {
+			t.Errorf("CCM Open (nonce=%d): got % 02x, %v, want % 02x", tst.nonceLen, pt, err, plain)
+		}
+	}
+}
+
+func TestCCM(t *testing.T) {
+
+	block, _ := NewWithParameters(64, 24, tests[0].key)
+
+	for _, nonceLen := range []int{7, 10, 13} {
+		for _, tagLen := range []int{4, 8, 16} {
+
+			aead, err := NewCCM(block, tagLen, nonceLen)
+			if err != nil {
+				t.Fatalf("NewCCM(%d, %d) failed: %v", tagLen, nonceLen, err)
+			}
+
+			nonce := seq(0x10, nonceLen)
+
+			for _, l := range []int{0, 1, 16, 33} {
+				for _, ad := range [][]byte{nil, seq(0, 3), seq(0, 40)} {
+
+					msg := seq(0x80, l)
+
+					ct := aead.Seal(nil, nonce, msg, ad)
+					if len(ct) != l+tagLen {
+						t.Errorf("Seal: got %d bytes, want %d", len(ct), l+tagLen)
+					}
+
+					pt, err := aead.Open(nil, nonce, ct, ad)
+					if err != nil {
+						t.Fatalf("Open (nonce=%d tag=%d len=%d ad=%d) failed: %v", nonceLen, tagLen, l, len(ad), err)
+					}
+
+					if !bytes.Equal(pt, msg) {
+						t.Errorf("CCM round trip:\ngot : % 02x\nwant: % 02x", pt, msg)
+					}
+
+					for i := range ct {
+						ct[i] ^= 1
+						if _, err := aead.Open(nil, nonce, ct, ad); err != errOpen {
+							t.Errorf("Open with byte %d flipped: got %v, want %v", i, err, errOpen)
+						}
+						ct[i] ^= 1
+					}
+
+					if _, err := aead.Open(nil, nonce, ct, append(ad, 0)); err != errOpen {
+						t.Errorf("Open with extended additional data: got %v, want %v", err, errOpen)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestNewCCMErrors(t *testing.T) {
+
+	block, _ := NewWithParameters(64, 24, tests[0].key)
+
+	for _, tst := range []struct {
+		tagSize, nonceSize int
+		err                error
+	}{
+		{2, 12, errTagSize},
+		{5, 12, errTagSize},
+		{18, 12, errTagSize},
+		{16, 6, errNonceSize},
+		{16, 14, errNonceSize},
+	} {
+		if _, err := NewCCM(block, tst.tagSize, tst.nonceSize); err != tst.err {
+			t.Errorf("NewCCM(%d, %d): got %v, want %v", tst.tagSize, tst.nonceSize, err, tst.err)
+		}
+	}
+
+	block, _ = New(tests[0].key)
+	if _, err := NewCCM(block, 16, 12); err != errCCMBlockSize {
+		t.Errorf("NewCCM with 64-bit block: got %v, want %v", err, errCCMBlockSize)
+	}
+}
@@ -0,0 +1,126 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+)
+
+const (
+	chainedNonceSize = 4
+	chainedTagSize   = BlockSize
+)
+
+type chained struct {
+	enc *Cipher
+	mac *Cipher
+}
+
+// NewChainedAEAD returns a cipher.AEAD built only from RC5-32/12/16 counter
+// mode and CMAC, in encrypt-then-MAC order.  Two independent subkeys are
+// derived from the 16-byte key with DeriveKeys.  The plaintext is encrypted in
+// counter mode starting from the counter block nonce||0, and the tag is
+// CMAC(nonce || additionalData || ciphertext || len(additionalData) ||
+// len(ciphertext)), with the lengths as 64-bit big-endian byte counts so the
+// split between the fields is unambiguous.
+//
+// The construction has the limits of its 64-bit block.  Nonces are 4 bytes and
+// must never repeat under one key, so they should come from a counter rather
+// than a random source; a message may be at most 2^32 blocks; and the 8-byte
+// tag allows forgery with probability 2^-64 per attempt.  Prefer NewEAX or
+// NewSIV unless a CMAC-only design is required.
+func NewChainedAEAD(key []byte) (cipher.AEAD, error) {
+
+	if l := len(key); l != 16 {
+		return nil, KeyLengthError{l, 16, 16}
+	}
+
+	keys, err := DeriveKeys(key, 2, 16)
+	if err != nil {
+		return nil, err
+	}
+
+	enc, _ := New(keys[0])
+	mac, _ := New(keys[1])
+
+	clear(keys[0])
+	clear(keys[1])
+
+	return &chained{enc: enc.(*Cipher), mac: mac.(*Cipher)}, nil
+}
+
+func (a *chained) NonceSize() int { return chainedNonceSize }
+func (a *chained) Overhead() int  { return chainedTagSize }
+
+func (a *chained) tag(nonce, additionalData, ciphertext []byte) []byte {
+
+	m := newCMAC(a.mac)
+	m.Write(nonce)
+	m.Write(additionalData)
+	m.Write(ciphertext)
+
+	var lengths [16]byte
+	binary.BigEndian.PutUint64(lengths[:8], uint64(len(additionalData)))
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(ciphertext)))
+	m.Write(lengths[:])
+
+	return m.Sum(nil)
+}
+
+func (a *chained) crypt(dst, src, nonce []byte) {
+
+	var iv [BlockSize]byte
+	copy(iv[:], nonce)
+
+	x, _ := newCTR(a.enc, iv[:])
+	x.XORKeyStream(dst, src)
+}
+
+func (a *chained) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+
+	if len(nonce) != chainedNonceSize {
+		panic("rc5: incorrect nonce length given to ChainedAEAD")
+	}
+
+	if uint64(len(plaintext)) > BlockSize<<32 {
+		panic("rc5: message too large for ChainedAEAD")
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+chainedTagSize)
+	if inexactOverlap(out, plaintext) {
+		panic("rc5: invalid buffer overlap")
+	}
+
+	a.crypt(out, plaintext, nonce)
+	copy(out[len(plaintext):], a.tag(nonce, additionalData, out[:len(plaintext)]))
+
+	return ret
+}
+
+func (a *chained) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+
+	if len(nonce) != chainedNonceSize {
+		panic("rc5: incorrect nonce length given to ChainedAEAD")
+	}
+
+	if len(ciphertext) < chainedTagSize {
+		return nil, errOpen
+	}
+
+	tag := ciphertext[len(ciphertext)-chainedTagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-chainedTagSize]
+
+	// verify before decrypting so no plaintext is released on failure
+	if subtle.ConstantTimeCompare(a.tag(nonce, additionalData, ciphertext), tag) != 1 {
+		return nil, errOpen
+	}
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	if inexactOverlap(out, ciphertext) {
+		panic("rc5: invalid buffer overlap")
+	}
+
+	a.crypt(out, ciphertext, nonce)
+
+	return ret, nil
+}
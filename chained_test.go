@@ -0,0 +1,99 @@
+package rc5
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestChainedAEAD(t *testing.T) {
+
+	key := seq(0, 16)
+	nonce := seq(0x40, 4)
+
+	aead, err := NewChainedAEAD(key)
+	if err != nil {
+		t.Fatalf("NewChainedAEAD failed: %v", err)
+	}
+
+	keys, _ := DeriveKeys(key, 2, 16)
+
+	for _, tst := range []struct {
+		plainLen, adLen int
+	}{
+		{0, 0},
+		{0, 5},
+		{5, 0},
+		{16, 3},
+		{33, 20},
+	} {
+		plain := seq(0x80, tst.plainLen)
+		ad := seq(0xC0, tst.adLen)
+
+		ct := aead.Seal(nil, nonce, plain, ad)
+
+		// the documented construction, built from the public pieces
+		want := make([]byte, len(plain))
+		s, _ := NewCTR(keys[0], append(bytes.Clone(nonce), 0, 0, 0, 0))
+		s.XORKeyStream(want, plain)
+
+		m, _ := NewCMAC(keys[1])
+		m.Write(nonce)
+		m.Write(ad)
+		m.Write(want)
+		binary.Write(m, binary.BigEndian, [2]uint64{uint64(len(ad)), uint64(len(want))})
+		want = m.Sum(want)
+
+		if !bytes.Equal(ct, want) {
+			t.Errorf("Seal(%d, %d):\ngot : % 02x\nwant: % 02x", tst.plainLen, tst.adLen, ct, want)
+		}
+
+		p, err := aead.Open(nil, nonce, ct, ad)
+		if err != nil {
+			t.Errorf("Open(%d, %d) failed: %v", tst.plainLen, tst.adLen, err)
+		}
+		if !bytes.Equal(p, plain) {
+			t.Errorf("Open(%d, %d):\ngot : % 02x\nwant: % 02x", tst.plainLen, tst.adLen, p, plain)
+		}
+
+		for i := range ct {
+			bad := bytes.Clone(ct)
+			bad[i] ^= 0x01
+			if p, err := aead.Open(nil, nonce, bad, ad); err != errOpen || p != nil {
+				t.Errorf("Open with byte %d flipped: got (% 02x, %v), want (nil, %v)", i, p, err, errOpen)
+			}
+		}
+
+		if _, err := aead.Open(nil, nonce, ct, append(bytes.Clone(ad), 0)); err != errOpen {
+			t.Errorf("Open with extended AD: got %v, want %v", err, errOpen)
+		}
+
+		if _, err := aead.Open(nil, seq(0x41, 4), ct, ad); err != errOpen {
+			t.Errorf("Open with wrong nonce: got %v, want %v", err, errOpen)
+		}
+
+		if _, err := aead.Open(nil, nonce, ct[:len(ct)-1], ad); err != errOpen {
+			t.Errorf("Open with truncated input: got %v, want %v", err, errOpen)
+		}
+	}
+
+	// moving a byte from the ciphertext to the AD changes the lengths
+	ct := aead.Seal(nil, nonce, seq(0x80, 8), seq(0xC0, 8))
+	moved := append(seq(0xC0, 8), ct[0])
+	if _, err := aead.Open(nil, nonce, ct[1:], moved); err != errOpen {
+		t.Errorf("Open with shifted boundary: got %v, want %v", err, errOpen)
+	}
+
+	// in place
+	plain := seq(0x80, 40)
+	buf := append(bytes.Clone(plain), make([]byte, aead.Overhead())...)
+	ct = aead.Seal(buf[:0], nonce, buf[:len(plain)], nil)
+	if p, err := aead.Open(ct[:0], nonce, ct, nil); err != nil || !bytes.Equal(p, plain) {
+		t.Errorf("in-place round trip failed: %v", err)
+	}
+
+	if _, err := NewChainedAEAD(key[:8]); !errors.Is(err, KeySizeError(8)) {
+		t.Errorf("NewChainedAEAD with 8 byte key: got %v, want KeySizeError(8)", err)
+	}
+}
@@ -0,0 +1,137 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"hash"
+)
+
+type cmac struct {
+	b      cipher.Block
+	k1, k2 []byte
+	x      []byte // chaining value
+	buf    []byte // pending input, never more than one block
+}
+
+// NewCMAC returns a hash.Hash computing the CMAC (OMAC1) of its input under
+// RC5-32/12/16 with the given key.  The tag is one 8-byte block.
+func NewCMAC(key []byte) (hash.Hash, error) {
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+	return newCMAC(b), nil
+}
+
+// MACOnly returns the 8-byte CMAC-RC5-32/12/16 tag of data under key, for
+// authenticating data which does not need to be encrypted.
+func MACOnly(key, data []byte) ([]byte, error) {
+
+	m, err := NewCMAC(key)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Write(data)
+
+	return m.Sum(nil), nil
+}
+
+// VerifyMAC reports whether tag is the MACOnly tag of data under key,
+// comparing in constant time.  It returns false if the key is invalid.
+func VerifyMAC(key, data, tag []byte) bool {
+
+	want, err := MACOnly(key, data)
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(want, tag) == 1
+}
+
+func newCMAC(b cipher.Block) *cmac {
+
+	bs := b.BlockSize()
+
+	m := &cmac{
+		b:   b,
+		k1:  make([]byte, bs),
+		k2:  make([]byte, bs),
+		x:   make([]byte, bs),
+		buf: make([]byte, 0, bs),
+	}
+
+	b.Encrypt(m.k1, m.k1)
+	dbl(m.k1, m.k1)
+	dbl(m.k2, m.k1)
+
+	return m
+}
+
+// dbl sets dst to src doubled in GF(2^n), for n-bit blocks of 64 or 128 bits.
+func dbl(dst, src []byte) {
+
+	// the reduction polynomials are x^64+x^4+x^3+x+1 and x^128+x^7+x^2+x+1
+	rb := byte(0x1B)
+	if len(src) == 16 {
+		rb = 0x87
+	}
+
+	msb := src[0] >> 7
+
+	for i := 0; i < len(src)-1; i++ {
+		dst[i] = src[i]<<1 | src[i+1]>>7
+	}
+
+	dst[len(src)-1] = src[len(src)-1]<<1 ^ byte(subtle.ConstantTimeSelect(int(msb), int(rb), 0))
+}
+
+func (m *cmac) Size() int      { return m.b.BlockSize() }
+func (m *cmac) BlockSize() int { return m.b.BlockSize() }
+
+func (m *cmac) Reset() {
+	clear(m.x)
+	m.buf = m.buf[:0]
+}
+
+func (m *cmac) Write(p []byte) (int, error) {
+
+	n := len(p)
+	bs := m.b.BlockSize()
+
+	for len(p) > 0 {
+		// the final block is treated specially, so only process a full
+		// buffer once we know more input follows it
+		if len(m.buf) == bs {
+			subtle.XORBytes(m.x, m.x, m.buf)
+			m.b.Encrypt(m.x, m.x)
+			m.buf = m.buf[:0]
+		}
+
+		k := min(bs-len(m.buf), len(p))
+		m.buf = append(m.buf, p[:k]...)
+		p = p[k:]
+	}
+
+	return n, nil
+}
+
+func (m *cmac) Sum(in []byte) []byte {
+
+	bs := m.b.BlockSize()
+
+	last := make([]byte, bs)
+	copy(last, m.buf)
+
+	if len(m.buf) == bs {
+		subtle.XORBytes(last, last, m.k1)
+	} else {
+		last[len(m.buf)] = 0x80
+		subtle.XORBytes(last, last, m.k2)
+	}
+
+	subtle.XORBytes(last, last, m.x)
+	m.b.Encrypt(last, last)
+
+	return append(in, last...)
+}
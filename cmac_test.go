@@ -0,0 +1,104 @@
+package rc5
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestCMAC(t *testing.T) {
+
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
+
+	// computed with a reference CMAC built on the reference RC5 code
+	for _, tst := range []struct {
+		len int
+		tag string
+	}{
+		{0, "06c002bdb08b3c68"},
+		{3, "1c434f5c92178be6"},
+		{8, "d204dc3d863cb307"},
+		{20, "d2fdb76a83b991d6"},
+		{32, "c48066711532f9c2"},
+	} {
+		msg := make([]byte, tst.len)
+		for i := range msg {
+			msg[i] = byte(i)
+		}
+
+		want, _ := hex.DecodeString(tst.tag)
+
+		h, err := NewCMAC(key)
+		if err != nil {
+			t.Fatalf("NewCMAC failed: %v", err)
+		}
+
+		h.Write(msg)
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("CMAC(len=%d)=% 02x, want % 02x", tst.len, got, want)
+		}
+
+		h.Reset()
+		for i := range msg {
+			h.Write(msg[i : i+1])
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("bytewise CMAC(len=%d)=% 02x, want % 02x", tst.len, got, want)
+		}
+	}
+}
+
+func TestMACOnly(t *testing.T) {
+
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
+
+	// the same vectors as TestCMAC
+	for _, tst := range []struct {
+		data []byte
+		tag  string
+	}{
+		{nil, "06c002bdb08b3c68"},
+		{[]byte{}, "06c002bdb08b3c68"},
+		{[]byte{0, 1, 2}, "1c434f5c92178be6"},
+	} {
+		want, _ := hex.DecodeString(tst.tag)
+
+		tag, err := MACOnly(key, tst.data)
+		if err != nil {
+			t.Fatalf("MACOnly failed: %v", err)
+		}
+
+		if !bytes.Equal(tag, want) {
+			t.Errorf("MACOnly(% 02x)=% 02x, want % 02x", tst.data, tag, want)
+		}
+
+		if !VerifyMAC(key, tst.data, tag) {
+			t.Errorf("VerifyMAC(% 02x) rejected a valid tag", tst.data)
+		}
+	}
+
+	data := []byte("authenticated, not encrypted")
+	tag, _ := MACOnly(key, data)
+
+	for _, tst := range []struct {
+		name      string
+		key, data []byte
+		tag       []byte
+	}{
+		{"flipped tag", key, data, append(bytes.Clone(tag[:7]), tag[7]^1)},
+		{"short tag", key, data, tag[:7]},
+		{"empty tag", key, data, nil},
+		{"changed data", key, []byte("authenticated, not encrypted."), tag},
+		{"empty data", key, nil, tag},
+		{"other key", seq(1, 16), data, tag},
+		{"bad key", key[:8], data, tag},
+	} {
+		if VerifyMAC(tst.key, tst.data, tst.tag) {
+			t.Errorf("VerifyMAC accepted %s", tst.name)
+		}
+	}
+
+	if _, err := MACOnly(key[:8], data); err == nil {
+		t.Error("MACOnly accepted an 8 byte key")
+	}
+}
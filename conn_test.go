@@ -0,0 +1,164 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestWrapConn(t *testing.T) {
+
+	key := tests[0].key
+
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+
+	ea, _ := WrapConn(a, key)
+	eb, _ := WrapConn(b, key)
+
+	long := make([]byte, 3*connMessageSize+123)
+	rand.Read(long)
+
+	messages := [][]byte{[]byte("hi"), seq(0, 8), long, []byte("bye")}
+
+	go func() {
+		for _, m := range messages {
+			if _, err := ea.Write(m); err != nil {
+				t.Errorf("Write(%d bytes) failed: %v", len(m), err)
+			}
+		}
+		ea.Close()
+	}()
+
+	// read in awkward sizes, so frames are split and joined
+	var got bytes.Buffer
+	buf := make([]byte, 1000)
+	for {
+		n, err := eb.Read(buf[:1+got.Len()%999])
+		got.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+	}
+
+	if want := bytes.Join(messages, nil); !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("WrapConn read %d bytes, want %d", got.Len(), len(want))
+	}
+}
+
+func TestWrapConnWire(t *testing.T) {
+
+	key := tests[0].key
+
+	a, b := net.Pipe()
+	defer b.Close()
+
+	ea, _ := WrapConn(a, key)
+
+	go func() {
+		ea.Write([]byte("attack at dawn"))
+		a.Close()
+	}()
+
+	// the wire carries a length, an IV and the CBC-Pad ciphertext
+	wire, _ := io.ReadAll(b)
+	if len(wire) != 4+8+16 || wire[3] != 24 {
+		t.Fatalf("wire frame % 02x, want 28 bytes with length 24", wire)
+	}
+
+	p, err := DecryptCBCPad(key, wire[4:12], wire[12:])
+	if err != nil || string(p) != "attack at dawn" {
+		t.Errorf("DecryptCBCPad of the frame: got (%q, %v)", p, err)
+	}
+}
+
+func TestWrapConnInvalid(t *testing.T) {
+
+	key := tests[0].key
+
+	for _, frame := range [][]byte{
+		{0, 0},                       // truncated header
+		{0, 0, 0, 8, 1, 2, 3, 4},     // too short for an IV and a block
+		{0, 0, 0, 20},                // not whole blocks
+		{0xFF, 0xFF, 0xFF, 0xFF},     // too long
+		{0, 0, 0, 16, 1, 2, 3, 4, 5}, // truncated body
+	} {
+		a, b := net.Pipe()
+		eb, _ := WrapConn(b, key)
+
+		go func() {
+			a.Write(frame)
+			a.Close()
+		}()
+
+		if _, err := eb.Read(make([]byte, 10)); err != errConnFrame {
+			t.Errorf("Read of frame % 02x: got %v, want %v", frame, err, errConnFrame)
+		}
+		b.Close()
+	}
+
+	if _, err := WrapConn(nil, make([]byte, 8)); err == nil {
+		t.Errorf("WrapConn with an 8-byte key succeeded")
+	}
+}
+
+func TestWrapConnTimeout(t *testing.T) {
+
+	key := tests[0].key
+	msg := []byte("a message split across a read timeout")
+
+	iv := seq(0x40, 8)
+	ct, _ := EncryptCBCPad(key, iv, msg)
+	frame := binary.BigEndian.AppendUint32(nil, uint32(len(iv)+len(ct)))
+	frame = append(append(frame, iv...), ct...)
+
+	// time out part way through the header and again part way through the
+	// body
+	for _, split := range [][]int{{2}, {6}, {2, 20}} {
+
+		a, b := net.Pipe()
+		eb, _ := WrapConn(b, key)
+
+		// each piece is written only after the previous Read has timed out
+		next := make(chan bool)
+		go func() {
+			start := 0
+			for _, end := range split {
+				a.Write(frame[start:end])
+				start = end
+				<-next
+			}
+			a.Write(frame[start:])
+		}()
+
+		buf := make([]byte, 100)
+		for range split {
+			eb.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+			_, err := eb.Read(buf)
+
+			var ne net.Error
+			if !errors.As(err, &ne) || !ne.Timeout() {
+				t.Fatalf("split %v: Read got %v, want a timeout", split, err)
+			}
+			next <- true
+		}
+
+		eb.SetReadDeadline(time.Time{})
+		n, err := eb.Read(buf)
+		if err != nil || !bytes.Equal(buf[:n], msg) {
+			t.Errorf("split %v: Read after timeouts: got (%q, %v), want %q", split, buf[:n], err, msg)
+		}
+
+		a.Close()
+		b.Close()
+	}
+}
@@ -0,0 +1,77 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// crossCheckSamples is the number of random keys and plaintexts CrossCheck
+// tries for each variant, after the all-zero key and plaintext.
+const crossCheckSamples = 16
+
+// A CrossCheckError is returned by CrossCheck for the first input on which
+// this package and the reference disagree.  Got is this package's ciphertext
+// and Want the reference's.  If the ciphertexts agree but decrypting does not
+// give back the plaintext, Got is the result of decrypting Want.
+type CrossCheckError struct {
+	Parameters
+	Key, Plaintext []byte
+	Got, Want      []byte
+}
+
+func (e CrossCheckError) Error() string {
+	return "rc5: " + e.Parameters.String() + " differs from the reference for key " + hex.EncodeToString(e.Key) +
+		" plaintext " + hex.EncodeToString(e.Plaintext) + ": got " + hex.EncodeToString(e.Got) + ", want " + hex.EncodeToString(e.Want)
+}
+
+// CrossCheck compares this package against a reference implementation of
+// RC5, for porting and refactoring.  For each variant in params it encrypts
+// an all-zero block under an all-zero key and then random blocks under random
+// keys of the variant's key length, calling ref for the expected ciphertext,
+// and checks that decrypting gives back the plaintext.  It returns a
+// CrossCheckError for the first disagreement, or the error from New for an
+// unsupported variant.
+func CrossCheck(ref func(params Parameters, key, pt []byte) []byte, params []Parameters) error {
+
+	for _, p := range params {
+
+		key := make([]byte, p.KeyLength)
+		b, err := p.New(key)
+		if err != nil {
+			return err
+		}
+
+		c := b.(*Cipher)
+		bs := c.BlockSize()
+
+		for i := 0; i <= crossCheckSamples; i++ {
+
+			pt := make([]byte, bs)
+			if i > 0 {
+				if _, err := rand.Read(key); err != nil {
+					return err
+				}
+				if _, err := rand.Read(pt); err != nil {
+					return err
+				}
+				c.Rekey(key)
+			}
+
+			got := make([]byte, bs)
+			c.Encrypt(got, pt)
+
+			want := ref(p, key, pt)
+			if !bytes.Equal(got, want) {
+				return CrossCheckError{p, key, pt, got, want}
+			}
+
+			c.Decrypt(got, want)
+			if !bytes.Equal(got, pt) {
+				return CrossCheckError{p, key, pt, got, want}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package rc5
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCrossCheck(t *testing.T) {
+
+	params := []Parameters{
+		{32, 12, 16},
+		{16, 16, 8},
+		{64, 24, 24},
+		{32, 0, 0},
+		{64, 255, 255},
+	}
+
+	self := func(p Parameters, key, pt []byte) []byte {
+		b, err := NewWithParameters(p.WordSize, p.Rounds, key)
+		if err != nil {
+			t.Fatalf("NewWithParameters(%v) failed: %v", p, err)
+		}
+		ct := make([]byte, b.BlockSize())
+		b.Encrypt(ct, pt)
+		return ct
+	}
+
+	if err := CrossCheck(self, params); err != nil {
+		t.Errorf("CrossCheck against itself: %v", err)
+	}
+
+	// a reference with one too few rounds
+	broken := func(p Parameters, key, pt []byte) []byte {
+		p.Rounds--
+		return self(p, key, pt)
+	}
+
+	err := CrossCheck(broken, params[1:2])
+
+	var e CrossCheckError
+	if !errors.As(err, &e) {
+		t.Fatalf("CrossCheck against a broken reference: got %v, want a CrossCheckError", err)
+	}
+
+	if e.Parameters != params[1] || len(e.Key) != 8 || len(e.Plaintext) != 4 {
+		t.Errorf("CrossCheckError has the wrong details: %v", err)
+	}
+
+	if want := self(params[1], e.Key, e.Plaintext); !bytes.Equal(e.Got, want) {
+		t.Errorf("CrossCheckError.Got:\ngot : % 02x\nwant: % 02x", e.Got, want)
+	}
+
+	if err := CrossCheck(self, []Parameters{{24, 12, 16}}); err != (ParameterError{"word size", 24}) {
+		t.Errorf("CrossCheck of an unsupported variant: got %v, want word size error", err)
+	}
+}
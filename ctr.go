@@ -0,0 +1,505 @@
+package rc5
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"sync"
+)
+
+type ctr struct {
+	c       *Cipher
+	counter uint64 // next counter block
+	ks      [8]byte
+	used    int  // bytes of ks already consumed
+	split   bool // only the low 32 bits of counter increment
+}
+
+// next returns the counter block n blocks after the current one.
+func (x *ctr) next(n uint64) uint64 {
+	if x.split {
+		return x.counter&^0xFFFFFFFF | uint64(uint32(x.counter)+uint32(n))
+	}
+	return x.counter + n
+}
+
+// NewCTR returns a cipher.Stream which encrypts with RC5-32/12/16 in counter
+// mode.  The output is identical to cipher.NewCTR, but four counter blocks
+// are encrypted at a time to hide the latency of the data-dependent rotations.
+// The length of iv must be the same as the block size.
+func NewCTR(key, iv []byte) (cipher.Stream, error) {
+
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	x, err := newCTR(b.(*Cipher), iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+func newCTR(c *Cipher, iv []byte) (*ctr, error) {
+	if len(iv) != c.BlockSize() {
+		return nil, errIVSize
+	}
+	return &ctr{c: c, counter: binary.BigEndian.Uint64(iv), used: 8}, nil
+}
+
+// NewCTRStream returns a cipher.Stream which encrypts with RC5-32/12/16 in
+// counter mode using the given nonce.  A nonce shorter than the block size is
+// placed in the high bytes of the first counter block and the remaining low
+// bytes, which form the block counter, start at zero.  For example a 4-byte
+// nonce allows 2^32 blocks before the counter carries into the nonce.  The
+// nonce must be between 1 byte and the block size long and must never be reused
+// with the same key.
+func NewCTRStream(key, nonce []byte) (cipher.Stream, error) {
+
+	if len(nonce) == 0 || len(nonce) > 8 {
+		return nil, errNonceSize
+	}
+
+	var iv [8]byte
+	copy(iv[:], nonce)
+
+	return NewCTR(key, iv[:])
+}
+
+// NewCTRWithNonce returns a cipher.Stream which encrypts with RC5-32/12/16 in
+// counter mode for a nonce of any non-zero length.  As in GCM, a nonce the
+// size of the block is used directly as the initial counter block, exactly as
+// NewCTR, while a nonce of any other length is first compressed to a block;
+// here with CMAC under the same key, so the initial counter is
+// CMAC(key, nonce).  Distinct nonces of the same length give independent
+// counters except with negligible probability.
+func NewCTRWithNonce(key, nonce []byte) (cipher.Stream, error) {
+
+	if len(nonce) == 0 {
+		return nil, errNonceSize
+	}
+
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := nonce
+	if len(nonce) != b.BlockSize() {
+		m := newCMAC(b)
+		m.Write(nonce)
+		iv = m.Sum(nil)
+	}
+
+	return &ctr{c: b.(*Cipher), counter: binary.BigEndian.Uint64(iv), used: 8}, nil
+}
+
+// NewCTRSplit returns a cipher.Stream which encrypts with RC5-32/12/16 in
+// counter mode with the 4-byte nonce fixed in the high half of each counter
+// block and a 32-bit block counter, starting at zero, in the low half.  Unlike
+// NewCTR and NewCTRStream, which increment the whole 64-bit block, the counter
+// wraps around within the low 32 bits and never carries into the nonce, so
+// the keystream repeats after 2^32 blocks (32 GiB).  The nonce must never be
+// reused with the same key.
+func NewCTRSplit(key, nonce []byte) (cipher.Stream, error) {
+
+	if len(nonce) != 4 {
+		return nil, errNonceSize
+	}
+
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	counter := uint64(binary.BigEndian.Uint32(nonce)) << 32
+
+	return &ctr{c: b.(*Cipher), counter: counter, used: 8, split: true}, nil
+}
+
+func (x *ctr) XORKeyStream(dst, src []byte) {
+
+	if len(dst) < len(src) {
+		panic("rc5: output smaller than input")
+	}
+
+	if inexactOverlap(dst[:len(src)], src) {
+		panic("rc5: invalid buffer overlap")
+	}
+
+	if x.used < len(x.ks) {
+		n := subtle.XORBytes(dst, src, x.ks[x.used:])
+		x.used += n
+		dst, src = dst[n:], src[n:]
+	}
+
+	for len(src) >= 32 {
+		x.c.ctr4(dst, src, [4]uint64{x.counter, x.next(1), x.next(2), x.next(3)})
+		x.counter = x.next(4)
+		dst, src = dst[32:], src[32:]
+	}
+
+	for len(src) > 0 {
+		binary.BigEndian.PutUint64(x.ks[:], x.counter)
+		x.c.Encrypt(x.ks[:], x.ks[:])
+		x.counter = x.next(1)
+
+		n := subtle.XORBytes(dst, src, x.ks[:])
+		x.used = n
+		dst, src = dst[n:], src[n:]
+	}
+}
+
+// A CTR is a counter mode cipher.Stream, as returned by NewCTR, which can also
+// seek to any offset in the keystream for random access to large files.
+type CTR struct {
+	ctr
+	iv uint64 // initial counter block
+}
+
+// NewSeekableCTR returns a CTR which encrypts with RC5-32/12/16 in counter
+// mode, producing the same keystream as NewCTR with the same key and iv.
+func NewSeekableCTR(key, iv []byte) (*CTR, error) {
+
+	s, err := NewCTR(key, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	x := s.(*ctr)
+
+	return &CTR{ctr: *x, iv: x.counter}, nil
+}
+
+var (
+	errSeekOffset = errors.New("rc5: negative seek offset")
+	errSeekWhence = errors.New("rc5: invalid seek whence")
+)
+
+// Seek implements io.Seeker, positioning the stream so the next call to
+// XORKeyStream continues as if the keystream had been used up to the new
+// offset.  Offsets are interpreted relative to the start of the keystream for
+// io.SeekStart or the current position for io.SeekCurrent; the keystream has
+// no end, so io.SeekEnd is not supported.
+func (x *CTR) Seek(offset int64, whence int) (int64, error) {
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += int64((x.counter-x.iv)*8) - int64(len(x.ks)-x.used)
+	default:
+		return 0, errSeekWhence
+	}
+
+	if offset < 0 {
+		return 0, errSeekOffset
+	}
+
+	x.counter = x.iv + uint64(offset/8)
+	x.used = len(x.ks)
+
+	if n := int(offset % 8); n != 0 {
+		binary.BigEndian.PutUint64(x.ks[:], x.counter)
+		x.c.Encrypt(x.ks[:], x.ks[:])
+		x.counter++
+		x.used = n
+	}
+
+	return offset, nil
+}
+
+var errRangeLength = errors.New("rc5: negative range length")
+
+// EncryptRange encrypts (or decrypts) length bytes starting at offset in
+// place: it reads them from r, XORs them with the part of the NewCTR keystream
+// for key and iv at the same offset, and writes the result to w at that
+// offset.  Only that range is touched, so part of a counter mode encrypted
+// file can be rewritten without the rest.  Typically r and w are the same
+// file.  An offset need not be block aligned.
+func EncryptRange(key, iv []byte, w io.WriterAt, r io.ReaderAt, offset, length int64) error {
+
+	if length < 0 {
+		return errRangeLength
+	}
+
+	x, err := NewSeekableCTR(key, iv)
+	if err != nil {
+		return err
+	}
+
+	if _, err := x.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	buf := make([]byte, min(length, ctrContextChunk))
+
+	for length > 0 {
+		p := buf[:min(length, int64(len(buf)))]
+
+		n, err := r.ReadAt(p, offset)
+		if n < len(p) {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+
+		x.XORKeyStream(p, p)
+
+		if _, err := w.WriteAt(p, offset); err != nil {
+			return err
+		}
+
+		offset += int64(len(p))
+		length -= int64(len(p))
+	}
+
+	return nil
+}
+
+// A StreamCipher encrypts records of any length with RC5-32/12/16 in counter
+// mode without expanding them.  It keeps its position in the keystream between
+// calls, so data may be passed in chunks of any size and the output is the
+// same as encrypting it all at once with NewCTR.
+type StreamCipher struct {
+	x *ctr
+}
+
+// NewStreamCipher returns a StreamCipher starting at counter block iv, whose
+// length must be the same as the block size.
+func NewStreamCipher(key, iv []byte) (*StreamCipher, error) {
+
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	x, err := newCTR(b.(*Cipher), iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamCipher{x: x}, nil
+}
+
+// Encrypt XORs src with the next len(src) bytes of keystream into dst.  Dst
+// must be at least as long as src, and the two must overlap entirely or not
+// at all.
+func (s *StreamCipher) Encrypt(dst, src []byte) { s.x.XORKeyStream(dst, src) }
+
+// Decrypt is the same operation as Encrypt, since counter mode is its own
+// inverse.
+func (s *StreamCipher) Decrypt(dst, src []byte) { s.x.XORKeyStream(dst, src) }
+
+// ctr4 XORs src with the keystream for four counter blocks into dst.  The
+// four encryptions are interleaved so their dependency chains can overlap.
+func (c *Cipher) ctr4(dst, src []byte, counters [4]uint64) {
+
+	var blk [32]byte
+	for i, ctr := range counters {
+		binary.BigEndian.PutUint64(blk[8*i:], ctr)
+	}
+
+	rk := c.rk32
+
+	A0, B0 := c.load32(blk[0:])+rk[0], c.load32(blk[4:])+rk[1]
+	A1, B1 := c.load32(blk[8:])+rk[0], c.load32(blk[12:])+rk[1]
+	A2, B2 := c.load32(blk[16:])+rk[0], c.load32(blk[20:])+rk[1]
+	A3, B3 := c.load32(blk[24:])+rk[0], c.load32(blk[28:])+rk[1]
+
+	for k := rk[2:]; len(k) >= 2; k = k[2:] {
+		k0, k1 := k[0], k[1]
+
+		A0 = rotl(A0^B0, B0) + k0
+		A1 = rotl(A1^B1, B1) + k0
+		A2 = rotl(A2^B2, B2) + k0
+		A3 = rotl(A3^B3, B3) + k0
+
+		B0 = rotl(B0^A0, A0) + k1
+		B1 = rotl(B1^A1, A1) + k1
+		B2 = rotl(B2^A2, A2) + k1
+		B3 = rotl(B3^A3, A3) + k1
+	}
+
+	c.store32(blk[0:], A0)
+	c.store32(blk[4:], B0)
+	c.store32(blk[8:], A1)
+	c.store32(blk[12:], B1)
+	c.store32(blk[16:], A2)
+	c.store32(blk[20:], B2)
+	c.store32(blk[24:], A3)
+	c.store32(blk[28:], B3)
+
+	subtle.XORBytes(dst[:32], src[:32], blk[:])
+}
+
+// Keystream fills out with the RC5-32/12/16 counter mode keystream for key
+// and iv, the bytes NewCTR would XOR with the plaintext.  The length of iv
+// must be the same as the block size.
+func Keystream(key, iv, out []byte) error {
+
+	s, err := NewCTR(key, iv)
+	if err != nil {
+		return err
+	}
+
+	clear(out)
+	s.XORKeyStream(out, out)
+
+	return nil
+}
+
+// XORKeyStreamAt XORs src with the RC5-32/12/16 counter mode keystream for key
+// starting at the caller's initialCounter, a big-endian block counter, and
+// writes the result to dst.  It is NewCTR with initialCounter as the IV, for
+// deterministic or convergent encryption where the counter is derived from
+// the content, such as from its hash; a counter must never be reused with the
+// same key for different data.  The counter wraps from 2^64-1 to 0.  Unlike
+// XORKeyStream it returns an error, rather than panicking, if dst is shorter
+// than src or the two overlap inexactly.
+func XORKeyStreamAt(key []byte, initialCounter [8]byte, dst, src []byte) error {
+
+	if len(dst) < len(src) {
+		return errOutputSize
+	}
+
+	if inexactOverlap(dst[:len(src)], src) {
+		return errOverlap
+	}
+
+	s, err := NewCTR(key, initialCounter[:])
+	if err != nil {
+		return err
+	}
+
+	s.XORKeyStream(dst, src)
+
+	return nil
+}
+
+// EncryptCTRWithCRC encrypts plaintext with RC5-32/12/16 in counter mode, as
+// NewCTR, returning a new slice together with the IEEE CRC-32 of the
+// plaintext.  The data is read once: each piece is checksummed and then
+// encrypted while it is still in cache.  The CRC is not a MAC and gives no
+// protection against deliberate modification.
+func EncryptCTRWithCRC(key, iv, plaintext []byte) (ciphertext []byte, crc uint32, err error) {
+
+	s, err := NewCTR(key, iv)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	const piece = 4 << 10
+
+	ciphertext = make([]byte, len(plaintext))
+	for i := 0; i < len(plaintext); i += piece {
+		p := plaintext[i:min(i+piece, len(plaintext))]
+		crc = crc32.Update(crc, crc32.IEEETable, p)
+		s.XORKeyStream(ciphertext[i:], p)
+	}
+
+	return ciphertext, crc, nil
+}
+
+// ctrContextChunk is the amount of data EncryptCTRContext encrypts between
+// checks of its context.
+const ctrContextChunk = 64 << 10
+
+// EncryptCTRContext encrypts (or decrypts) everything read from r with
+// RC5-32/12/16 in counter mode, as NewCTR, and writes it to w.  The data is
+// processed in 64 KiB chunks and ctx is checked before each one, so a
+// cancelled or expired context stops the work promptly and its error is
+// returned.  Any output already written to w is then incomplete, and it is up
+// to the caller to discard it.
+func EncryptCTRContext(ctx context.Context, key, iv []byte, r io.Reader, w io.Writer) error {
+
+	s, err := NewCTR(key, iv)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, ctrContextChunk)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := r.Read(buf)
+		if n > 0 {
+			s.XORKeyStream(buf[:n], buf[:n])
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+var errWorkers = errors.New("rc5: number of workers must be positive")
+
+// EncryptCTRParallel encrypts (or decrypts) data with RC5-32/12/16 in counter
+// mode using the given number of goroutines, returning a new slice.  Each
+// goroutine processes a contiguous range of blocks with its own copy of the
+// key schedule.  The output is identical to cipher.NewCTR with the same key
+// and iv.
+func EncryptCTRParallel(key, iv, data []byte, workers int) ([]byte, error) {
+
+	if workers < 1 {
+		return nil, errWorkers
+	}
+
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	bs := b.BlockSize()
+
+	if len(iv) != bs {
+		return nil, errIVSize
+	}
+
+	counter := binary.BigEndian.Uint64(iv)
+	dst := make([]byte, len(data))
+
+	blocks := (len(data) + bs - 1) / bs
+	per := (blocks + workers - 1) / workers
+
+	var wg sync.WaitGroup
+
+	for start := 0; start < blocks; start += per {
+
+		lo, hi := start*bs, min((start+per)*bs, len(data))
+
+		// the first range reuses the cipher created above
+		c := b.(*Cipher)
+		if start > 0 {
+			nb, _ := New(key)
+			c = nb.(*Cipher)
+		}
+
+		wg.Add(1)
+		go func(c *Cipher, counter uint64, dst, src []byte) {
+			defer wg.Done()
+			x := &ctr{c: c, counter: counter, used: bs}
+			x.XORKeyStream(dst, src)
+		}(c, counter+uint64(start), dst[lo:hi], data[lo:hi])
+	}
+
+	wg.Wait()
+
+	return dst, nil
+}
@@ -0,0 +1,636 @@
+package rc5
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"testing"
+)
+
+func TestCTR(t *testing.T) {
+
+	key := tests[0].key
+
+	for _, iv := range [][]byte{
+		{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+		// counter wraps around in the middle of the message
+		{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFD},
+	} {
+		block, _ := New(key)
+
+		for _, l := range []int{0, 1, 7, 8, 9, 31, 32, 33, 100, 1000} {
+
+			src := make([]byte, l)
+			rand.Read(src)
+
+			want := make([]byte, l)
+			cipher.NewCTR(block, iv).XORKeyStream(want, src)
+
+			s, err := NewCTR(key, iv)
+			if err != nil {
+				t.Fatalf("NewCTR failed: %v", err)
+			}
+
+			got := make([]byte, l)
+			s.XORKeyStream(got, src)
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("NewCTR differs from cipher.NewCTR (len=%d):\ngot : % 02x\nwant: % 02x", l, got, want)
+			}
+
+			// uneven chunks exercise the buffered keystream
+			s, _ = NewCTR(key, iv)
+			for i, n := 0, 1; i < l; i, n = i+n, n+5 {
+				n = min(n, l-i)
+				s.XORKeyStream(got[i:i+n], src[i:i+n])
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("chunked NewCTR differs from cipher.NewCTR (len=%d):\ngot : % 02x\nwant: % 02x", l, got, want)
+			}
+		}
+	}
+
+	if _, err := NewCTR(key, make([]byte, 16)); err != errIVSize {
+		t.Errorf("NewCTR with long IV: got %v, want %v", err, errIVSize)
+	}
+}
+
+func TestNewCTRStream(t *testing.T) {
+
+	key := tests[0].key
+
+	for _, nonce := range [][]byte{
+		{0x01},
+		{0x01, 0x02, 0x03, 0x04},
+		{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+	} {
+		src := make([]byte, 100)
+		rand.Read(src)
+
+		s, err := NewCTRStream(key, nonce)
+		if err != nil {
+			t.Fatalf("NewCTRStream(len=%d) failed: %v", len(nonce), err)
+		}
+
+		ct := make([]byte, len(src))
+		s.XORKeyStream(ct, src)
+
+		// the nonce is zero-padded to form the initial counter block
+		iv := make([]byte, 8)
+		copy(iv, nonce)
+		block, _ := New(key)
+		want := make([]byte, len(src))
+		cipher.NewCTR(block, iv).XORKeyStream(want, src)
+
+		if !bytes.Equal(ct, want) {
+			t.Errorf("NewCTRStream(len=%d) differs from cipher.NewCTR:\ngot : % 02x\nwant: % 02x", len(nonce), ct, want)
+		}
+
+		s, _ = NewCTRStream(key, nonce)
+		pt := make([]byte, len(ct))
+		s.XORKeyStream(pt, ct)
+
+		if !bytes.Equal(pt, src) {
+			t.Errorf("NewCTRStream(len=%d) is not its own inverse:\ngot : % 02x\nwant: % 02x", len(nonce), pt, src)
+		}
+	}
+
+	for _, l := range []int{0, 9} {
+		if _, err := NewCTRStream(key, make([]byte, l)); err != errNonceSize {
+			t.Errorf("NewCTRStream with %d byte nonce: got %v, want %v", l, err, errNonceSize)
+		}
+	}
+}
+
+func TestNewCTRWithNonce(t *testing.T) {
+
+	key := tests[0].key
+	block, _ := New(key)
+
+	src := make([]byte, 100)
+	rand.Read(src)
+
+	for _, nonce := range [][]byte{
+		seq(0x10, 1),
+		seq(0x10, 7),
+		seq(0x10, 8),
+		seq(0x10, 12),
+		seq(0x10, 16),
+	} {
+		s, err := NewCTRWithNonce(key, nonce)
+		if err != nil {
+			t.Fatalf("NewCTRWithNonce(len=%d) failed: %v", len(nonce), err)
+		}
+
+		got := make([]byte, len(src))
+		s.XORKeyStream(got, src)
+
+		// an exact-length nonce is the counter block, others are CMACed
+		iv := nonce
+		if len(nonce) != 8 {
+			m := newCMAC(block)
+			m.Write(nonce)
+			iv = m.Sum(nil)
+		}
+
+		want := make([]byte, len(src))
+		cipher.NewCTR(block, iv).XORKeyStream(want, src)
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("NewCTRWithNonce(len=%d):\ngot : % 02x\nwant: % 02x", len(nonce), got, want)
+		}
+
+		s, _ = NewCTRWithNonce(key, nonce)
+		s.XORKeyStream(got, got)
+
+		if !bytes.Equal(got, src) {
+			t.Errorf("NewCTRWithNonce(len=%d) round trip:\ngot : % 02x\nwant: % 02x", len(nonce), got, src)
+		}
+	}
+
+	// a 12-byte nonce differing only in the last byte gives a new keystream
+	a, b := make([]byte, 16), make([]byte, 16)
+	s, _ := NewCTRWithNonce(key, seq(0x10, 12))
+	s.XORKeyStream(a, a)
+	s, _ = NewCTRWithNonce(key, append(seq(0x10, 11), 0))
+	s.XORKeyStream(b, b)
+	if bytes.Equal(a, b) {
+		t.Errorf("NewCTRWithNonce gave the same keystream for different nonces")
+	}
+
+	if _, err := NewCTRWithNonce(key, nil); err != errNonceSize {
+		t.Errorf("NewCTRWithNonce with empty nonce: got %v, want %v", err, errNonceSize)
+	}
+}
+
+func TestSeekableCTR(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xF0}
+
+	src := make([]byte, 1000)
+	rand.Read(src)
+
+	want := make([]byte, len(src))
+	s, _ := NewCTR(key, iv)
+	s.XORKeyStream(want, src)
+
+	x, err := NewSeekableCTR(key, iv)
+	if err != nil {
+		t.Fatalf("NewSeekableCTR failed: %v", err)
+	}
+
+	for _, off := range []int{0, 1, 7, 8, 9, 31, 32, 33, 127, 128, 500, 999, 1000} {
+		for _, n := range []int{0, 1, 5, 8, 40, 1000} {
+
+			n = min(n, len(src)-off)
+
+			if pos, err := x.Seek(int64(off), io.SeekStart); err != nil || pos != int64(off) {
+				t.Fatalf("Seek(%d) = %d, %v", off, pos, err)
+			}
+
+			got := make([]byte, n)
+			x.XORKeyStream(got, src[off:off+n])
+
+			if !bytes.Equal(got, want[off:off+n]) {
+				t.Errorf("Seek(%d) then %d bytes:\ngot : % 02x\nwant: % 02x", off, n, got, want[off:off+n])
+			}
+		}
+	}
+
+	// seeking backwards after reading to the end
+	x.Seek(0, io.SeekStart)
+	got := make([]byte, len(src))
+	x.XORKeyStream(got, src)
+	x.Seek(3, io.SeekStart)
+	x.XORKeyStream(got[3:10], src[3:10])
+	if !bytes.Equal(got, want) {
+		t.Errorf("Seek(3) after full read:\ngot : % 02x\nwant: % 02x", got, want)
+	}
+
+	// relative seeks from the position after the read above
+	for _, tst := range []struct{ rel, pos int64 }{{0, 10}, {-3, 7}, {20, 27}, {-27, 0}} {
+		if pos, err := x.Seek(tst.rel, io.SeekCurrent); err != nil || pos != tst.pos {
+			t.Errorf("Seek(%d, io.SeekCurrent) = %d, %v, want %d", tst.rel, pos, err, tst.pos)
+		}
+	}
+	x.Seek(13, io.SeekCurrent)
+	x.XORKeyStream(got[13:20], src[13:20])
+	if !bytes.Equal(got, want) {
+		t.Errorf("relative Seek to 13:\ngot : % 02x\nwant: % 02x", got, want)
+	}
+
+	if _, err := x.Seek(-1, io.SeekStart); err != errSeekOffset {
+		t.Errorf("Seek(-1): got %v, want %v", err, errSeekOffset)
+	}
+
+	if _, err := x.Seek(0, io.SeekEnd); err != errSeekWhence {
+		t.Errorf("Seek(0, io.SeekEnd): got %v, want %v", err, errSeekWhence)
+	}
+}
+
+func BenchmarkCTR(b *testing.B) {
+	s, _ := NewCTR(tests[0].key, make([]byte, 8))
+	buf := make([]byte, 8192)
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.XORKeyStream(buf, buf)
+	}
+}
+
+func BenchmarkStdlibCTR(b *testing.B) {
+	block, _ := New(tests[0].key)
+	s := cipher.NewCTR(block, make([]byte, 8))
+	buf := make([]byte, 8192)
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.XORKeyStream(buf, buf)
+	}
+}
+
+func TestEncryptCTRParallel(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00}
+	block, _ := New(key)
+
+	for _, l := range []int{0, 1, 8, 100, 4096, 100003} {
+
+		src := make([]byte, l)
+		rand.Read(src)
+
+		want := make([]byte, l)
+		cipher.NewCTR(block, iv).XORKeyStream(want, src)
+
+		for _, workers := range []int{1, 2, 3, 8, 64} {
+
+			got, err := EncryptCTRParallel(key, iv, src, workers)
+			if err != nil {
+				t.Fatalf("EncryptCTRParallel failed: %v", err)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("EncryptCTRParallel(len=%d, workers=%d) differs from cipher.NewCTR", l, workers)
+			}
+		}
+	}
+
+	if _, err := EncryptCTRParallel(key, iv, nil, 0); err != errWorkers {
+		t.Errorf("EncryptCTRParallel with 0 workers: got %v, want %v", err, errWorkers)
+	}
+}
+
+func TestKeystream(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xF0}
+	block, _ := New(key)
+
+	for _, l := range []int{0, 1, 8, 31, 32, 33, 200} {
+
+		want := make([]byte, l)
+		cipher.NewCTR(block, iv).XORKeyStream(want, want)
+
+		// stale contents of out are ignored
+		got := bytes.Repeat([]byte{0xAA}, l)
+		if err := Keystream(key, iv, got); err != nil {
+			t.Fatalf("Keystream failed: %v", err)
+		}
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("Keystream(len=%d):\ngot : % 02x\nwant: % 02x", l, got, want)
+		}
+	}
+
+	if err := Keystream(key, iv[:7], make([]byte, 8)); err != errIVSize {
+		t.Errorf("Keystream with short IV: got %v, want %v", err, errIVSize)
+	}
+}
+
+func TestStreamCipher(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xF0}
+
+	plain := make([]byte, 200)
+	for i := range plain {
+		plain[i] = byte(i)
+	}
+
+	want := make([]byte, len(plain))
+	s, _ := NewCTR(key, iv)
+	s.XORKeyStream(want, plain)
+
+	for _, step := range []int{1, 3, 7, 33} {
+
+		sc, err := NewStreamCipher(key, iv)
+		if err != nil {
+			t.Fatalf("NewStreamCipher failed: %v", err)
+		}
+
+		got := make([]byte, len(plain))
+		for i := 0; i < len(plain); i += step {
+			end := min(i+step, len(plain))
+			sc.Encrypt(got[i:end], plain[i:end])
+		}
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("StreamCipher in %d byte chunks:\ngot : % 02x\nwant: % 02x", step, got, want)
+		}
+
+		dec, _ := NewStreamCipher(key, iv)
+		for i := 0; i < len(got); i += step {
+			end := min(i+step, len(got))
+			dec.Decrypt(got[i:end], got[i:end])
+		}
+
+		if !bytes.Equal(got, plain) {
+			t.Errorf("StreamCipher decrypt in %d byte chunks failed", step)
+		}
+	}
+
+	if _, err := NewStreamCipher(key, iv[:7]); err != errIVSize {
+		t.Errorf("NewStreamCipher with short IV: got %v, want %v", err, errIVSize)
+	}
+}
+
+func TestCTRSplit(t *testing.T) {
+
+	key := seq(0, 16)
+	nonce := []byte{0xDE, 0xAD, 0xBE, 0xEF}
+	b, _ := New(key)
+
+	// the peer's keystream: block i is E(nonce || i), for a 32-bit i
+	keystream := func(start uint32, n int) []byte {
+		var ks []byte
+		for i := 0; i < n; i++ {
+			blk := append(bytes.Clone(nonce), binary.BigEndian.AppendUint32(nil, start+uint32(i))...)
+			b.Encrypt(blk, blk)
+			ks = append(ks, blk...)
+		}
+		return ks
+	}
+
+	s, err := NewCTRSplit(key, nonce)
+	if err != nil {
+		t.Fatalf("NewCTRSplit failed: %v", err)
+	}
+
+	got := make([]byte, 8*10)
+	s.XORKeyStream(got[:3], got[:3])
+	s.XORKeyStream(got[3:], got[3:])
+
+	if want := keystream(0, 10); !bytes.Equal(got, want) {
+		t.Errorf("NewCTRSplit keystream:\ngot : % 02x\nwant: % 02x", got, want)
+	}
+
+	// near the end of the counter, the low half wraps without touching the
+	// nonce, through both the four-block and single-block paths
+	for _, n := range []int{1, 3, 4, 6} {
+
+		x, _ := NewCTRSplit(key, nonce)
+		x.(*ctr).counter |= 0xFFFFFFFE
+
+		got := make([]byte, 8*n)
+		x.XORKeyStream(got, got)
+
+		if want := keystream(0xFFFFFFFE, n); !bytes.Equal(got, want) {
+			t.Errorf("NewCTRSplit keystream wrapping, %d blocks:\ngot : % 02x\nwant: % 02x", n, got, want)
+		}
+	}
+
+	for _, l := range []int{0, 3, 5, 8} {
+		if _, err := NewCTRSplit(key, make([]byte, l)); err != errNonceSize {
+			t.Errorf("NewCTRSplit with a %d-byte nonce: got %v, want %v", l, err, errNonceSize)
+		}
+	}
+}
+
+func TestEncryptCTRWithCRC(t *testing.T) {
+
+	key := tests[0].key
+	iv := seq(0x40, 8)
+
+	for _, l := range []int{0, 1, 31, 4096, 4097, 100000} {
+
+		plain := make([]byte, l)
+		rand.Read(plain)
+
+		ct, crc, err := EncryptCTRWithCRC(key, iv, plain)
+		if err != nil {
+			t.Fatalf("EncryptCTRWithCRC(%d bytes) failed: %v", l, err)
+		}
+
+		if want := crc32.ChecksumIEEE(plain); crc != want {
+			t.Errorf("EncryptCTRWithCRC(%d bytes) CRC=%08x, want %08x", l, crc, want)
+		}
+
+		p := make([]byte, l)
+		s, _ := NewCTR(key, iv)
+		s.XORKeyStream(p, ct)
+		if !bytes.Equal(p, plain) {
+			t.Errorf("EncryptCTRWithCRC(%d bytes) did not decrypt to the plaintext", l)
+		}
+	}
+
+	if _, _, err := EncryptCTRWithCRC(key, seq(0, 7), nil); err != errIVSize {
+		t.Errorf("EncryptCTRWithCRC with a short IV: got %v, want %v", err, errIVSize)
+	}
+}
+
+func TestEncryptCTRContext(t *testing.T) {
+
+	key := tests[0].key
+	iv := seq(0x40, 8)
+
+	plain := make([]byte, 3*ctrContextChunk+100)
+	rand.Read(plain)
+
+	var ct bytes.Buffer
+	if err := EncryptCTRContext(context.Background(), key, iv, bytes.NewReader(plain), &ct); err != nil {
+		t.Fatalf("EncryptCTRContext failed: %v", err)
+	}
+
+	want := make([]byte, len(plain))
+	s, _ := NewCTR(key, iv)
+	s.XORKeyStream(want, plain)
+	if !bytes.Equal(ct.Bytes(), want) {
+		t.Errorf("EncryptCTRContext differs from NewCTR")
+	}
+
+	// cancel part way through an endless input
+	ctx, cancel := context.WithCancel(context.Background())
+	reads := 0
+	r := readerFunc(func(p []byte) (int, error) {
+		if reads++; reads == 3 {
+			cancel()
+		}
+		return len(p), nil
+	})
+
+	ct.Reset()
+	if err := EncryptCTRContext(ctx, key, iv, r, &ct); err != context.Canceled {
+		t.Errorf("EncryptCTRContext after cancel: got %v, want %v", err, context.Canceled)
+	}
+
+	if reads != 3 || ct.Len() != 3*ctrContextChunk {
+		t.Errorf("EncryptCTRContext after cancel: %d reads and %d bytes written, want 3 and %d", reads, ct.Len(), 3*ctrContextChunk)
+	}
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
+
+// memFile is an in-memory io.ReaderAt and io.WriterAt.
+type memFile []byte
+
+func (f memFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(f)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f memFile) WriteAt(p []byte, off int64) (int, error) {
+	return copy(f[off:], p), nil
+}
+
+func TestEncryptRange(t *testing.T) {
+
+	key := tests[0].key
+	iv := seq(0x40, 8)
+
+	plain := make([]byte, 3*ctrContextChunk)
+	rand.Read(plain)
+
+	encrypt := func(p []byte) []byte {
+		ct := make([]byte, len(p))
+		s, _ := NewCTR(key, iv)
+		s.XORKeyStream(ct, p)
+		return ct
+	}
+
+	for _, tst := range []struct {
+		offset, length int64
+	}{
+		{0, 0},
+		{100, 0},
+		{0, 8},
+		{3, 1},
+		{13, 27},
+		{8000, 5},
+		{ctrContextChunk - 3, ctrContextChunk + 10},
+		{0, int64(len(plain))},
+	} {
+		file := memFile(encrypt(plain))
+
+		// decrypt the range in place, edit it, and encrypt it again
+		if err := EncryptRange(key, iv, file, file, tst.offset, tst.length); err != nil {
+			t.Fatalf("EncryptRange(%d, %d) failed: %v", tst.offset, tst.length, err)
+		}
+
+		edited := bytes.Clone(plain)
+		rng := file[tst.offset : tst.offset+tst.length]
+		if !bytes.Equal(rng, plain[tst.offset:tst.offset+tst.length]) {
+			t.Errorf("EncryptRange(%d, %d) did not decrypt the range", tst.offset, tst.length)
+		}
+		for i := range rng {
+			rng[i] ^= 0xFF
+			edited[tst.offset+int64(i)] ^= 0xFF
+		}
+
+		if err := EncryptRange(key, iv, file, file, tst.offset, tst.length); err != nil {
+			t.Fatalf("EncryptRange(%d, %d) failed: %v", tst.offset, tst.length, err)
+		}
+
+		if !bytes.Equal(file, encrypt(edited)) {
+			t.Errorf("EncryptRange(%d, %d) edit does not match encrypting the edited file", tst.offset, tst.length)
+		}
+
+		// only the range changed
+		orig := encrypt(plain)
+		for i := range file {
+			inRange := int64(i) >= tst.offset && int64(i) < tst.offset+tst.length
+			if !inRange && file[i] != orig[i] {
+				t.Fatalf("EncryptRange(%d, %d) changed byte %d", tst.offset, tst.length, i)
+			}
+		}
+	}
+
+	file := memFile(make([]byte, 16))
+	if err := EncryptRange(key, iv, file, file, 10, 10); err != io.ErrUnexpectedEOF {
+		t.Errorf("EncryptRange past the end: got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if err := EncryptRange(key, iv, file, file, -1, 1); err != errSeekOffset {
+		t.Errorf("EncryptRange at a negative offset: got %v, want %v", err, errSeekOffset)
+	}
+	if err := EncryptRange(key, iv, file, file, 0, -1); err != errRangeLength {
+		t.Errorf("EncryptRange with a negative length: got %v, want %v", err, errRangeLength)
+	}
+}
+
+func TestXORKeyStreamAt(t *testing.T) {
+
+	key := tests[0].key
+	c, _ := New(key)
+	src := seq(0x80, 45)
+
+	for _, start := range []uint64{0, 0x0102030405060708, 1<<64 - 3} {
+
+		var counter [8]byte
+		binary.BigEndian.PutUint64(counter[:], start)
+
+		dst := make([]byte, len(src))
+		if err := XORKeyStreamAt(key, counter, dst, src); err != nil {
+			t.Fatalf("XORKeyStreamAt(%x) failed: %v", start, err)
+		}
+
+		// the counter increments as a 64-bit integer, wrapping to zero
+		want := make([]byte, len(src))
+		for i := 0; i < len(src); i += 8 {
+			var ks [8]byte
+			binary.BigEndian.PutUint64(ks[:], start+uint64(i/8))
+			c.Encrypt(ks[:], ks[:])
+			subtle.XORBytes(want[i:], src[i:], ks[:])
+		}
+
+		if !bytes.Equal(dst, want) {
+			t.Errorf("XORKeyStreamAt(%x):\ngot : % 02x\nwant: % 02x", start, dst, want)
+		}
+
+		again := make([]byte, len(src))
+		XORKeyStreamAt(key, counter, again, src)
+		if !bytes.Equal(again, dst) {
+			t.Errorf("XORKeyStreamAt(%x) is not deterministic", start)
+		}
+
+		// decrypt in place
+		if err := XORKeyStreamAt(key, counter, dst, dst); err != nil || !bytes.Equal(dst, src) {
+			t.Errorf("XORKeyStreamAt(%x) in place: got (% 02x, %v), want % 02x", start, dst, err, src)
+		}
+	}
+
+	var counter [8]byte
+	if err := XORKeyStreamAt(key, counter, make([]byte, 4), src); err != errOutputSize {
+		t.Errorf("XORKeyStreamAt with short dst: got %v, want %v", err, errOutputSize)
+	}
+	if err := XORKeyStreamAt(key, counter, src[1:], src[:40]); err != errOverlap {
+		t.Errorf("XORKeyStreamAt with overlapping buffers: got %v, want %v", err, errOverlap)
+	}
+	if err := XORKeyStreamAt(key[:5], counter, make([]byte, 8), src[:8]); err == nil {
+		t.Errorf("XORKeyStreamAt accepted a 5-byte key")
+	}
+}
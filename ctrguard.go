@@ -0,0 +1,71 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"errors"
+	"sync"
+)
+
+var (
+	errIVReused = errors.New("rc5: IV has already been used")
+	errIVStore  = errors.New("rc5: nil IVStore")
+)
+
+// An IVStore remembers the IVs used with one key, for NewCTRGuarded.
+type IVStore interface {
+	// Seen reports whether iv has been recorded.
+	Seen(iv []byte) bool
+
+	// Record adds iv to the store.  The store must copy iv if it keeps it.
+	Record(iv []byte)
+}
+
+type memoryIVStore struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// NewMemoryIVStore returns an IVStore which keeps every recorded IV in memory
+// for the life of the process.  It is safe for concurrent use.
+func NewMemoryIVStore() IVStore {
+	return &memoryIVStore{seen: make(map[string]struct{})}
+}
+
+func (s *memoryIVStore) Seen(iv []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.seen[string(iv)]
+	return ok
+}
+
+func (s *memoryIVStore) Record(iv []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seen[string(iv)] = struct{}{}
+}
+
+// NewCTRGuarded is like NewCTR, but first checks iv against store and returns
+// an error if it has been used before, recording it otherwise.  Reusing an IV
+// in counter mode reveals the XOR of the two plaintexts.  A store belongs to a
+// single key, and callers sharing one store between goroutines must serialize
+// their calls to NewCTRGuarded, as the check and the record are separate
+// operations.
+func NewCTRGuarded(key, iv []byte, store IVStore) (cipher.Stream, error) {
+
+	if store == nil {
+		return nil, errIVStore
+	}
+
+	s, err := NewCTR(key, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	if store.Seen(iv) {
+		return nil, errIVReused
+	}
+
+	store.Record(iv)
+
+	return s, nil
+}
@@ -0,0 +1,55 @@
+package rc5
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewCTRGuarded(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+	store := NewMemoryIVStore()
+
+	s, err := NewCTRGuarded(key, iv, store)
+	if err != nil {
+		t.Fatalf("NewCTRGuarded failed: %v", err)
+	}
+
+	plain := make([]byte, 40)
+	got := make([]byte, len(plain))
+	s.XORKeyStream(got, plain)
+
+	want := make([]byte, len(plain))
+	ctr, _ := NewCTR(key, iv)
+	ctr.XORKeyStream(want, plain)
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("NewCTRGuarded keystream:\ngot : % 02x\nwant: % 02x", got, want)
+	}
+
+	// the store keeps its own copy of the IV
+	reused := bytes.Clone(iv)
+	iv[0] ^= 0xFF
+
+	if _, err := NewCTRGuarded(key, reused, store); err != errIVReused {
+		t.Errorf("NewCTRGuarded with reused IV: got %v, want %v", err, errIVReused)
+	}
+
+	if _, err := NewCTRGuarded(key, iv, store); err != nil {
+		t.Errorf("NewCTRGuarded with fresh IV failed: %v", err)
+	}
+
+	// an invalid IV is not recorded
+	if _, err := NewCTRGuarded(key, iv[:7], store); err != errIVSize {
+		t.Errorf("NewCTRGuarded with short IV: got %v, want %v", err, errIVSize)
+	}
+	if store.Seen(iv[:7]) {
+		t.Error("short IV was recorded")
+	}
+
+	if _, err := NewCTRGuarded(key, iv, nil); err != errIVStore {
+		t.Errorf("NewCTRGuarded with nil store: got %v, want %v", err, errIVStore)
+	}
+}
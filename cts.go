@@ -0,0 +1,134 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+var errCTSInput = errors.New("rc5: ciphertext stealing requires at least one full block")
+
+// A CTS encrypts or decrypts whole messages with RC5-32/12/16 in CBC mode with
+// ciphertext stealing, using the CS3 ordering from the addendum to NIST SP
+// 800-38A: the last two ciphertext blocks are always swapped.  The ciphertext
+// has the same length as the plaintext.
+type CTS struct {
+	b       cipher.Block
+	iv      []byte
+	decrypt bool
+}
+
+func newCTS(key, iv []byte, decrypt bool) (*CTS, error) {
+
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(iv) != b.BlockSize() {
+		return nil, errIVSize
+	}
+
+	return &CTS{b: b, iv: append([]byte(nil), iv...), decrypt: decrypt}, nil
+}
+
+// NewCTSEncrypter returns a CTS which encrypts messages with the given key and iv.
+func NewCTSEncrypter(key, iv []byte) (*CTS, error) { return newCTS(key, iv, false) }
+
+// NewCTSDecrypter returns a CTS which decrypts messages with the given key and iv.
+func NewCTSDecrypter(key, iv []byte) (*CTS, error) { return newCTS(key, iv, true) }
+
+// CryptBlocks encrypts or decrypts the message in src into dst, which must be
+// at least as long as src.  Each call processes an independent message
+// starting from the IV.  The message must be at least one block long.
+func (x *CTS) CryptBlocks(dst, src []byte) error {
+
+	bs := x.b.BlockSize()
+
+	if len(src) < bs {
+		return errCTSInput
+	}
+
+	if len(dst) < len(src) {
+		return errors.New("rc5: output smaller than input")
+	}
+
+	if x.decrypt {
+		x.decryptBlocks(dst[:len(src)], src)
+	} else {
+		x.encryptBlocks(dst[:len(src)], src)
+	}
+
+	return nil
+}
+
+func (x *CTS) encryptBlocks(dst, src []byte) {
+
+	bs := x.b.BlockSize()
+
+	if len(src) == bs {
+		subtle.XORBytes(dst, src, x.iv)
+		x.b.Encrypt(dst, dst)
+		return
+	}
+
+	// number of bytes in the final (possibly partial) block
+	d := len(src) % bs
+	if d == 0 {
+		d = bs
+	}
+
+	// full blocks before the final one
+	n := len(src) - d
+
+	cipher.NewCBCEncrypter(x.b, x.iv).CryptBlocks(dst[:n], src[:n])
+
+	penultimate := make([]byte, bs)
+	copy(penultimate, dst[n-bs:n])
+
+	last := make([]byte, bs)
+	copy(last, src[n:])
+	subtle.XORBytes(last, last, penultimate)
+	x.b.Encrypt(last, last)
+
+	copy(dst[n-bs:], last)
+	copy(dst[n:], penultimate[:d])
+}
+
+func (x *CTS) decryptBlocks(dst, src []byte) {
+
+	bs := x.b.BlockSize()
+
+	if len(src) == bs {
+		x.b.Decrypt(dst, src)
+		subtle.XORBytes(dst, dst, x.iv)
+		return
+	}
+
+	d := len(src) % bs
+	if d == 0 {
+		d = bs
+	}
+
+	n := len(src) - d
+
+	// chaining value for the penultimate block
+	prev := x.iv
+	if n > bs {
+		prev = append([]byte(nil), src[n-2*bs:n-bs]...)
+	}
+
+	z := make([]byte, bs)
+	x.b.Decrypt(z, src[n-bs:n])
+
+	penultimate := make([]byte, bs)
+	copy(penultimate, src[n:])
+	copy(penultimate[d:], z[d:])
+
+	cipher.NewCBCDecrypter(x.b, x.iv).CryptBlocks(dst[:n-bs], src[:n-bs])
+
+	subtle.XORBytes(dst[n:], z[:d], penultimate[:d])
+
+	x.b.Decrypt(dst[n-bs:n], penultimate)
+	subtle.XORBytes(dst[n-bs:n], dst[n-bs:n], prev)
+}
@@ -0,0 +1,90 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"testing"
+)
+
+func TestCTS(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+	enc, err := NewCTSEncrypter(key, iv)
+	if err != nil {
+		t.Fatalf("NewCTSEncrypter failed: %v", err)
+	}
+
+	dec, err := NewCTSDecrypter(key, iv)
+	if err != nil {
+		t.Fatalf("NewCTSDecrypter failed: %v", err)
+	}
+
+	for l := 8; l <= 40; l++ {
+
+		plain := make([]byte, l)
+		for i := range plain {
+			plain[i] = byte(i)
+		}
+
+		ct := make([]byte, l)
+		if err := enc.CryptBlocks(ct, plain); err != nil {
+			t.Fatalf("CTS encrypt (len=%d) failed: %v", l, err)
+		}
+
+		p := make([]byte, l)
+		if err := dec.CryptBlocks(p, ct); err != nil {
+			t.Fatalf("CTS decrypt (len=%d) failed: %v", l, err)
+		}
+
+		if !bytes.Equal(p, plain) {
+			t.Errorf("CTS round trip failed (len=%d):\ngot : % 02x\nwant: % 02x", l, p, plain)
+		}
+
+		// in place
+		if err := dec.CryptBlocks(ct, ct); err != nil || !bytes.Equal(ct, plain) {
+			t.Errorf("CTS in-place decrypt failed (len=%d):\ngot : % 02x\nwant: % 02x", l, ct, plain)
+		}
+	}
+}
+
+func TestCTSMatchesCBC(t *testing.T) {
+
+	key := tests[0].key
+	iv := make([]byte, 8)
+	block, _ := New(key)
+
+	plain := make([]byte, 24)
+	for i := range plain {
+		plain[i] = byte(i)
+	}
+
+	cbc := make([]byte, 24)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(cbc, plain)
+
+	// CS3 swaps the final two blocks even for aligned input
+	want := append(append(append([]byte(nil), cbc[:8]...), cbc[16:]...), cbc[8:16]...)
+
+	enc, _ := NewCTSEncrypter(key, iv)
+	ct := make([]byte, 24)
+	enc.CryptBlocks(ct, plain)
+
+	if !bytes.Equal(ct, want) {
+		t.Errorf("CTS on aligned input:\ngot : % 02x\nwant: % 02x", ct, want)
+	}
+
+	enc.CryptBlocks(ct[:8], plain[:8])
+	if !bytes.Equal(ct[:8], cbc[:8]) {
+		t.Errorf("CTS on single block:\ngot : % 02x\nwant: % 02x", ct[:8], cbc[:8])
+	}
+}
+
+func TestCTSShort(t *testing.T) {
+
+	enc, _ := NewCTSEncrypter(tests[0].key, make([]byte, 8))
+
+	if err := enc.CryptBlocks(make([]byte, 7), make([]byte, 7)); err != errCTSInput {
+		t.Errorf("CTS with short input: got %v, want %v", err, errCTSInput)
+	}
+}
@@ -0,0 +1,38 @@
+package rc5
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+)
+
+// NewDeterministic returns an RC5 keyed from seed, together with an IV, both
+// derived from seed with HKDF-SHA256 and the info labels
+// "rc5 deterministic key" and "rc5 deterministic iv".  The same seed always
+// gives the same key and IV, for reproducible ciphertext in golden tests.
+//
+// NewDeterministic is intended for tests.  The IV is fixed for a seed, so
+// using it for more than one message breaks the security of CBC and CTR.
+func NewDeterministic(seed []byte) (*RC5, []byte, error) {
+
+	if len(seed) == 0 {
+		return nil, nil, errSeed
+	}
+
+	key, err := hkdf.Key(sha256.New, seed, nil, "rc5 deterministic key", 16)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	iv, err := hkdf.Key(sha256.New, seed, nil, "rc5 deterministic iv", BlockSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := NewRC5(key)
+	clear(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, iv, nil
+}
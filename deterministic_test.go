@@ -0,0 +1,47 @@
+package rc5
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestNewDeterministic(t *testing.T) {
+
+	seed := []byte("golden")
+
+	r, iv, err := NewDeterministic(seed)
+	if err != nil {
+		t.Fatalf("NewDeterministic failed: %v", err)
+	}
+
+	// computed with an independent HKDF and RC5
+	wantIV, _ := hex.DecodeString("46cb64a645a0410a")
+	wantCT, _ := hex.DecodeString("e18594ff04caf9d9")
+
+	if !bytes.Equal(iv, wantIV) {
+		t.Errorf("NewDeterministic IV:\ngot : % 02x\nwant: % 02x", iv, wantIV)
+	}
+
+	ct := make([]byte, 8)
+	r.Encrypt(ct, ct)
+	if !bytes.Equal(ct, wantCT) {
+		t.Errorf("NewDeterministic key encrypts zero block to:\ngot : % 02x\nwant: % 02x", ct, wantCT)
+	}
+
+	for i := 0; i < 3; i++ {
+		r2, iv2, _ := NewDeterministic(seed)
+		if !r2.Equal(r.Cipher) || !bytes.Equal(iv2, iv) {
+			t.Errorf("NewDeterministic not reproducible: IV % 02x, want % 02x", iv2, iv)
+		}
+	}
+
+	other, otherIV, _ := NewDeterministic([]byte("golden2"))
+	if other.Equal(r.Cipher) || bytes.Equal(otherIV, iv) {
+		t.Errorf("NewDeterministic gave the same key or IV for two seeds")
+	}
+
+	if _, _, err := NewDeterministic(nil); err != errSeed {
+		t.Errorf("NewDeterministic(nil): got %v, want %v", err, errSeed)
+	}
+}
@@ -0,0 +1,46 @@
+package rc5
+
+import "crypto/subtle"
+
+// EncryptDouble encrypts a 16-byte record as two RC5-32/12/16 blocks, the
+// second chained to the first: the first half is encrypted directly and the
+// second is XORed with the first half's ciphertext before being encrypted, as
+// in CBC with a zero IV.  A change to the first half therefore changes the
+// whole ciphertext, though a change to the second half leaves the first half
+// of the ciphertext alone.  EncryptDouble panics if key is not 16 bytes.
+//
+// EncryptDouble is a compatibility construction for a format with 16-byte
+// records.  It is not standard RC5 and is not a 128-bit block cipher; use
+// NewWithParameters with a 64-bit word size for RC5-64.
+func EncryptDouble(key []byte, block [16]byte) [16]byte {
+
+	c := mustNewDouble(key)
+
+	var dst [16]byte
+	c.Encrypt(dst[:8], block[:8])
+	subtle.XORBytes(dst[8:], block[8:], dst[:8])
+	c.Encrypt(dst[8:], dst[8:])
+
+	return dst
+}
+
+// DecryptDouble reverses EncryptDouble.  It panics if key is not 16 bytes.
+func DecryptDouble(key []byte, block [16]byte) [16]byte {
+
+	c := mustNewDouble(key)
+
+	var dst [16]byte
+	c.Decrypt(dst[8:], block[8:])
+	subtle.XORBytes(dst[8:], dst[8:], block[:8])
+	c.Decrypt(dst[:8], block[:8])
+
+	return dst
+}
+
+func mustNewDouble(key []byte) *Cipher {
+	b, err := New(key)
+	if err != nil {
+		panic(err)
+	}
+	return b.(*Cipher)
+}
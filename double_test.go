@@ -0,0 +1,53 @@
+package rc5
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncryptDouble(t *testing.T) {
+
+	key := seq(0, 16)
+
+	var block [16]byte
+	copy(block[:], seq(0x80, 16))
+
+	ct := EncryptDouble(key, block)
+
+	// the same as CBC with a zero IV
+	want := make([]byte, 16)
+	enc, _ := NewCBCEncrypter(key, make([]byte, 8))
+	enc.CryptBlocks(want, block[:])
+	if !bytes.Equal(ct[:], want) {
+		t.Errorf("EncryptDouble:\ngot : % 02x\nwant: % 02x", ct[:], want)
+	}
+
+	if got := DecryptDouble(key, ct); got != block {
+		t.Errorf("DecryptDouble:\ngot : % 02x\nwant: % 02x", got[:], block[:])
+	}
+
+	for i := 0; i < 8; i++ {
+		b := block
+		b[i] ^= 0x01
+		got := EncryptDouble(key, b)
+		if bytes.Equal(got[8:], ct[8:]) {
+			t.Errorf("flipping byte %d of the first half left the second half unchanged: % 02x", i, got[8:])
+		}
+		if DecryptDouble(key, got) != b {
+			t.Errorf("DecryptDouble with byte %d flipped did not round trip", i)
+		}
+	}
+}
+
+func TestEncryptDoubleKeySize(t *testing.T) {
+
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, KeySizeError(8)) {
+			t.Errorf("EncryptDouble with an 8-byte key: got panic %v, want KeySizeError(8)", err)
+		}
+	}()
+
+	EncryptDouble(seq(0, 8), [16]byte{})
+}
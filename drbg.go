@@ -0,0 +1,121 @@
+package rc5
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	drbgSeedLen   = 16 + BlockSize // key length plus block length
+	drbgChunk     = 1 << 10        // bytes per generate, 2^13 bits as for TDEA
+	drbgMaxChunks = 1 << 32        // generates allowed before reseeding
+)
+
+var (
+	errSeed          = errors.New("rc5: seed must not be empty")
+	errDRBGExhausted = errors.New("rc5: DRBG output limit reached")
+)
+
+// A DRBG is a deterministic random bit generator modelled on CTR_DRBG from
+// NIST SP 800-90A without a derivation function, using RC5-32/12/16 as the
+// block cipher.  Output is produced in 1 KiB generate requests, each followed
+// by a key and counter update, and handed out through Read, so the stream
+// depends only on the seed and not on how it is read.  It is intended for
+// reproducible test data; it has not been validated against NIST test
+// vectors.  A DRBG is not safe for concurrent use.
+type DRBG struct {
+	c      *Cipher
+	v      uint64 // counter block
+	buf    []byte // unread output of the last generate
+	chunks uint64 // generates so far
+}
+
+// NewDRBG returns a DRBG instantiated from seed.  The seed is condensed with
+// SHA-256 to the 24 bytes of seed material CTR_DRBG needs, so it may be any
+// non-empty length.
+func NewDRBG(seed []byte) (*DRBG, error) {
+
+	if len(seed) == 0 {
+		return nil, errSeed
+	}
+
+	c, err := New(make([]byte, 16))
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DRBG{c: c.(*Cipher)}
+
+	h := sha256.Sum256(seed)
+	d.update(h[:drbgSeedLen])
+
+	return d, nil
+}
+
+// block encrypts the next counter block into dst.
+func (d *DRBG) block(dst []byte) {
+	d.v++
+	binary.BigEndian.PutUint64(dst, d.v)
+	d.c.Encrypt(dst, dst)
+}
+
+// update is the CTR_DRBG update function, replacing the key and counter with
+// the next seedlen bytes of output XORed with provided.
+func (d *DRBG) update(provided []byte) {
+
+	var tmp [drbgSeedLen]byte
+	for i := 0; i < len(tmp); i += BlockSize {
+		d.block(tmp[i:])
+	}
+
+	subtle.XORBytes(tmp[:], tmp[:], provided)
+
+	d.c.Rekey(tmp[:16])
+	d.v = binary.BigEndian.Uint64(tmp[16:])
+
+	clear(tmp[:])
+}
+
+// Read fills p with the next len(p) bytes of output.  It fails only once the
+// generator has produced 2^32 generate requests, after which CTR_DRBG requires
+// reseeding.
+func (d *DRBG) Read(p []byte) (int, error) {
+
+	n := 0
+
+	for len(p) > 0 {
+		if len(d.buf) == 0 {
+			if d.chunks == drbgMaxChunks {
+				return n, errDRBGExhausted
+			}
+			d.generate()
+		}
+
+		k := copy(p, d.buf)
+		d.buf = d.buf[k:]
+		p = p[k:]
+		n += k
+	}
+
+	return n, nil
+}
+
+// generate produces the next chunk of output into d.buf.
+func (d *DRBG) generate() {
+
+	if cap(d.buf) < drbgChunk {
+		d.buf = make([]byte, drbgChunk)
+	}
+	d.buf = d.buf[:drbgChunk]
+
+	for i := 0; i < len(d.buf); i += BlockSize {
+		d.block(d.buf[i:])
+	}
+
+	var zero [drbgSeedLen]byte
+	d.update(zero[:])
+
+	d.chunks++
+}
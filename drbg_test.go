@@ -0,0 +1,67 @@
+package rc5
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+var _ io.Reader = (*DRBG)(nil)
+
+func TestDRBG(t *testing.T) {
+
+	seed := []byte("rc5 drbg test")
+
+	d, err := NewDRBG(seed)
+	if err != nil {
+		t.Fatalf("NewDRBG failed: %v", err)
+	}
+
+	want := make([]byte, 3*drbgChunk+100)
+	if _, err := io.ReadFull(d, want); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	// computed with a reference implementation built on the reference RC5
+	// code
+	for _, tst := range []struct {
+		off int
+		hex string
+	}{
+		{0, "220b978da1162d88c14c882bdea068aad79ae47c89d5eb7d2ce9ebc0d7824a7f"},
+		{drbgChunk, "3d15187f5cae5ee8d660115209eaa710"},
+	} {
+		w, _ := hex.DecodeString(tst.hex)
+		if got := want[tst.off : tst.off+len(w)]; !bytes.Equal(got, w) {
+			t.Errorf("output at %d:\ngot : % 02x\nwant: % 02x", tst.off, got, w)
+		}
+	}
+
+	// the same seed gives the same stream however it is read
+	for _, step := range []int{1, 7, 1000, 4096} {
+		d, _ := NewDRBG(seed)
+
+		got := make([]byte, 0, len(want))
+		buf := make([]byte, step)
+		for len(got) < len(want) {
+			n, _ := d.Read(buf[:min(step, len(want)-len(got))])
+			got = append(got, buf[:n]...)
+		}
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("reading in %d byte steps gave a different stream", step)
+		}
+	}
+
+	other, _ := NewDRBG([]byte("rc5 drbg test."))
+	got := make([]byte, len(want))
+	other.Read(got)
+	if bytes.Equal(got[:16], want[:16]) || bytes.Equal(got[drbgChunk:drbgChunk+16], want[drbgChunk:drbgChunk+16]) {
+		t.Error("different seeds gave the same output")
+	}
+
+	if _, err := NewDRBG(nil); err != errSeed {
+		t.Errorf("NewDRBG(nil): got %v, want %v", err, errSeed)
+	}
+}
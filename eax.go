@@ -0,0 +1,198 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+const eaxDefaultNonceSize = 8
+
+var (
+	errOpen      = errors.New("rc5: message authentication failed")
+	errNonceSize = errors.New("rc5: invalid nonce size")
+	errEAXLimit  = errors.New("rc5: EAX input exceeds the configured limit")
+	errLimit     = errors.New("rc5: invalid EAX input limit")
+)
+
+type eax struct {
+	b         cipher.Block
+	nonceSize int
+	tagSize   int
+	nonces    nonceTracker // detects nonce reuse in rc5debug builds
+
+	// the longest plaintext and additional data accepted, or -1 for no limit
+	maxPlaintext, maxAD int
+}
+
+// NewEAX returns a cipher.AEAD implementing the EAX mode of Bellare, Rogaway
+// and Wagner over RC5-32/12/16, using 8-byte nonces and 8-byte tags.
+func NewEAX(key []byte) (cipher.AEAD, error) {
+	return NewEAXWithNonceSize(key, eaxDefaultNonceSize)
+}
+
+// NewEAXWithNonceSize is like NewEAX but accepts nonces of the given
+// length, which must be positive.
+func NewEAXWithNonceSize(key []byte, size int) (cipher.AEAD, error) {
+
+	if size <= 0 {
+		return nil, errNonceSize
+	}
+
+	return newEAX(key, size, BlockSize)
+}
+
+// NewEAXWithTagSize is like NewEAX but emits tags truncated to tagSize bytes,
+// which must be between 4 and the block size.  A forgery succeeds with
+// probability 2^-(8*tagSize) per attempt, so a 4-byte tag is broken by about
+// four billion attempts; use short tags only where the receiver limits how
+// many forgeries it will try to open under one key.
+func NewEAXWithTagSize(key []byte, tagSize int) (cipher.AEAD, error) {
+
+	if tagSize < 4 || tagSize > BlockSize {
+		return nil, errTagSize
+	}
+
+	return newEAX(key, eaxDefaultNonceSize, tagSize)
+}
+
+func newEAX(key []byte, nonceSize, tagSize int) (cipher.AEAD, error) {
+
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eax{b: b, nonceSize: nonceSize, tagSize: tagSize, nonces: newNonceTracker(), maxPlaintext: -1, maxAD: -1}, nil
+}
+
+// NewEAXLimited is like NewEAX but only accepts plaintexts of up to
+// maxPlaintext bytes and additional data of up to maxAD bytes, for inputs
+// from untrusted sources.  The limits are checked before any other work:
+// Open returns an error for a longer ciphertext or additional data, and Seal,
+// which cannot return an error, panics.  Both limits must be non-negative.
+func NewEAXLimited(key []byte, maxPlaintext, maxAD int) (cipher.AEAD, error) {
+
+	if maxPlaintext < 0 || maxAD < 0 {
+		return nil, errLimit
+	}
+
+	a, err := newEAX(key, eaxDefaultNonceSize, BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	e := a.(*eax)
+	e.maxPlaintext, e.maxAD = maxPlaintext, maxAD
+
+	return e, nil
+}
+
+// withinLimits reports whether the plaintext and additional data lengths are
+// within the limits set by NewEAXLimited.
+func (e *eax) withinLimits(plaintextLen, adLen int) bool {
+	return (e.maxPlaintext < 0 || plaintextLen <= e.maxPlaintext) && (e.maxAD < 0 || adLen <= e.maxAD)
+}
+
+func (e *eax) NonceSize() int { return e.nonceSize }
+func (e *eax) Overhead() int  { return e.tagSize }
+
+// omac computes OMAC^t(data) = CMAC([t]_n || data) for the EAX tweak t.
+func (e *eax) omac(t byte, data ...[]byte) []byte {
+
+	m := newCMAC(e.b)
+
+	tweak := make([]byte, e.b.BlockSize())
+	tweak[len(tweak)-1] = t
+	m.Write(tweak)
+
+	for _, d := range data {
+		m.Write(d)
+	}
+
+	return m.Sum(nil)
+}
+
+func (e *eax) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+
+	if len(nonce) != e.nonceSize {
+		panic("rc5: incorrect nonce length given to EAX")
+	}
+
+	if !e.withinLimits(len(plaintext), len(additionalData)) {
+		panic(errEAXLimit.Error())
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+e.tagSize)
+	if inexactOverlap(out, plaintext) {
+		panic("rc5: invalid buffer overlap")
+	}
+
+	e.nonces.check(nonce, plaintext, additionalData)
+
+	n := e.omac(0, nonce)
+	h := e.omac(1, additionalData)
+
+	cipher.NewCTR(e.b, n).XORKeyStream(out, plaintext)
+
+	tag := e.omac(2, out[:len(plaintext)])
+	subtle.XORBytes(tag, tag, n)
+	subtle.XORBytes(tag, tag, h)
+
+	copy(out[len(plaintext):], tag)
+
+	return ret
+}
+
+func (e *eax) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+
+	if len(nonce) != e.nonceSize {
+		panic("rc5: incorrect nonce length given to EAX")
+	}
+
+	if len(ciphertext) < e.tagSize {
+		return nil, errOpen
+	}
+
+	if !e.withinLimits(len(ciphertext)-e.tagSize, len(additionalData)) {
+		return nil, errEAXLimit
+	}
+
+	tag := ciphertext[len(ciphertext)-e.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-e.tagSize]
+
+	n := e.omac(0, nonce)
+	h := e.omac(1, additionalData)
+
+	expected := e.omac(2, ciphertext)
+	subtle.XORBytes(expected, expected, n)
+	subtle.XORBytes(expected, expected, h)
+
+	// verify before decrypting so no plaintext is released on failure
+	if subtle.ConstantTimeCompare(expected[:e.tagSize], tag) != 1 {
+		return nil, errOpen
+	}
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	if inexactOverlap(out, ciphertext) {
+		panic("rc5: invalid buffer overlap")
+	}
+
+	cipher.NewCTR(e.b, n).XORKeyStream(out, ciphertext)
+
+	return ret, nil
+}
+
+// sliceForAppend takes a slice and a requested number of bytes.  It returns a
+// slice with the contents of the given slice followed by that many bytes and a
+// second slice that aliases into it and contains only the extra bytes.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
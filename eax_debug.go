@@ -0,0 +1,46 @@
+//go:build rc5debug
+
+package rc5
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+)
+
+// In builds with the rc5debug tag, every Seal by an EAX AEAD is recorded by
+// its nonce, and sealing a different message under the same nonce with the
+// same AEAD panics.  Resealing the same message, as a retry, is allowed since
+// it reveals nothing new.  The record lives as long as the AEAD and is never
+// pruned, so debug builds are for development and testing only.  Separate
+// AEADs, even with the same key, are tracked separately, so deliberate reuse
+// across them, as in known-answer tests, is not reported.
+
+type nonceTracker struct {
+	mu *sync.Mutex
+	m  map[string][sha256.Size]byte // nonce to hash of the message sealed
+}
+
+func newNonceTracker() nonceTracker {
+	return nonceTracker{mu: new(sync.Mutex), m: make(map[string][sha256.Size]byte)}
+}
+
+func (t nonceTracker) check(nonce, plaintext, additionalData []byte) {
+
+	h := sha256.New()
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(additionalData))))
+	h.Write(additionalData)
+	h.Write(plaintext)
+
+	var msg [sha256.Size]byte
+	h.Sum(msg[:0])
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if prev, ok := t.m[string(nonce)]; ok && prev != msg {
+		panic("rc5: EAX nonce reused with a different message")
+	}
+
+	t.m[string(nonce)] = msg
+}
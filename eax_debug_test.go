@@ -0,0 +1,41 @@
+//go:build rc5debug
+
+package rc5
+
+import "testing"
+
+func TestEAXNonceReuse(t *testing.T) {
+
+	key := seq(0x20, 16)
+	nonce := seq(0x40, 8)
+
+	aead, _ := NewEAX(key)
+	first := aead.Seal(nil, nonce, []byte("first"), nil)
+
+	// resealing the same message is a harmless retry
+	if got := aead.Seal(nil, nonce, []byte("first"), nil); string(got) != string(first) {
+		t.Errorf("resealed message differs")
+	}
+
+	// a fresh nonce, or the same nonce with another AEAD, is fine
+	aead.Seal(nil, seq(0x41, 8), []byte("second"), nil)
+	other, _ := NewEAX(key)
+	other.Seal(nil, nonce, []byte("second"), nil)
+
+	for _, tst := range []struct {
+		plaintext, ad string
+	}{
+		{"second", ""},
+		{"first", "header"},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Seal(%q, ad %q) reusing a nonce did not panic", tst.plaintext, tst.ad)
+				}
+			}()
+
+			aead.Seal(nil, nonce, []byte(tst.plaintext), []byte(tst.ad))
+		}()
+	}
+}
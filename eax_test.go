@@ -0,0 +1,248 @@
+package rc5
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// seq returns n bytes counting up from start
+func seq(start byte, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = start + byte(i)
+	}
+	return b
+}
+
+func TestEAX(t *testing.T) {
+
+	key := seq(0, 16)
+
+	// computed with a reference EAX built on the reference RC5 code
+	for _, tst := range []struct {
+		nonceLen, plainLen, adLen int
+		out                       string
+	}{
+		{8, 0, 0, "17920dcf789fe821"},
+		{8, 5, 0, "3850a648b1196db006c96d0968"},
+		{8, 16, 3, "3850a648b10ad8c973c665b86531aac86ed193ddc35c0fe7"},
+		{12, 20, 11, "4f4d98214c1eb6b2b4f7a369f7ef89098fcbc509ce47eba1ee8a4f57"},
+		{16, 33, 20, "40045c1b04487f98cdb4fd671946335df43cfb7d92453986267181312086ebb402f3fb829460ef83d4"},
+	} {
+		aead, err := NewEAXWithNonceSize(key, tst.nonceLen)
+		if err != nil {
+			t.Fatalf("NewEAXWithNonceSize(%d) failed: %v", tst.nonceLen, err)
+		}
+
+		nonce := seq(0x40, tst.nonceLen)
+		plain := seq(0x80, tst.plainLen)
+		ad := seq(0xC0, tst.adLen)
+		want, _ := hex.DecodeString(tst.out)
+
+		ct := aead.Seal(nil, nonce, plain, ad)
+		if !bytes.Equal(ct, want) {
+			t.Errorf("EAX Seal(%d, %d, %d):\ngot : % 02x\nwant: % 02x", tst.nonceLen, tst.plainLen, tst.adLen, ct, want)
+		}
+
+		p, err := aead.Open(nil, nonce, ct, ad)
+		if err != nil {
+			t.Errorf("EAX Open(%d, %d, %d) failed: %v", tst.nonceLen, tst.plainLen, tst.adLen, err)
+		}
+		if !bytes.Equal(p, plain) {
+			t.Errorf("EAX Open(%d, %d, %d):\ngot : % 02x\nwant: % 02x", tst.nonceLen, tst.plainLen, tst.adLen, p, plain)
+		}
+
+		for i := range ct {
+			bad := append([]byte(nil), ct...)
+			bad[i] ^= 0x01
+			if p, err := aead.Open(nil, nonce, bad, ad); err != errOpen || p != nil {
+				t.Errorf("EAX Open with byte %d flipped: got (% 02x, %v), want (nil, %v)", i, p, err, errOpen)
+			}
+		}
+
+		if tst.adLen > 0 {
+			if _, err := aead.Open(nil, nonce, ct, ad[1:]); err != errOpen {
+				t.Errorf("EAX Open with modified additional data: got %v, want %v", err, errOpen)
+			}
+		}
+	}
+}
+
+func TestEAXShortMessages(t *testing.T) {
+
+	nonce := seq(0x40, 8)
+
+	// messages and additional data shorter than a block, computed with the
+	// same reference EAX as TestEAX
+	for _, tst := range []struct {
+		plainLen, adLen int
+		out             string
+	}{
+		{0, 0, "17920dcf789fe821"},
+		{0, 1, "5bc87047d525f2ba"},
+		{0, 7, "20b2b17c8853025c"},
+		{1, 0, "3853b0a6e5b9b6af91"},
+		{1, 1, "381feadb6d140cb50a"},
+		{1, 7, "3864901a56497a45ec"},
+		{7, 0, "3850a648b10ad8c81d3b1f64df8e85"},
+		{7, 1, "3850a648b10ad884474697c965941e"},
+		{7, 7, "3850a648b10ad8ff3d87ac941364f8"},
+	} {
+		// the vectors share a nonce, so each needs its own AEAD in rc5debug
+		// builds
+		aead, _ := NewEAX(seq(0, 16))
+		plain := seq(0x80, tst.plainLen)
+		ad := seq(0xC0, tst.adLen)
+		want, _ := hex.DecodeString(tst.out)
+
+		ct := aead.Seal(nil, nonce, plain, ad)
+		if !bytes.Equal(ct, want) {
+			t.Errorf("EAX Seal(%d, %d):\ngot : % 02x\nwant: % 02x", tst.plainLen, tst.adLen, ct, want)
+		}
+
+		// in place, in a buffer with room for the tag
+		buf := make([]byte, tst.plainLen, tst.plainLen+aead.Overhead())
+		copy(buf, plain)
+		if got := aead.Seal(buf[:0], nonce, buf, ad); !bytes.Equal(got, want) {
+			t.Errorf("EAX in-place Seal(%d, %d):\ngot : % 02x\nwant: % 02x", tst.plainLen, tst.adLen, got, want)
+		}
+
+		p, err := aead.Open(nil, nonce, ct, ad)
+		if err != nil || !bytes.Equal(p, plain) {
+			t.Errorf("EAX Open(%d, %d): got (% 02x, %v), want % 02x", tst.plainLen, tst.adLen, p, err, plain)
+		}
+
+		if _, err := aead.Open(nil, nonce, ct[:len(ct)-1], ad); err != errOpen {
+			t.Errorf("EAX Open(%d, %d) of a truncated message: got %v, want %v", tst.plainLen, tst.adLen, err, errOpen)
+		}
+	}
+}
+
+func TestEAXLimited(t *testing.T) {
+
+	key := seq(0, 16)
+
+	aead, err := NewEAXLimited(key, 16, 4)
+	if err != nil {
+		t.Fatalf("NewEAXLimited failed: %v", err)
+	}
+
+	// within the limits, the output is plain EAX
+	full, _ := NewEAX(key)
+	for i, tst := range []struct{ plainLen, adLen int }{{0, 0}, {16, 0}, {0, 4}, {16, 4}} {
+		nonce := seq(0x40+byte(i), 8)
+		plain, ad := seq(0x80, tst.plainLen), seq(0xC0, tst.adLen)
+
+		ct := aead.Seal(nil, nonce, plain, ad)
+		if want := full.Seal(nil, nonce, plain, ad); !bytes.Equal(ct, want) {
+			t.Errorf("limited Seal(%d, %d):\ngot : % 02x\nwant: % 02x", tst.plainLen, tst.adLen, ct, want)
+		}
+
+		if p, err := aead.Open(nil, nonce, ct, ad); err != nil || !bytes.Equal(p, plain) {
+			t.Errorf("limited Open(%d, %d): got (% 02x, %v), want % 02x", tst.plainLen, tst.adLen, p, err, plain)
+		}
+	}
+
+	for i, tst := range []struct{ plainLen, adLen int }{{17, 0}, {0, 5}, {100, 100}} {
+		nonce := seq(0x50+byte(i), 8)
+		plain, ad := seq(0x80, tst.plainLen), seq(0xC0, tst.adLen)
+
+		ct := full.Seal(nil, nonce, plain, ad)
+		if _, err := aead.Open(nil, nonce, ct, ad); err != errEAXLimit {
+			t.Errorf("limited Open(%d, %d): got %v, want %v", tst.plainLen, tst.adLen, err, errEAXLimit)
+		}
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("limited Seal(%d, %d) did not panic", tst.plainLen, tst.adLen)
+				}
+			}()
+			aead.Seal(nil, nonce, plain, ad)
+		}()
+	}
+
+	if _, err := NewEAXLimited(key, -1, 0); err != errLimit {
+		t.Errorf("NewEAXLimited(-1, 0): got %v, want %v", err, errLimit)
+	}
+
+	if _, err := NewEAXLimited(key[:5], 16, 4); err == nil {
+		t.Errorf("NewEAXLimited accepted a 5-byte key")
+	}
+}
+
+func TestEAXDefaults(t *testing.T) {
+
+	aead, err := NewEAX(seq(0, 16))
+	if err != nil {
+		t.Fatalf("NewEAX failed: %v", err)
+	}
+
+	if aead.NonceSize() != 8 || aead.Overhead() != 8 {
+		t.Errorf("NewEAX: NonceSize()=%d Overhead()=%d, want 8 and 8", aead.NonceSize(), aead.Overhead())
+	}
+
+	// Seal appends to dst
+	prefix := []byte("prefix")
+	out := aead.Seal(prefix, seq(0, 8), []byte("hello"), nil)
+	if !bytes.HasPrefix(out, prefix) || len(out) != len(prefix)+5+8 {
+		t.Errorf("EAX Seal did not append to dst: % 02x", out)
+	}
+
+	if _, err := NewEAXWithNonceSize(seq(0, 16), 0); err != errNonceSize {
+		t.Errorf("NewEAXWithNonceSize(0): got %v, want %v", err, errNonceSize)
+	}
+}
+
+func TestEAXTagSize(t *testing.T) {
+
+	key := seq(0, 16)
+	nonce := seq(0x40, 8)
+	plain := seq(0x80, 16)
+	ad := seq(0xC0, 3)
+
+	full, _ := NewEAX(key)
+	want := full.Seal(nil, nonce, plain, ad)
+
+	for _, size := range []int{4, 6, 8} {
+
+		aead, err := NewEAXWithTagSize(key, size)
+		if err != nil {
+			t.Fatalf("NewEAXWithTagSize(%d) failed: %v", size, err)
+		}
+
+		if aead.Overhead() != size {
+			t.Errorf("NewEAXWithTagSize(%d).Overhead()=%d", size, aead.Overhead())
+		}
+
+		// the tag is a prefix of the full EAX tag
+		ct := aead.Seal(nil, nonce, plain, ad)
+		if w := want[:len(plain)+size]; !bytes.Equal(ct, w) {
+			t.Errorf("Seal with %d byte tag:\ngot : % 02x\nwant: % 02x", size, ct, w)
+		}
+
+		p, err := aead.Open(nil, nonce, ct, ad)
+		if err != nil || !bytes.Equal(p, plain) {
+			t.Errorf("Open with %d byte tag: got (% 02x, %v), want % 02x", size, p, err, plain)
+		}
+
+		for i := range ct {
+			bad := bytes.Clone(ct)
+			bad[i] ^= 0x01
+			if _, err := aead.Open(nil, nonce, bad, ad); err != errOpen {
+				t.Errorf("Open with %d byte tag and byte %d flipped: got %v, want %v", size, i, err, errOpen)
+			}
+		}
+
+		if _, err := aead.Open(nil, nonce, ct[:len(ct)-1], ad); err != errOpen {
+			t.Errorf("Open with %d byte tag truncated: got %v, want %v", size, err, errOpen)
+		}
+	}
+
+	for _, size := range []int{0, 3, 9, 16} {
+		if _, err := NewEAXWithTagSize(key, size); err != errTagSize {
+			t.Errorf("NewEAXWithTagSize(%d): got %v, want %v", size, err, errTagSize)
+		}
+	}
+}
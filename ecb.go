@@ -0,0 +1,48 @@
+package rc5
+
+// EncryptECB encrypts data with RC5-32/12/16 in electronic codebook mode,
+// returning a new slice.  The length of data must be a multiple of the block
+// size.
+//
+// WARNING: ECB encrypts identical plaintext blocks to identical ciphertext
+// blocks and so leaks the structure of the data.  It is provided only for
+// reading and writing legacy formats and must not be used in new designs; use
+// an authenticated mode such as NewEAX instead.
+func EncryptECB(key, data []byte) ([]byte, error) {
+	return cryptECB(key, data, false)
+}
+
+// DecryptECB decrypts data with RC5-32/12/16 in electronic codebook mode,
+// returning a new slice.  The length of data must be a multiple of the block
+// size.
+//
+// WARNING: ECB is insecure; see EncryptECB.
+func DecryptECB(key, data []byte) ([]byte, error) {
+	return cryptECB(key, data, true)
+}
+
+func cryptECB(key, data []byte, decrypt bool) ([]byte, error) {
+
+	block, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	bs := block.BlockSize()
+
+	if len(data)%bs != 0 {
+		return nil, errInputSize
+	}
+
+	dst := make([]byte, len(data))
+
+	for i := 0; i < len(data); i += bs {
+		if decrypt {
+			block.Decrypt(dst[i:i+bs], data[i:i+bs])
+		} else {
+			block.Encrypt(dst[i:i+bs], data[i:i+bs])
+		}
+	}
+
+	return dst, nil
+}
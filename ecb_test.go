@@ -0,0 +1,50 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestECB(t *testing.T) {
+
+	key := tests[0].key
+	block, _ := New(key)
+
+	for _, l := range []int{0, 8, 16, 64, 1000} {
+
+		src := make([]byte, l)
+		rand.Read(src)
+
+		ct, err := EncryptECB(key, src)
+		if err != nil {
+			t.Fatalf("EncryptECB (len=%d) failed: %v", l, err)
+		}
+
+		want := make([]byte, 8)
+		for i := 0; i < l; i += 8 {
+			block.Encrypt(want, src[i:i+8])
+			if !bytes.Equal(ct[i:i+8], want) {
+				t.Errorf("EncryptECB (len=%d) block %d:\ngot : % 02x\nwant: % 02x", l, i/8, ct[i:i+8], want)
+			}
+		}
+
+		pt, err := DecryptECB(key, ct)
+		if err != nil {
+			t.Fatalf("DecryptECB (len=%d) failed: %v", l, err)
+		}
+
+		if !bytes.Equal(pt, src) {
+			t.Errorf("ECB round trip (len=%d):\ngot : % 02x\nwant: % 02x", l, pt, src)
+		}
+	}
+
+	for _, l := range []int{1, 7, 9, 15} {
+		if _, err := EncryptECB(key, make([]byte, l)); err != errInputSize {
+			t.Errorf("EncryptECB (len=%d): got %v, want %v", l, err, errInputSize)
+		}
+		if _, err := DecryptECB(key, make([]byte, l)); err != errInputSize {
+			t.Errorf("DecryptECB (len=%d): got %v, want %v", l, err, errInputSize)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package rc5
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+// SealEtM encrypts plaintext with RC5-32/12/16 in RC5-CBC-Pad mode under
+// encKey with a random IV, then authenticates the IV and ciphertext with
+// HMAC-SHA256 under macKey.  It returns iv||ciphertext||tag, with an 8-byte
+// IV and a 32-byte tag.  The two keys must be independent.  WithRand selects
+// the source of the IV.
+func SealEtM(encKey, macKey, plaintext []byte, opts ...RandOption) ([]byte, error) {
+
+	iv := make([]byte, 8)
+	if err := readRandom(iv, opts); err != nil {
+		return nil, err
+	}
+
+	ct, err := EncryptCBCPad(encKey, iv, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	m := hmac.New(sha256.New, macKey)
+	m.Write(iv)
+	m.Write(ct)
+
+	out := append(iv, ct...)
+	return m.Sum(out), nil
+}
+
+// OpenEtM verifies the HMAC-SHA256 tag of a message produced by SealEtM and
+// only then decrypts it and removes the padding.
+func OpenEtM(encKey, macKey, blob []byte) ([]byte, error) {
+
+	if len(blob) < 8+8+sha256.Size || !ValidCBCPadLength(len(blob)-8-sha256.Size) {
+		return nil, errSealedLength
+	}
+
+	body, tag := blob[:len(blob)-sha256.Size], blob[len(blob)-sha256.Size:]
+
+	m := hmac.New(sha256.New, macKey)
+	m.Write(body)
+
+	if !hmac.Equal(m.Sum(nil), tag) {
+		return nil, errOpen
+	}
+
+	return DecryptCBCPad(encKey, body[:8], body[8:])
+}
+
+// DecryptCBCPadVerified checks the HMAC-SHA256 tag under macKey of iv and the
+// RC5-CBC-Pad ciphertext, which ciphertextWithTag holds followed by the 32-byte
+// tag, and only then decrypts under encKey and removes the padding.  The input
+// laid out as iv||ciphertextWithTag is the output of SealEtM.
+//
+// A short input, a bad tag and bad padding all return the same error, so a
+// caller cannot become a padding oracle.  This is the recommended way to
+// decrypt RC5-CBC-Pad; DecryptCBCPad on its own must never be exposed to
+// attacker-chosen ciphertext.
+func DecryptCBCPadVerified(encKey, macKey, iv, ciphertextWithTag []byte) ([]byte, error) {
+
+	if len(iv) != 8 {
+		return nil, errIVSize
+	}
+
+	if len(ciphertextWithTag) < sha256.Size || !ValidCBCPadLength(len(ciphertextWithTag)-sha256.Size) {
+		return nil, errOpen
+	}
+
+	n := len(ciphertextWithTag) - sha256.Size
+	ct, tag := ciphertextWithTag[:n], ciphertextWithTag[n:]
+
+	m := hmac.New(sha256.New, macKey)
+	m.Write(iv)
+	m.Write(ct)
+
+	if !hmac.Equal(m.Sum(nil), tag) {
+		return nil, errOpen
+	}
+
+	p, err := DecryptCBCPad(encKey, iv, ct)
+	if err == errPadding {
+		return nil, errOpen
+	}
+
+	return p, err
+}
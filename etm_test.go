@@ -0,0 +1,114 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestEtM(t *testing.T) {
+
+	encKey, macKey := tests[0].key, seq(0x40, 32)
+
+	for _, l := range []int{0, 1, 8, 100} {
+
+		msg := seq(0x80, l)
+
+		blob, err := SealEtM(encKey, macKey, msg)
+		if err != nil {
+			t.Fatalf("SealEtM failed: %v", err)
+		}
+
+		if want := 8 + (l/8+1)*8 + 32; len(blob) != want {
+			t.Errorf("SealEtM (len=%d): got %d bytes, want %d", l, len(blob), want)
+		}
+
+		pt, err := OpenEtM(encKey, macKey, blob)
+		if err != nil {
+			t.Fatalf("OpenEtM (len=%d) failed: %v", l, err)
+		}
+
+		if !bytes.Equal(pt, msg) {
+			t.Errorf("EtM round trip:\ngot : % 02x\nwant: % 02x", pt, msg)
+		}
+
+		for i := range blob {
+			blob[i] ^= 0x01
+			if _, err := OpenEtM(encKey, macKey, blob); err != errOpen {
+				t.Errorf("OpenEtM (len=%d) with byte %d flipped: got %v, want %v", l, i, err, errOpen)
+			}
+			blob[i] ^= 0x01
+		}
+
+		if _, err := OpenEtM(encKey, seq(0x41, 32), blob); err != errOpen {
+			t.Errorf("OpenEtM with wrong MAC key: got %v, want %v", err, errOpen)
+		}
+	}
+
+	for _, l := range []int{0, 47, 49, 55} {
+		if _, err := OpenEtM(encKey, macKey, make([]byte, l)); err != errSealedLength {
+			t.Errorf("OpenEtM of %d bytes: got %v, want %v", l, err, errSealedLength)
+		}
+	}
+}
+
+func TestDecryptCBCPadVerified(t *testing.T) {
+
+	encKey, macKey := tests[0].key, seq(0x40, 32)
+
+	msg := seq(0x80, 20)
+
+	blob, _ := SealEtM(encKey, macKey, msg)
+	iv, body := blob[:8], blob[8:]
+
+	pt, err := DecryptCBCPadVerified(encKey, macKey, iv, body)
+	if err != nil {
+		t.Fatalf("DecryptCBCPadVerified failed: %v", err)
+	}
+	if !bytes.Equal(pt, msg) {
+		t.Errorf("DecryptCBCPadVerified:\ngot : % 02x\nwant: % 02x", pt, msg)
+	}
+
+	// a correctly tagged ciphertext whose padding is malformed
+	block, _ := New(encKey)
+	badPad := make([]byte, 16)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(badPad, append(seq(0, 15), 0))
+	m := hmac.New(sha256.New, macKey)
+	m.Write(iv)
+	m.Write(badPad)
+	badPad = m.Sum(badPad)
+
+	badTag := bytes.Clone(body)
+	badTag[len(badTag)-1] ^= 1
+
+	badCT := bytes.Clone(body)
+	badCT[0] ^= 1
+
+	var errs []error
+	for _, tst := range []struct {
+		name string
+		iv   []byte
+		body []byte
+	}{
+		{"bad padding", iv, badPad},
+		{"bad tag", iv, badTag},
+		{"bad ciphertext", iv, badCT},
+		{"wrong iv", seq(1, 8), body},
+		{"truncated", iv, body[:len(body)-1]},
+		{"tag only", iv, body[len(body)-sha256.Size:]},
+	} {
+		_, err := DecryptCBCPadVerified(encKey, macKey, tst.iv, tst.body)
+		if err == nil {
+			t.Errorf("DecryptCBCPadVerified(%s) succeeded", tst.name)
+		}
+		errs = append(errs, err)
+	}
+
+	for i, err := range errs {
+		if err != errs[0] || err.Error() != errs[0].Error() {
+			t.Errorf("failure %d returned %v, failure 0 returned %v", i, err, errs[0])
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package rc5
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+func ExampleCipher() {
+
+	key := []byte("example key 1234")
+
+	block, err := New(key)
+	if err != nil {
+		panic(err)
+	}
+
+	// New returns a cipher.Block; assert to *Cipher for the extra methods
+	c := block.(*Cipher)
+
+	fmt.Println(c, c.Rounds())
+
+	buf := make([]byte, 8*4)
+	c.EncryptBlocks(buf, buf)
+	c.DecryptBlocks(buf, buf)
+
+	c.Wipe()
+
+	// Output: RC5-32/12/16 12
+}
+
+func ExampleEncryptCBCPad() {
+
+	key := []byte("example key 1234")
+
+	// The IV must be unpredictable and never reused with a key, so real code
+	// reads it from crypto/rand.  A fixed IV keeps this example's output
+	// stable.
+	iv := []byte("8byte iv")
+
+	ciphertext, err := EncryptCBCPad(key, iv, []byte("attack at dawn"))
+	if err != nil {
+		panic(err)
+	}
+
+	// the IV is not secret, so it is commonly sent in front of the ciphertext
+	message := append(iv, ciphertext...)
+	fmt.Println(hex.EncodeToString(message))
+
+	plaintext, err := DecryptCBCPad(key, message[:BlockSize], message[BlockSize:])
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s\n", plaintext)
+
+	// Output:
+	// 386279746520697645997e56eb10dbe15aa6d83aeb105791
+	// attack at dawn
+}
+
+func TestNewReturnsCipher(t *testing.T) {
+
+	for _, tst := range parameterTests {
+
+		b, err := NewWithParameters(tst.w, tst.r, tst.key)
+		if err != nil {
+			t.Fatalf("NewWithParameters failed: %v", err)
+		}
+
+		if _, ok := b.(*Cipher); !ok {
+			t.Errorf("NewWithParameters(%d, %d) returned %T, want *Cipher", tst.w, tst.r, b)
+		}
+	}
+
+	b, _ := New(tests[0].key)
+	if _, ok := b.(*Cipher); !ok {
+		t.Errorf("New returned %T, want *Cipher", b)
+	}
+}
@@ -0,0 +1,84 @@
+package rc5
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"sync"
+	"testing"
+)
+
+func TestFieldCipher(t *testing.T) {
+
+	master := seq(0, 32)
+	block := [8]byte{'4', '1', '1', '1', '1', '1', '1', '1'}
+
+	f, err := NewFieldCipher(master)
+	if err != nil {
+		t.Fatalf("NewFieldCipher failed: %v", err)
+	}
+
+	ssn := f.EncryptField("ssn", block)
+	card := f.EncryptField("card", block)
+
+	if ssn == card {
+		t.Errorf("labels ssn and card gave the same ciphertext % 02x", ssn)
+	}
+
+	if got := f.EncryptField("ssn", block); got != ssn {
+		t.Errorf("EncryptField not consistent:\ngot : % 02x\nwant: % 02x", got, ssn)
+	}
+
+	if got := f.DecryptField("card", card); got != block {
+		t.Errorf("DecryptField:\ngot : % 02x\nwant: % 02x", got, block)
+	}
+
+	// the subkey is HKDF of the master key and the label
+	key, _ := hkdf.Key(sha256.New, master, nil, "rc5 field ssn", 16)
+	b, _ := New(key)
+	var want [8]byte
+	b.Encrypt(want[:], block[:])
+	if ssn != want {
+		t.Errorf("EncryptField(ssn):\ngot : % 02x\nwant: % 02x", ssn, want)
+	}
+
+	// a second instance with the same master key derives the same subkeys
+	g, _ := NewFieldCipher(master)
+	if got := g.EncryptField("ssn", block); got != ssn {
+		t.Errorf("second FieldCipher:\ngot : % 02x\nwant: % 02x", got, ssn)
+	}
+
+	if len(f.ciphers) != 2 {
+		t.Errorf("FieldCipher cached %d subkeys, want 2", len(f.ciphers))
+	}
+
+	if _, err := NewFieldCipher(nil); err != errMasterKey {
+		t.Errorf("NewFieldCipher(nil): got %v, want %v", err, errMasterKey)
+	}
+}
+
+func TestFieldCipherConcurrent(t *testing.T) {
+
+	f, _ := NewFieldCipher(seq(0, 32))
+	labels := []string{"a", "b", "c", "d"}
+
+	want := make(map[string][8]byte)
+	for _, l := range labels {
+		want[l] = f.EncryptField(l, [8]byte{})
+	}
+
+	g, _ := NewFieldCipher(seq(0, 32))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, l := range labels {
+				if got := g.EncryptField(l, [8]byte{}); got != want[l] {
+					t.Errorf("EncryptField(%q):\ngot : % 02x\nwant: % 02x", l, got, want[l])
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
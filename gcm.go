@@ -0,0 +1,21 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"errors"
+)
+
+var errGCMBlockSize = errors.New("rc5: GCM requires a 128-bit block, use a 64-bit word size")
+
+// NewGCM returns block wrapped in Galois Counter Mode with the standard nonce
+// and tag sizes.  GCM is only defined for 128-bit blocks, so block must have a
+// block size of 16 bytes, such as RC5-64 from
+// NewWithParameters(64, rounds, key).
+func NewGCM(block cipher.Block) (cipher.AEAD, error) {
+
+	if block.BlockSize() != 16 {
+		return nil, errGCMBlockSize
+	}
+
+	return cipher.NewGCM(block)
+}
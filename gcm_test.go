@@ -0,0 +1,52 @@
+package rc5
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGCM(t *testing.T) {
+
+	block, _ := NewWithParameters(64, 24, tests[0].key)
+
+	aead, err := NewGCM(block)
+	if err != nil {
+		t.Fatalf("NewGCM failed: %v", err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	ad := []byte("associated data")
+
+	for _, l := range []int{0, 1, 16, 33} {
+
+		msg := seq(0, l)
+
+		ct := aead.Seal(nil, nonce, msg, ad)
+		if len(ct) != l+aead.Overhead() {
+			t.Errorf("Seal (len=%d): got %d bytes, want %d", l, len(ct), l+aead.Overhead())
+		}
+
+		pt, err := aead.Open(nil, nonce, ct, ad)
+		if err != nil {
+			t.Fatalf("Open (len=%d) failed: %v", l, err)
+		}
+
+		if !bytes.Equal(pt, msg) {
+			t.Errorf("GCM round trip (len=%d):\ngot : % 02x\nwant: % 02x", l, pt, msg)
+		}
+
+		if _, err := aead.Open(nil, nonce, ct, ad[1:]); err == nil {
+			t.Errorf("Open (len=%d) with modified associated data succeeded", l)
+		}
+
+		ct[0] ^= 1
+		if _, err := aead.Open(nil, nonce, ct, ad); err == nil {
+			t.Errorf("Open (len=%d) with modified ciphertext succeeded", l)
+		}
+	}
+
+	block, _ = New(tests[0].key)
+	if _, err := NewGCM(block); err != errGCMBlockSize {
+		t.Errorf("NewGCM with 64-bit block: got %v, want %v", err, errGCMBlockSize)
+	}
+}
@@ -0,0 +1,52 @@
+package rc5
+
+import (
+	"bytes"
+	"errors"
+)
+
+var errPrefix = errors.New("rc5: known plaintext prefix is empty")
+
+// GuessParameters returns every standard RC5 variant, among the word sizes
+// 16, 32 and 64 and round counts 0 to 255, with key length len(key), under
+// which the first block of ciphertext decrypts to something beginning with
+// knownPlaintextPrefix, or as much of it as fits in a block.  The first block
+// is decrypted directly, as in ECB mode or CBC with a zero IV.  Word sizes
+// whose block is longer than ciphertext are skipped.
+//
+// GuessParameters is a best-effort heuristic for forensics.  A short prefix
+// matches wrong variants by chance, about once in 2^(8*len) tries, and
+// non-standard variants, such as big-endian ones, are not tried.
+func GuessParameters(key, ciphertext, knownPlaintextPrefix []byte) ([]Parameters, error) {
+
+	if len(knownPlaintextPrefix) == 0 {
+		return nil, errPrefix
+	}
+
+	if l := len(key); l > 255 {
+		return nil, KeyLengthError{l, 0, 255}
+	}
+
+	var found []Parameters
+
+	for _, w := range []int{16, 32, 64} {
+
+		bs := BlockSizeFor(w)
+		if len(ciphertext) < bs {
+			continue
+		}
+
+		prefix := knownPlaintextPrefix[:min(len(knownPlaintextPrefix), bs)]
+		pt := make([]byte, bs)
+
+		for r := 0; r <= 255; r++ {
+			c, _ := newCipher(params{wordSize: w, rounds: r}, key)
+			c.Decrypt(pt, ciphertext)
+			if bytes.HasPrefix(pt, prefix) {
+				found = append(found, Parameters{w, r, len(key)})
+			}
+		}
+	}
+
+	return found, nil
+}
@@ -0,0 +1,42 @@
+package rc5
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGuessParameters(t *testing.T) {
+
+	plain := []byte("%PDF-1.7 document")
+
+	for _, p := range []Parameters{
+		{32, 12, 16},
+		{16, 16, 8},
+		{64, 24, 24},
+		{32, 20, 5},
+	} {
+		key := seq(1, p.KeyLength)
+		b, _ := p.New(key)
+
+		ct := make([]byte, 16)
+		b.Encrypt(ct, plain)
+
+		got, err := GuessParameters(key, ct, plain)
+		if err != nil {
+			t.Fatalf("GuessParameters(%v) failed: %v", p, err)
+		}
+
+		// a full-block prefix should match only the true variant
+		if !slices.Equal(got, []Parameters{p}) {
+			t.Errorf("GuessParameters(%v)=%v", p, got)
+		}
+	}
+
+	if got, _ := GuessParameters(seq(1, 16), seq(0, 16), plain); len(got) != 0 {
+		t.Errorf("GuessParameters of unrelated ciphertext: got %v, want none", got)
+	}
+
+	if _, err := GuessParameters(seq(1, 16), seq(0, 8), nil); err != errPrefix {
+		t.Errorf("GuessParameters with no prefix: got %v, want %v", err, errPrefix)
+	}
+}
@@ -0,0 +1,105 @@
+package rc5
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	hashSize      = BlockSize // the digest is one chaining value
+	hashBlockSize = 16        // each message block is an RC5-32/12/16 key
+)
+
+// hashIV is the initial chaining value.
+var hashIV = [hashSize]byte{'r', 'c', '5', ' ', 'h', 'a', 's', 'h'}
+
+type rc5Hash struct {
+	c   *Cipher
+	h   [hashSize]byte
+	buf [hashBlockSize]byte
+	n   int    // bytes pending in buf
+	len uint64 // total bytes written
+}
+
+// NewHash returns a hash.Hash computing an 8-byte digest built on
+// RC5-32/12/16 in the single-block-length Matyas–Meyer–Oseas family: each
+// 16-byte message block is used as the key to encrypt the chaining value, and
+// the result is XORed with the chaining value to give the next one.  The
+// message is padded Merkle–Damgård style with a 0x80 byte, zeros and its
+// big-endian 64-bit length in bits.
+//
+// A 64-bit digest gives collisions after about 2^32 messages, so NewHash is
+// only suitable for non-cryptographic integrity checks such as detecting
+// accidental corruption; use crypto/sha256 where an attacker may choose the
+// input.
+func NewHash() hash.Hash {
+	c, _ := newCipher(params{wordSize: 32, rounds: 12}, make([]byte, hashBlockSize))
+	d := &rc5Hash{c: c}
+	d.Reset()
+	return d
+}
+
+func (d *rc5Hash) Size() int      { return hashSize }
+func (d *rc5Hash) BlockSize() int { return hashBlockSize }
+
+func (d *rc5Hash) Reset() {
+	d.h = hashIV
+	d.n = 0
+	d.len = 0
+}
+
+// compress updates the chaining value h with one message block.
+func (d *rc5Hash) compress(h *[hashSize]byte, block []byte) {
+
+	d.c.Rekey(block)
+
+	var t [hashSize]byte
+	d.c.Encrypt(t[:], h[:])
+	subtle.XORBytes(h[:], t[:], h[:])
+}
+
+func (d *rc5Hash) Write(p []byte) (int, error) {
+
+	n := len(p)
+	d.len += uint64(n)
+
+	if d.n > 0 {
+		k := copy(d.buf[d.n:], p)
+		d.n += k
+		p = p[k:]
+		if d.n < hashBlockSize {
+			return n, nil
+		}
+		d.compress(&d.h, d.buf[:])
+		d.n = 0
+	}
+
+	for len(p) >= hashBlockSize {
+		d.compress(&d.h, p[:hashBlockSize])
+		p = p[hashBlockSize:]
+	}
+
+	d.n = copy(d.buf[:], p)
+
+	return n, nil
+}
+
+func (d *rc5Hash) Sum(in []byte) []byte {
+
+	// pad to a multiple of the block size, leaving 8 bytes for the length
+	tail := make([]byte, 0, 2*hashBlockSize)
+	tail = append(tail, d.buf[:d.n]...)
+	tail = append(tail, 0x80)
+	for len(tail)%hashBlockSize != hashBlockSize-8 {
+		tail = append(tail, 0)
+	}
+	tail = binary.BigEndian.AppendUint64(tail, d.len*8)
+
+	h := d.h
+	for ; len(tail) > 0; tail = tail[hashBlockSize:] {
+		d.compress(&h, tail[:hashBlockSize])
+	}
+
+	return append(in, h[:]...)
+}
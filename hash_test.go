@@ -0,0 +1,69 @@
+package rc5
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+
+	// computed with an independent implementation
+	for _, tst := range []struct {
+		msg  []byte
+		want string
+	}{
+		{nil, "21e75b8b76914c44"},
+		{[]byte("abc"), "93498f25d01eb1b0"},
+		{seq(0, 100), "7dea15f8aa3ebc85"},
+	} {
+		h := NewHash()
+		h.Write(tst.msg)
+		want, _ := hex.DecodeString(tst.want)
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("NewHash(% 02x):\ngot : % 02x\nwant: % 02x", tst.msg, got, want)
+		}
+	}
+
+	msg := seq(0, 100)
+	seen := make(map[string]int)
+
+	for l := 0; l <= len(msg); l++ {
+
+		h := NewHash()
+		h.Write(msg[:l])
+		want := h.Sum(nil)
+
+		if len(want) != h.Size() {
+			t.Errorf("Sum (len=%d): got %d bytes, want %d", l, len(want), h.Size())
+		}
+
+		if prev, ok := seen[string(want)]; ok {
+			t.Errorf("lengths %d and %d have the same digest % 02x", prev, l, want)
+		}
+		seen[string(want)] = l
+
+		for _, chunk := range []int{1, 3, 15, 16, 17} {
+			h.Reset()
+			for p := msg[:l]; len(p) > 0; {
+				k := min(chunk, len(p))
+				h.Write(p[:k])
+				p = p[k:]
+
+				// Sum must not disturb the running state
+				h.Sum(nil)
+			}
+
+			if got := h.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("chunked (len=%d, chunk=%d):\ngot : % 02x\nwant: % 02x", l, chunk, got, want)
+			}
+		}
+	}
+
+	a, b := NewHash(), NewHash()
+	a.Write([]byte("message 1"))
+	b.Write([]byte("message 2"))
+	if bytes.Equal(a.Sum(nil), b.Sum(nil)) {
+		t.Errorf("different messages have the same digest")
+	}
+}
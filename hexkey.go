@@ -0,0 +1,35 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// NewFromHex returns a cipher.Block implementing RC5-32/12/16 whose key is
+// given as a hex string, which must decode to 16 bytes.  A malformed string is
+// reported with the error from encoding/hex, and a key of the wrong length
+// with a KeyLengthError.
+func NewFromHex(hexKey string) (cipher.Block, error) {
+
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(key)
+}
+
+// NewFromBase64 returns a cipher.Block implementing RC5-32/12/16 whose key is
+// given in standard padded base64, which must decode to 16 bytes.  A malformed
+// string is reported with the error from encoding/base64, and a key of the
+// wrong length with a KeyLengthError.
+func NewFromBase64(b64Key string) (cipher.Block, error) {
+
+	key, err := base64.StdEncoding.DecodeString(b64Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(key)
+}
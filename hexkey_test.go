@@ -0,0 +1,62 @@
+package rc5
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestNewFromHex(t *testing.T) {
+
+	tst := tests[1]
+
+	c, err := NewFromHex(hex.EncodeToString(tst.key))
+	if err != nil {
+		t.Fatalf("NewFromHex failed: %v", err)
+	}
+
+	var ct [8]byte
+	c.Encrypt(ct[:], tst.plain)
+	if string(ct[:]) != string(tst.cipher) {
+		t.Errorf("NewFromHex:\ngot : % 02x\nwant: % 02x", ct[:], tst.cipher)
+	}
+
+	if _, err := NewFromHex("000102030405060708090a0b0c0d0e"); !errors.Is(err, KeySizeError(15)) {
+		t.Errorf("NewFromHex with 15 byte key: got %v, want KeySizeError(15)", err)
+	}
+
+	for _, s := range []string{"0g", "000102030405060708090a0b0c0d0e0", "zz"} {
+		_, err := NewFromHex(s)
+		if _, ok := err.(KeySizeError); ok || err == nil {
+			t.Errorf("NewFromHex(%q): got %v, want decode error", s, err)
+		}
+	}
+}
+
+func TestNewFromBase64(t *testing.T) {
+
+	tst := tests[1]
+
+	c, err := NewFromBase64(base64.StdEncoding.EncodeToString(tst.key))
+	if err != nil {
+		t.Fatalf("NewFromBase64 failed: %v", err)
+	}
+
+	var ct [8]byte
+	c.Encrypt(ct[:], tst.plain)
+	if string(ct[:]) != string(tst.cipher) {
+		t.Errorf("NewFromBase64:\ngot : % 02x\nwant: % 02x", ct[:], tst.cipher)
+	}
+
+	if _, err := NewFromBase64("AAECAwQFBgc="); !errors.Is(err, KeySizeError(8)) {
+		t.Errorf("NewFromBase64 with 8 byte key: got %v, want KeySizeError(8)", err)
+	}
+
+	for _, s := range []string{"AAECAwQFBgc", "!!!!", "AAECAwQFBgcICQoLDA0ODw=x"} {
+		_, err := NewFromBase64(s)
+		if _, ok := err.(KeySizeError); ok || err == nil {
+			t.Errorf("NewFromBase64(%q): got %v, want decode error", s, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package rc5
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// asmEnabled selects the assembly implementation of RC5-32/12, where there is
+// one.  It is set on first use to whichever of the assembly and Go
+// implementations is faster on this CPU, and may be overridden with
+// SetImplementation.
+var asmEnabled atomic.Bool
+
+// asmChosen guards the timing comparison, so programs that never encrypt with
+// RC5-32/12 don't pay for it at startup.
+var asmChosen sync.Once
+
+// useAsm reports whether RC5-32/12 should use the assembly implementation.
+func useAsm() bool {
+
+	if !haveAsm {
+		return false
+	}
+
+	asmChosen.Do(func() { asmEnabled.Store(asmFaster()) })
+
+	return asmEnabled.Load()
+}
+
+var (
+	errImplementation = errors.New("rc5: unknown implementation")
+	errNoAsm          = errors.New("rc5: no assembly implementation on this platform")
+)
+
+// SetImplementation selects the implementation of little-endian RC5-32/12
+// used by all ciphers: "asm" for the assembly implementation, available on
+// amd64 unless built with the purego tag, "go" for the pure Go one, or "auto"
+// to repeat the brief comparison made on first use, which picks the faster of
+// the two on the current CPU.  Every implementation gives the same output.
+// SetImplementation is safe to call at any time, but is intended for
+// benchmarking and for overriding the automatic choice early in a program.
+func SetImplementation(name string) error {
+
+	switch name {
+	case "asm":
+		if !haveAsm {
+			return errNoAsm
+		}
+		asmChosen.Do(func() {})
+		asmEnabled.Store(true)
+	case "go":
+		asmChosen.Do(func() {})
+		asmEnabled.Store(false)
+	case "auto":
+		asmChosen.Do(func() {})
+		asmEnabled.Store(haveAsm && asmFaster())
+	default:
+		return errImplementation
+	}
+
+	return nil
+}
+
+// Implementation returns the name of the implementation of RC5-32/12 in use,
+// "asm" or "go".
+func Implementation() string {
+	if useAsm() {
+		return "asm"
+	}
+	return "go"
+}
+
+// asmFaster reports whether the assembly implementation encrypted a small
+// buffer faster than the Go implementation, taking the best of a few trials of
+// each to reduce noise.
+func asmFaster() bool {
+
+	c := &Cipher{w: 32, rounds: 12, rk32: make([]uint32, defaultRoundKeys)}
+	buf := make([]byte, 256*BlockSize)
+
+	best := func(f func(dst, src []byte)) time.Duration {
+		d := time.Duration(1<<63 - 1)
+		for trial := 0; trial < 5; trial++ {
+			start := time.Now()
+			for i := 0; i < len(buf); i += BlockSize {
+				f(buf[i:i+BlockSize], buf[i:i+BlockSize])
+			}
+			d = min(d, time.Since(start))
+		}
+		return d
+	}
+
+	asm := best(func(dst, src []byte) { encrypt12(&c.rk32[0], &dst[0], &src[0]) })
+	generic := best(c.encrypt32r12)
+
+	return asm <= generic
+}
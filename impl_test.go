@@ -0,0 +1,70 @@
+package rc5
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetImplementation(t *testing.T) {
+
+	defer SetImplementation(Implementation())
+
+	names := []string{"go"}
+	if haveAsm {
+		names = append(names, "asm")
+	} else if err := SetImplementation("asm"); err != errNoAsm {
+		t.Errorf("SetImplementation(asm) without assembly: got %v, want %v", err, errNoAsm)
+	}
+
+	for _, name := range names {
+
+		if err := SetImplementation(name); err != nil {
+			t.Fatalf("SetImplementation(%s) failed: %v", name, err)
+		}
+
+		if got := Implementation(); got != name {
+			t.Errorf("after SetImplementation(%s), Implementation()=%s", name, got)
+		}
+
+		for _, tst := range tests {
+
+			b, _ := New(tst.key)
+			c := b.(*Cipher)
+
+			var ct [8]byte
+			c.Encrypt(ct[:], tst.plain)
+			if !bytes.Equal(ct[:], tst.cipher) {
+				t.Errorf("%s Encrypt:\ngot : % 02x\nwant: % 02x", name, ct[:], tst.cipher)
+			}
+
+			var pt [8]byte
+			c.Decrypt(pt[:], ct[:])
+			if !bytes.Equal(pt[:], tst.plain) {
+				t.Errorf("%s Decrypt:\ngot : % 02x\nwant: % 02x", name, pt[:], tst.plain)
+			}
+
+			blocks := bytes.Repeat(tst.plain, 4)
+			c.EncryptBlocks(blocks, blocks)
+			if !bytes.Equal(blocks, bytes.Repeat(tst.cipher, 4)) {
+				t.Errorf("%s EncryptBlocks:\ngot : % 02x\nwant: % 02x", name, blocks, bytes.Repeat(tst.cipher, 4))
+			}
+
+			var arr [8]byte
+			copy(arr[:], tst.plain)
+			if got := c.EncryptBlock(arr); !bytes.Equal(got[:], tst.cipher) {
+				t.Errorf("%s EncryptBlock:\ngot : % 02x\nwant: % 02x", name, got[:], tst.cipher)
+			}
+		}
+	}
+
+	if err := SetImplementation("auto"); err != nil {
+		t.Errorf("SetImplementation(auto) failed: %v", err)
+	}
+	if got := Implementation(); got != "go" && (got != "asm" || !haveAsm) {
+		t.Errorf("after SetImplementation(auto), Implementation()=%s", got)
+	}
+
+	if err := SetImplementation("avx512"); err != errImplementation {
+		t.Errorf("SetImplementation(avx512): got %v, want %v", err, errImplementation)
+	}
+}
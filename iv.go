@@ -0,0 +1,69 @@
+package rc5
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+)
+
+// ivDomain separates the IVs made by DeriveIV from encryptions of other
+// 8-byte values under the same key.
+var ivDomain = [BlockSize]byte{'r', 'c', '5', ' ', 'i', 'v', 0, 0}
+
+// DeriveIV returns a block-sized IV for message number counter, so CBC IVs
+// need not be stored alongside each message.  The counter is whitened with
+// the encryption of a fixed domain separator and then encrypted with
+// RC5-32/12/16 under key.  Encryption is a permutation, so distinct counters
+// always give distinct IVs, and without the key the IVs are unpredictable.
+// A counter must never be reused with the same key.
+func DeriveIV(key []byte, counter uint64) ([]byte, error) {
+
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var t [BlockSize]byte
+	b.Encrypt(t[:], ivDomain[:])
+
+	iv := make([]byte, BlockSize)
+	binary.BigEndian.PutUint64(iv, counter)
+	subtle.XORBytes(iv, iv, t[:])
+	b.Encrypt(iv, iv)
+
+	return iv, nil
+}
+
+// IsLikelyRandomIV reports whether iv looks like it could have come from a
+// random source.  It returns false for an empty IV, an IV whose bytes are all
+// the same, including all zeros, and an IV whose bytes count steadily up or
+// down, such as 00 01 02 ... or 08 07 06 ..., and true otherwise.
+//
+// IsLikelyRandomIV is a lint-style aid for catching mistakes such as an
+// uninitialised or hard-coded IV in tests and development builds.  It is not a
+// security control: many predictable IVs pass, and a true result says nothing
+// about whether an IV is unpredictable.
+func IsLikelyRandomIV(iv []byte) bool {
+
+	if len(iv) == 0 {
+		return false
+	}
+
+	if len(iv) == 1 {
+		return true
+	}
+
+	// a constant or arithmetic sequence has one difference throughout
+	step := iv[1] - iv[0]
+	for i := 2; i < len(iv); i++ {
+		if iv[i]-iv[i-1] != step {
+			return true
+		}
+	}
+
+	switch step {
+	case 0, 1, 0xFF:
+		return false
+	}
+
+	return true
+}
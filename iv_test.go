@@ -0,0 +1,87 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestDeriveIV(t *testing.T) {
+
+	key := seq(0, 16)
+
+	seen := make(map[string]uint64)
+	for _, counter := range []uint64{0, 1, 2, 3, 255, 256, 1 << 32, 1<<64 - 1} {
+
+		iv, err := DeriveIV(key, counter)
+		if err != nil {
+			t.Fatalf("DeriveIV(%d) failed: %v", counter, err)
+		}
+
+		if len(iv) != BlockSize {
+			t.Errorf("DeriveIV(%d) returned %d bytes, want %d", counter, len(iv), BlockSize)
+		}
+
+		if prev, ok := seen[string(iv)]; ok {
+			t.Errorf("DeriveIV(%d) = DeriveIV(%d) = % 02x", counter, prev, iv)
+		}
+		seen[string(iv)] = counter
+
+		again, _ := DeriveIV(key, counter)
+		if !bytes.Equal(iv, again) {
+			t.Errorf("DeriveIV(%d) not reproducible:\ngot : % 02x\nwant: % 02x", counter, again, iv)
+		}
+	}
+
+	// a different key gives different IVs
+	a, _ := DeriveIV(key, 7)
+	b, _ := DeriveIV(seq(1, 16), 7)
+	if bytes.Equal(a, b) {
+		t.Errorf("DeriveIV gave the same IV under two keys: % 02x", a)
+	}
+
+	if _, err := DeriveIV(seq(0, 8), 0); !errors.Is(err, KeySizeError(8)) {
+		t.Errorf("DeriveIV with an 8-byte key: got %v, want KeySizeError(8)", err)
+	}
+}
+
+func TestIsLikelyRandomIV(t *testing.T) {
+
+	for _, tst := range []struct {
+		iv   []byte
+		want bool
+	}{
+		{nil, false},
+		{make([]byte, 8), false},
+		{bytes.Repeat([]byte{0xAA}, 8), false},
+		{seq(0, 8), false},
+		{seq(0xFC, 8), false},
+		{[]byte{8, 7, 6, 5, 4, 3, 2, 1}, false},
+		{[]byte{0x01, 0x00, 0xFF, 0xFE, 0xFD, 0xFC, 0xFB, 0xFA}, false},
+		{[]byte{0x7F, 0x3A, 0xC4, 0x19, 0xE2, 0x58, 0x0B, 0x96}, true},
+		{[]byte{0, 0, 0, 0, 0, 0, 0, 1}, true},
+		{[]byte{0, 2, 4, 6, 8, 10, 12, 14}, true},
+	} {
+		if got := IsLikelyRandomIV(tst.iv); got != tst.want {
+			t.Errorf("IsLikelyRandomIV(% 02x)=%v, want %v", tst.iv, got, tst.want)
+		}
+	}
+
+	// random IVs pass, except with negligible probability
+	iv := make([]byte, 8)
+	for i := 0; i < 100; i++ {
+		rand.Read(iv)
+		if !IsLikelyRandomIV(iv) {
+			t.Errorf("IsLikelyRandomIV(% 02x)=false for a random IV", iv)
+		}
+	}
+
+	// counter-derived IVs look random
+	for counter := uint64(0); counter < 10; counter++ {
+		iv, _ := DeriveIV(seq(0, 16), counter)
+		if !IsLikelyRandomIV(iv) {
+			t.Errorf("IsLikelyRandomIV(DeriveIV(%d))=false", counter)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package rc5
+
+import "math"
+
+// A Mode identifies a block cipher mode of operation for MaxPlaintext.
+type Mode int
+
+// The modes understood by MaxPlaintext.
+const (
+	ModeCTR Mode = iota
+	ModeCBC
+	ModeOFB
+	ModeCFB
+)
+
+// MaxPlaintext returns the largest number of bytes that should be processed
+// under one key and IV with RC5-32 in the given mode, or 0 for an unknown
+// mode.
+//
+// For ModeCTR this is the length of the keystream before the 64-bit counter
+// block wraps, which exceeds math.MaxInt64 and so is capped there.  For the
+// chaining and feedback modes it is the birthday bound of 2^32 blocks, after
+// which repeated cipher inputs become likely and leak plaintext.  Note that
+// counter mode is also distinguishable from random well before the counter
+// wraps, so callers wanting a margin should rekey at the same bound.
+func MaxPlaintext(mode Mode) int64 {
+
+	const bs = 8
+
+	switch mode {
+	case ModeCTR:
+		// bs * 2^(8*bs) does not fit in an int64
+		return math.MaxInt64
+	case ModeCBC, ModeOFB, ModeCFB:
+		return bs << (8 * bs / 2)
+	}
+
+	return 0
+}
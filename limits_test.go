@@ -0,0 +1,31 @@
+package rc5
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestMaxPlaintext(t *testing.T) {
+
+	const bs = 8
+
+	// bs * 2^(8*bs), capped to an int64
+	wrap := new(big.Int).Lsh(big.NewInt(bs), 8*bs)
+	if wrap.IsInt64() {
+		t.Fatalf("counter space %v unexpectedly fits in an int64", wrap)
+	}
+	if got := MaxPlaintext(ModeCTR); got != math.MaxInt64 {
+		t.Errorf("MaxPlaintext(ModeCTR)=%d, want %d", got, int64(math.MaxInt64))
+	}
+
+	for _, mode := range []Mode{ModeCBC, ModeOFB, ModeCFB} {
+		if got, want := MaxPlaintext(mode), int64(bs)<<32; got != want {
+			t.Errorf("MaxPlaintext(%d)=%d, want %d", mode, got, want)
+		}
+	}
+
+	if got := MaxPlaintext(Mode(-1)); got != 0 {
+		t.Errorf("MaxPlaintext(unknown)=%d, want 0", got)
+	}
+}
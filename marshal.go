@@ -0,0 +1,162 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+)
+
+// marshalVersion is the first byte of the MarshalBinary encoding.  Version 1
+// has no flags byte and always uses little-endian words, and neither version
+// 1 nor 2 records the key length.
+const marshalVersion = 3
+
+// flags stored in the marshaled cipher
+const (
+	flagBigEndian = 1 << iota
+
+	// flagKeyLenUnknown marks a cipher restored from a version 1 or 2
+	// encoding, whose key length byte is then zero.  It is only valid in
+	// version 3.
+	flagKeyLenUnknown
+)
+
+var (
+	errMarshalVersion = errors.New("rc5: unknown marshaled cipher version")
+	errMarshalLength  = errors.New("rc5: invalid marshaled cipher length")
+	errMarshalFlags   = errors.New("rc5: unknown marshaled cipher flags")
+	errWiped          = errors.New("rc5: cipher has been wiped")
+)
+
+// MarshalBinary encodes the expanded key schedule as a version byte, the word
+// size, the round count, a flags byte and the key length, followed by the
+// little-endian round keys.  A cipher whose key length is unknown, because it
+// was restored from an older encoding, stays unknown.
+func (c *Cipher) MarshalBinary() ([]byte, error) {
+
+	if c.wiped {
+		return nil, errWiped
+	}
+
+	u := c.w / 8
+	roundKeys := 2 * (c.rounds + 1)
+
+	b := make([]byte, 5, 5+u*roundKeys)
+	b[0] = marshalVersion
+	b[1] = byte(c.w)
+	b[2] = byte(c.rounds)
+	if c.bigEndian {
+		b[3] |= flagBigEndian
+	}
+	if c.keyLen < 0 {
+		b[3] |= flagKeyLenUnknown
+	} else {
+		b[4] = byte(c.keyLen)
+	}
+
+	switch c.w {
+	case 16:
+		for _, k := range c.rk16 {
+			b = binary.LittleEndian.AppendUint16(b, k)
+		}
+	case 32:
+		for _, k := range c.rk32 {
+			b = binary.LittleEndian.AppendUint32(b, k)
+		}
+	case 64:
+		for _, k := range c.rk64 {
+			b = binary.LittleEndian.AppendUint64(b, k)
+		}
+	}
+
+	return b, nil
+}
+
+// UnmarshalBinary replaces the cipher's key schedule with one produced by
+// MarshalBinary.  Custom magic constants and extra key expansion passes are not
+// recorded by MarshalBinary, so a later Rekey uses the standard key expansion.
+func (c *Cipher) UnmarshalBinary(data []byte) error {
+
+	if len(data) < 3 {
+		return errMarshalLength
+	}
+
+	version, w, rounds := data[0], int(data[1]), int(data[2])
+	data = data[3:]
+
+	var flags byte
+	keyLen := -1
+
+	switch version {
+	case 1:
+	case 2, 3:
+		if len(data) < 1 {
+			return errMarshalLength
+		}
+		flags, data = data[0], data[1:]
+		known := byte(flagBigEndian)
+		if version == 3 {
+			known |= flagKeyLenUnknown
+		}
+		if flags&^known != 0 {
+			return errMarshalFlags
+		}
+		if version == 3 {
+			if len(data) < 1 {
+				return errMarshalLength
+			}
+			if flags&flagKeyLenUnknown == 0 {
+				keyLen = int(data[0])
+			}
+			data = data[1:]
+		}
+	default:
+		return errMarshalVersion
+	}
+
+	switch w {
+	case 16, 32, 64:
+	default:
+		return ParameterError{"word size", w}
+	}
+
+	u := w / 8
+	roundKeys := 2 * (rounds + 1)
+
+	if len(data) != u*roundKeys {
+		return errMarshalLength
+	}
+
+	*c = Cipher{w: w, rounds: rounds, keyLen: keyLen, passes: keyPasses, bigEndian: flags&flagBigEndian != 0}
+	c.pw, c.qw = magicConstants(w)
+
+	switch w {
+	case 16:
+		c.rk16 = make([]uint16, roundKeys)
+		for i := range c.rk16 {
+			c.rk16[i] = binary.LittleEndian.Uint16(data[u*i:])
+		}
+	case 32:
+		c.rk32 = make([]uint32, roundKeys)
+		for i := range c.rk32 {
+			c.rk32[i] = binary.LittleEndian.Uint32(data[u*i:])
+		}
+	case 64:
+		c.rk64 = make([]uint64, roundKeys)
+		for i := range c.rk64 {
+			c.rk64[i] = binary.LittleEndian.Uint64(data[u*i:])
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalCipher returns a cipher.Block using the key schedule encoded by a
+// previous call to MarshalBinary, without repeating the key expansion.
+func UnmarshalCipher(data []byte) (cipher.Block, error) {
+	c := &Cipher{}
+	if err := c.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,150 @@
+package rc5
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+
+	for _, tst := range parameterTests {
+
+		c, _ := NewWithParameters(tst.w, tst.r, tst.key)
+
+		data, err := c.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary failed: %v", err)
+		}
+
+		u, err := UnmarshalCipher(data)
+		if err != nil {
+			t.Fatalf("UnmarshalCipher failed: %v", err)
+		}
+
+		if u.(*Cipher).String() != c.(*Cipher).String() {
+			t.Errorf("unmarshaled String()=%q, want %q", u.(*Cipher).String(), c.(*Cipher).String())
+		}
+
+		ct := make([]byte, u.BlockSize())
+		u.Encrypt(ct, tst.plain)
+
+		if !bytes.Equal(ct, tst.cipher) {
+			t.Errorf("RC5-%d/%d/%d unmarshaled encrypt failed:\ngot : % 02x\nwant: % 02x", tst.w, tst.r, len(tst.key), ct, tst.cipher)
+		}
+	}
+}
+
+func TestUnmarshalBinaryInvalid(t *testing.T) {
+
+	c, _ := New(tests[0].key)
+	data, _ := c.(encoding.BinaryMarshaler).MarshalBinary()
+
+	bad := append([]byte(nil), data...)
+	bad[0] = 4
+	if _, err := UnmarshalCipher(bad); err != errMarshalVersion {
+		t.Errorf("UnmarshalCipher with unknown version: got %v, want %v", err, errMarshalVersion)
+	}
+
+	for _, l := range []int{0, 2, 3, 4, 5, len(data) - 1} {
+		if _, err := UnmarshalCipher(data[:l]); err != errMarshalLength {
+			t.Errorf("UnmarshalCipher with %d bytes: got %v, want %v", l, err, errMarshalLength)
+		}
+	}
+
+	if _, err := UnmarshalCipher(append(data, 0)); err != errMarshalLength {
+		t.Errorf("UnmarshalCipher with trailing data: got %v, want %v", err, errMarshalLength)
+	}
+
+	bad = append([]byte(nil), data...)
+	bad[1] = 24
+	if _, err := UnmarshalCipher(bad); err != (ParameterError{"word size", 24}) {
+		t.Errorf("UnmarshalCipher with invalid word size: got %v", err)
+	}
+
+	bad = append([]byte(nil), data...)
+	bad[3] = 0x80
+	if _, err := UnmarshalCipher(bad); err != errMarshalFlags {
+		t.Errorf("UnmarshalCipher with unknown flags: got %v, want %v", err, errMarshalFlags)
+	}
+
+	c.(Wiper).Wipe()
+	if _, err := c.(encoding.BinaryMarshaler).MarshalBinary(); err != errWiped {
+		t.Errorf("MarshalBinary after Wipe: got %v, want %v", err, errWiped)
+	}
+}
+
+func TestUnmarshalBinaryVersions(t *testing.T) {
+
+	c, _ := New(tests[0].key)
+	data, _ := c.(encoding.BinaryMarshaler).MarshalBinary()
+
+	// version 1 is the same encoding without the flags and key length bytes
+	v1 := append([]byte{1, data[1], data[2]}, data[5:]...)
+
+	u, err := UnmarshalCipher(v1)
+	if err != nil {
+		t.Fatalf("UnmarshalCipher(version 1) failed: %v", err)
+	}
+
+	var ct [8]byte
+	u.Encrypt(ct[:], tests[0].plain)
+	if !bytes.Equal(ct[:], tests[0].cipher) {
+		t.Errorf("version 1 unmarshaled encrypt failed:\ngot : % 02x\nwant: % 02x", ct[:], tests[0].cipher)
+	}
+
+	if s := u.(*Cipher).String(); s != "RC5-32/12/?" {
+		t.Errorf("version 1 unmarshaled String()=%q, want %q", s, "RC5-32/12/?")
+	}
+
+	// version 2 adds the flags byte; re-marshaling a cipher restored from
+	// it must keep the key length unknown
+	v2 := append([]byte{2, data[1], data[2], data[3]}, data[5:]...)
+	u, err = UnmarshalCipher(v2)
+	if err != nil {
+		t.Fatalf("UnmarshalCipher(version 2) failed: %v", err)
+	}
+
+	again, err := u.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary of version 2 cipher failed: %v", err)
+	}
+
+	r, err := UnmarshalCipher(again)
+	if err != nil {
+		t.Fatalf("UnmarshalCipher(re-marshaled version 2) failed: %v", err)
+	}
+	if k := r.(*Cipher).keyLen; k != -1 {
+		t.Errorf("re-marshaled version 2 keyLen=%d, want -1", k)
+	}
+	if s := r.(*Cipher).String(); s != "RC5-32/12/?" {
+		t.Errorf("re-marshaled version 2 String()=%q, want %q", s, "RC5-32/12/?")
+	}
+	r.Encrypt(ct[:], tests[0].plain)
+	if !bytes.Equal(ct[:], tests[0].cipher) {
+		t.Errorf("re-marshaled version 2 encrypt failed:\ngot : % 02x\nwant: % 02x", ct[:], tests[0].cipher)
+	}
+
+	// the unknown key length flag is not valid before version 3
+	bad := append([]byte(nil), v2...)
+	bad[3] |= flagKeyLenUnknown
+	if _, err := UnmarshalCipher(bad); err != errMarshalFlags {
+		t.Errorf("UnmarshalCipher(version 2 with key length flag): got %v, want %v", err, errMarshalFlags)
+	}
+
+	be, _ := NewWithByteOrder(binary.BigEndian, tests[0].key)
+	data, _ = be.(encoding.BinaryMarshaler).MarshalBinary()
+
+	u, err = UnmarshalCipher(data)
+	if err != nil {
+		t.Fatalf("UnmarshalCipher(big-endian) failed: %v", err)
+	}
+
+	var want [8]byte
+	be.Encrypt(want[:], tests[0].plain)
+	u.Encrypt(ct[:], tests[0].plain)
+	if ct != want {
+		t.Errorf("big-endian unmarshaled encrypt failed:\ngot : % 02x\nwant: % 02x", ct[:], want[:])
+	}
+}
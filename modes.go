@@ -0,0 +1,78 @@
+package rc5
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A ModeFactory constructs one mode of operation over RC5-32/12/16, as looked
+// up by ModeByName.  The constructors return a cipher.BlockMode for CBC, a
+// cipher.Stream for CTR, OFB and CFB, and a cipher.AEAD for EAX, whose iv must
+// be empty since the nonce is passed to Seal and Open instead.  For CTR, OFB
+// and EAX the two constructors are the same.
+type ModeFactory struct {
+	NewEncrypter func(key, iv []byte) (any, error)
+	NewDecrypter func(key, iv []byte) (any, error)
+}
+
+// A ModeError is returned by ModeByName for a mode name it does not know.
+type ModeError string
+
+func (e ModeError) Error() string { return "rc5: unknown mode " + strconv.Quote(string(e)) }
+
+// ModeByName returns the constructors for the mode with the given name: one of
+// "CBC", "CTR", "OFB", "CFB" or "EAX", in any case, so the mode can be chosen
+// by configuration.
+func ModeByName(name string) (ModeFactory, error) {
+
+	switch strings.ToUpper(name) {
+	case "CBC":
+		return ModeFactory{
+			NewEncrypter: modeConstructor(NewCBCEncrypter),
+			NewDecrypter: modeConstructor(NewCBCDecrypter),
+		}, nil
+	case "CTR":
+		return symmetricMode(modeConstructor(NewCTR)), nil
+	case "OFB":
+		return symmetricMode(modeConstructor(NewOFB)), nil
+	case "CFB":
+		return ModeFactory{
+			NewEncrypter: modeConstructor(NewCFBEncrypter),
+			NewDecrypter: modeConstructor(NewCFBDecrypter),
+		}, nil
+	case "EAX":
+		return symmetricMode(newEAXMode), nil
+	}
+
+	return ModeFactory{}, ModeError(name)
+}
+
+// modeConstructor adapts a typed mode constructor to a ModeFactory's.  It
+// returns a nil interface, not a typed nil, on error.
+func modeConstructor[M any](f func(key, iv []byte) (M, error)) func(key, iv []byte) (any, error) {
+	return func(key, iv []byte) (any, error) {
+		m, err := f(key, iv)
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+}
+
+func symmetricMode(f func(key, iv []byte) (any, error)) ModeFactory {
+	return ModeFactory{NewEncrypter: f, NewDecrypter: f}
+}
+
+func newEAXMode(key, iv []byte) (any, error) {
+
+	if len(iv) != 0 {
+		return nil, errIVSize
+	}
+
+	aead, err := NewEAX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead, nil
+}
@@ -0,0 +1,90 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"testing"
+)
+
+func TestModeByName(t *testing.T) {
+
+	key := tests[0].key
+	iv := seq(0x40, 8)
+	plain := seq(0x80, 32)
+
+	for _, name := range []string{"CBC", "CTR", "OFB", "CFB", "EAX", "cbc", "Ctr"} {
+
+		f, err := ModeByName(name)
+		if err != nil {
+			t.Fatalf("ModeByName(%q) failed: %v", name, err)
+		}
+
+		modeIV := iv
+		if name == "EAX" {
+			modeIV = nil
+		}
+
+		enc, err := f.NewEncrypter(key, modeIV)
+		if err != nil {
+			t.Fatalf("ModeByName(%q).NewEncrypter failed: %v", name, err)
+		}
+		dec, err := f.NewDecrypter(key, modeIV)
+		if err != nil {
+			t.Fatalf("ModeByName(%q).NewDecrypter failed: %v", name, err)
+		}
+
+		var got []byte
+		switch e := enc.(type) {
+		case cipher.BlockMode:
+			ct := make([]byte, len(plain))
+			e.CryptBlocks(ct, plain)
+			got = make([]byte, len(ct))
+			dec.(cipher.BlockMode).CryptBlocks(got, ct)
+		case cipher.Stream:
+			ct := make([]byte, len(plain))
+			e.XORKeyStream(ct, plain)
+			got = make([]byte, len(ct))
+			dec.(cipher.Stream).XORKeyStream(got, ct)
+		case cipher.AEAD:
+			nonce := make([]byte, e.NonceSize())
+			got, err = dec.(cipher.AEAD).Open(nil, nonce, e.Seal(nil, nonce, plain, nil), nil)
+			if err != nil {
+				t.Errorf("ModeByName(%q) Open failed: %v", name, err)
+			}
+		default:
+			t.Fatalf("ModeByName(%q) constructed a %T", name, enc)
+		}
+
+		if !bytes.Equal(got, plain) {
+			t.Errorf("ModeByName(%q) round trip:\ngot : % 02x\nwant: % 02x", name, got, plain)
+		}
+	}
+
+	// the constructors are the package's own
+	f, _ := ModeByName("CTR")
+	s, _ := f.NewEncrypter(key, iv)
+	got := make([]byte, len(plain))
+	s.(cipher.Stream).XORKeyStream(got, plain)
+	want := make([]byte, len(plain))
+	ctr, _ := NewCTR(key, iv)
+	ctr.XORKeyStream(want, plain)
+	if !bytes.Equal(got, want) {
+		t.Errorf("ModeByName(CTR) differs from NewCTR:\ngot : % 02x\nwant: % 02x", got, want)
+	}
+
+	// errors give a nil interface
+	if m, err := f.NewEncrypter(key, iv[:4]); m != nil || err != errIVSize {
+		t.Errorf("ModeByName(CTR) with a short IV: got (%v, %v), want (nil, %v)", m, err, errIVSize)
+	}
+
+	f, _ = ModeByName("EAX")
+	if _, err := f.NewEncrypter(key, iv); err != errIVSize {
+		t.Errorf("ModeByName(EAX) with an IV: got %v, want %v", err, errIVSize)
+	}
+
+	for _, name := range []string{"", "ECB", "GCM", "CBC "} {
+		if _, err := ModeByName(name); err != ModeError(name) {
+			t.Errorf("ModeByName(%q): got %v, want %v", name, err, ModeError(name))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package rc5
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// mteMAC returns the HMAC-SHA256 under macKey of the big-endian 64-bit length
+// of ad, ad and plaintext.
+func mteMAC(macKey, plaintext, ad []byte) []byte {
+
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(ad)))
+
+	m := hmac.New(sha256.New, macKey)
+	m.Write(n[:])
+	m.Write(ad)
+	m.Write(plaintext)
+
+	return m.Sum(nil)
+}
+
+// SealMtE authenticates plaintext and ad with HMAC-SHA256 under macKey,
+// appends the 32-byte tag to plaintext and encrypts the result with
+// RC5-32/12/16 in RC5-CBC-Pad mode under encKey and the 8-byte iv.  The tag
+// covers the big-endian 64-bit length of ad, ad and plaintext; ad itself is not
+// included in the output.
+//
+// MAC-then-encrypt is discouraged and is provided only to interoperate with
+// peers that already use it; new protocols should use SealEtM or Seal.
+func SealMtE(encKey, macKey, iv, plaintext, ad []byte) ([]byte, error) {
+
+	if len(iv) != 8 {
+		return nil, errIVSize
+	}
+
+	body := make([]byte, 0, len(plaintext)+sha256.Size)
+	body = append(body, plaintext...)
+	body = append(body, mteMAC(macKey, plaintext, ad)...)
+
+	return EncryptCBCPad(encKey, iv, body)
+}
+
+// OpenMtE decrypts a message produced by SealMtE, removes the padding and
+// only then verifies the tag over the recovered plaintext and ad.
+//
+// Because the padding is checked before the tag, a peer that can tell a
+// padding failure from a tag failure, by the error or by timing, is a padding
+// oracle that recovers plaintext from chosen ciphertexts.  OpenMtE returns the
+// same error for both, but cannot hide the timing difference; it must not be
+// exposed to attacker-chosen ciphertext where that matters.
+func OpenMtE(encKey, macKey, iv, ciphertext, ad []byte) ([]byte, error) {
+
+	if len(iv) != 8 {
+		return nil, errIVSize
+	}
+
+	body, err := DecryptCBCPad(encKey, iv, ciphertext)
+	if err == errPadding || err == errInputSize {
+		return nil, errOpen
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) < sha256.Size {
+		return nil, errOpen
+	}
+
+	n := len(body) - sha256.Size
+	plaintext, tag := body[:n], body[n:]
+
+	if !hmac.Equal(mteMAC(macKey, plaintext, ad), tag) {
+		return nil, errOpen
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,67 @@
+package rc5
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMtE(t *testing.T) {
+
+	encKey, macKey, iv := tests[0].key, seq(0x40, 32), seq(0x60, 8)
+	ad := []byte("header")
+
+	for _, l := range []int{0, 1, 8, 100} {
+
+		msg := seq(0x80, l)
+
+		ct, err := SealMtE(encKey, macKey, iv, msg, ad)
+		if err != nil {
+			t.Fatalf("SealMtE failed: %v", err)
+		}
+
+		if want := ((l+32)/8 + 1) * 8; len(ct) != want {
+			t.Errorf("SealMtE (len=%d): got %d bytes, want %d", l, len(ct), want)
+		}
+
+		pt, err := OpenMtE(encKey, macKey, iv, ct, ad)
+		if err != nil {
+			t.Fatalf("OpenMtE (len=%d) failed: %v", l, err)
+		}
+
+		if !bytes.Equal(pt, msg) {
+			t.Errorf("MtE round trip:\ngot : % 02x\nwant: % 02x", pt, msg)
+		}
+
+		for i := range ct {
+			ct[i] ^= 0x01
+			if _, err := OpenMtE(encKey, macKey, iv, ct, ad); err != errOpen {
+				t.Errorf("OpenMtE (len=%d) with byte %d flipped: got %v, want %v", l, i, err, errOpen)
+			}
+			ct[i] ^= 0x01
+		}
+
+		if _, err := OpenMtE(encKey, macKey, iv, ct, []byte("headex")); err != errOpen {
+			t.Errorf("OpenMtE with wrong ad: got %v, want %v", err, errOpen)
+		}
+
+		if _, err := OpenMtE(encKey, seq(0x41, 32), iv, ct, ad); err != errOpen {
+			t.Errorf("OpenMtE with wrong MAC key: got %v, want %v", err, errOpen)
+		}
+	}
+
+	// well padded, but too short to hold a tag
+	short, _ := EncryptCBCPad(encKey, iv, seq(0, 31))
+	if _, err := OpenMtE(encKey, macKey, iv, short, ad); err != errOpen {
+		t.Errorf("OpenMtE of a short message: got %v, want %v", err, errOpen)
+	}
+
+	for _, l := range []int{0, 7, 41} {
+		if _, err := OpenMtE(encKey, macKey, iv, make([]byte, l), ad); err != errOpen {
+			t.Errorf("OpenMtE of %d bytes: got %v, want %v", l, err, errOpen)
+		}
+	}
+
+	if _, err := SealMtE(encKey, macKey, iv[:7], nil, nil); err != errIVSize {
+		t.Errorf("SealMtE with a short IV: got %v, want %v", err, errIVSize)
+	}
+}
@@ -0,0 +1,58 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"errors"
+	"strconv"
+)
+
+// Parameters describes a variant of RC5: RC5-WordSize/Rounds/KeyLength, with
+// the word size in bits and the key length in bytes.
+type Parameters struct {
+	WordSize  int
+	Rounds    int
+	KeyLength int
+}
+
+func (p Parameters) String() string {
+	return "RC5-" + strconv.Itoa(p.WordSize) + "/" + strconv.Itoa(p.Rounds) + "/" + strconv.Itoa(p.KeyLength)
+}
+
+// supported reports whether NewWithParameters accepts p.
+func (p Parameters) supported() bool {
+	return checkParameters(p.WordSize, p.Rounds, nil) == nil && p.KeyLength >= 0 && p.KeyLength <= 255
+}
+
+// New returns a cipher.Block implementing the variant p.  The key must be
+// p.KeyLength bytes.
+func (p Parameters) New(key []byte) (cipher.Block, error) {
+	if l := len(key); l != p.KeyLength {
+		return nil, KeyLengthError{l, p.KeyLength, p.KeyLength}
+	}
+	return NewWithParameters(p.WordSize, p.Rounds, key)
+}
+
+var errNoCommonParameters = errors.New("rc5: no parameters offered by both sides")
+
+// NegotiateParameters returns the variant both sides of a handshake can use:
+// the first entry of localOffer, which is in order of preference, that
+// NewWithParameters supports and that also appears in remoteOffer.  The local
+// preference order decides, so the side calling NegotiateParameters should
+// be the one whose choice the protocol says wins, and both sides agree as long
+// as they call it with the same roles.  It returns an error if there is no
+// such variant.
+func NegotiateParameters(localOffer, remoteOffer []Parameters) (Parameters, error) {
+
+	remote := make(map[Parameters]bool, len(remoteOffer))
+	for _, p := range remoteOffer {
+		remote[p] = true
+	}
+
+	for _, p := range localOffer {
+		if remote[p] && p.supported() {
+			return p, nil
+		}
+	}
+
+	return Parameters{}, errNoCommonParameters
+}
@@ -0,0 +1,67 @@
+package rc5
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNegotiateParameters(t *testing.T) {
+
+	var (
+		std    = Parameters{32, 12, 16}
+		strong = Parameters{32, 20, 16}
+		wide   = Parameters{64, 24, 32}
+		small  = Parameters{16, 16, 8}
+		bad    = Parameters{128, 12, 16}
+	)
+
+	for _, tst := range []struct {
+		local, remote []Parameters
+		want          Parameters
+	}{
+		{[]Parameters{std}, []Parameters{std}, std},
+		{[]Parameters{wide, strong, std}, []Parameters{std, strong}, strong},
+		{[]Parameters{std, strong}, []Parameters{strong, std}, std},
+		{[]Parameters{small, wide}, []Parameters{wide, strong, small}, small},
+		{[]Parameters{bad, std}, []Parameters{bad, std}, std},
+	} {
+		got, err := NegotiateParameters(tst.local, tst.remote)
+		if err != nil {
+			t.Errorf("NegotiateParameters(%v, %v) failed: %v", tst.local, tst.remote, err)
+		} else if got != tst.want {
+			t.Errorf("NegotiateParameters(%v, %v)=%v, want %v", tst.local, tst.remote, got, tst.want)
+		}
+	}
+
+	for _, tst := range []struct {
+		local, remote []Parameters
+	}{
+		{nil, nil},
+		{[]Parameters{std}, nil},
+		{[]Parameters{std, strong}, []Parameters{wide, small}},
+		{[]Parameters{{32, 12, 24}}, []Parameters{std}},
+		{[]Parameters{bad}, []Parameters{bad}},
+	} {
+		if _, err := NegotiateParameters(tst.local, tst.remote); err != errNoCommonParameters {
+			t.Errorf("NegotiateParameters(%v, %v): got %v, want %v", tst.local, tst.remote, err, errNoCommonParameters)
+		}
+	}
+}
+
+func TestParametersNew(t *testing.T) {
+
+	p := Parameters{64, 24, 32}
+
+	b, err := p.New(seq(0, 32))
+	if err != nil {
+		t.Fatalf("%v.New failed: %v", p, err)
+	}
+
+	if c := b.(*Cipher); c.String() != p.String() {
+		t.Errorf("%v.New gave %v", p, c)
+	}
+
+	if _, err := p.New(seq(0, 16)); !errors.Is(err, KeySizeError(16)) {
+		t.Errorf("%v.New with a 16 byte key: got %v, want KeySizeError(16)", p, err)
+	}
+}
@@ -0,0 +1,46 @@
+package rc5
+
+import "strconv"
+
+// SecurityNotes returns human-readable advisories about the RC5 variant p,
+// for documenting a parameter choice.  They come from a fixed table of
+// published results and common recommendations covering the block size, the
+// number of rounds and the key length; this is not an analysis of the
+// variant.  A variant with no known concerns gives no notes.
+func SecurityNotes(p Parameters) []string {
+
+	if !p.supported() {
+		return []string{p.String() + " is not a valid RC5 variant"}
+	}
+
+	var notes []string
+	name := "RC5-" + strconv.Itoa(p.WordSize) + "/" + strconv.Itoa(p.Rounds)
+
+	switch p.WordSize {
+	case 16:
+		notes = append(notes, "the 32-bit block makes collisions likely after about 2^16 blocks (512 KiB) under one key; rekey far more often than that")
+	case 32:
+		notes = append(notes, "the 64-bit block makes collisions likely after about 2^32 blocks (32 GiB) under one key; rekey well before that")
+	}
+
+	// the round count at or above which no notes are given, by word size
+	minRounds := map[int]int{16: 16, 32: 16, 64: 20}[p.WordSize]
+
+	switch {
+	case p.Rounds == 0:
+		notes = append(notes, name+" has no rounds and provides no security")
+	case p.WordSize == 32 && p.Rounds == 12:
+		notes = append(notes, name+" has known differential attacks needing about 2^44 chosen plaintexts; consider ≥16 rounds")
+	case p.Rounds < minRounds:
+		notes = append(notes, name+" has fewer rounds than recommended against published attacks; consider ≥"+strconv.Itoa(minRounds)+" rounds")
+	}
+
+	switch {
+	case p.KeyLength == 0:
+		notes = append(notes, "an empty key gives every user the same key schedule")
+	case p.KeyLength < 16:
+		notes = append(notes, "key length "+strconv.Itoa(p.KeyLength)+" bytes ("+strconv.Itoa(8*p.KeyLength)+"-bit) is below modern recommendations of at least 16 bytes")
+	}
+
+	return notes
+}
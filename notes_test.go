@@ -0,0 +1,45 @@
+package rc5
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSecurityNotes(t *testing.T) {
+
+	for _, tst := range []struct {
+		p      Parameters
+		want   []string // substrings which must each appear in some note
+		absent []string // substrings which must not appear in any note
+	}{
+		{Parameters{32, 12, 16}, []string{"RC5-32/12 has known differential attacks", "64-bit block"}, []string{"key length"}},
+		{Parameters{32, 8, 16}, []string{"RC5-32/8 has fewer rounds", "≥16 rounds"}, nil},
+		{Parameters{32, 12, 8}, []string{"key length 8 bytes (64-bit) is below"}, nil},
+		{Parameters{32, 0, 0}, []string{"no rounds", "empty key"}, nil},
+		{Parameters{16, 12, 16}, []string{"32-bit block", "RC5-16/12 has fewer rounds"}, nil},
+		{Parameters{32, 20, 16}, []string{"64-bit block"}, []string{"rounds", "key length"}},
+		{Parameters{64, 16, 16}, []string{"≥20 rounds"}, []string{"block"}},
+		{Parameters{24, 12, 16}, []string{"not a valid RC5 variant"}, nil},
+	} {
+		notes := SecurityNotes(tst.p)
+		all := strings.Join(notes, "\n")
+
+		for _, w := range tst.want {
+			if !strings.Contains(all, w) {
+				t.Errorf("SecurityNotes(%v) does not mention %q:\n%s", tst.p, w, all)
+			}
+		}
+
+		for _, a := range tst.absent {
+			if strings.Contains(all, a) {
+				t.Errorf("SecurityNotes(%v) unexpectedly mentions %q:\n%s", tst.p, a, all)
+			}
+		}
+	}
+
+	for _, p := range []Parameters{{64, 24, 16}, {64, 32, 32}} {
+		if notes := SecurityNotes(p); len(notes) != 0 {
+			t.Errorf("SecurityNotes(%v): got %q, want no notes", p, notes)
+		}
+	}
+}
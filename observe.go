@@ -0,0 +1,60 @@
+package rc5
+
+import "crypto/cipher"
+
+// An Observer is notified of each encryption and decryption by a cipher
+// returned by NewObserved, for example to keep an audit log.  It is given only
+// the number of blocks processed, never the data or the key.  The methods are
+// called synchronously, after the blocks have been processed, and must be safe
+// for concurrent use if the cipher is.
+type Observer interface {
+	OnEncrypt(blocks int)
+	OnDecrypt(blocks int)
+}
+
+// observed wraps rather than embeds the cipher, so that only methods which
+// report to the Observer are exposed.
+type observed struct {
+	c   *Cipher
+	obs Observer
+}
+
+// NewObserved returns a cipher.Block implementing RC5-32/12/16 which reports
+// each call to Encrypt, Decrypt, EncryptBlocks and DecryptBlocks to obs.  The
+// key argument must be 16 bytes.  With a nil obs it returns the same cipher as
+// New, so an unobserved cipher costs nothing extra.
+func NewObserved(key []byte, obs Observer) (cipher.Block, error) {
+
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if obs == nil {
+		return b, nil
+	}
+
+	return &observed{c: b.(*Cipher), obs: obs}, nil
+}
+
+func (o *observed) BlockSize() int { return o.c.BlockSize() }
+
+func (o *observed) Encrypt(dst, src []byte) {
+	o.c.Encrypt(dst, src)
+	o.obs.OnEncrypt(1)
+}
+
+func (o *observed) Decrypt(dst, src []byte) {
+	o.c.Decrypt(dst, src)
+	o.obs.OnDecrypt(1)
+}
+
+func (o *observed) EncryptBlocks(dst, src []byte) {
+	o.c.EncryptBlocks(dst, src)
+	o.obs.OnEncrypt(len(src) / o.BlockSize())
+}
+
+func (o *observed) DecryptBlocks(dst, src []byte) {
+	o.c.DecryptBlocks(dst, src)
+	o.obs.OnDecrypt(len(src) / o.BlockSize())
+}
@@ -0,0 +1,76 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"testing"
+)
+
+type countingObserver struct {
+	encrypts, decrypts           int
+	encryptBlocks, decryptBlocks int
+}
+
+func (o *countingObserver) OnEncrypt(blocks int) {
+	o.encrypts++
+	o.encryptBlocks += blocks
+}
+
+func (o *countingObserver) OnDecrypt(blocks int) {
+	o.decrypts++
+	o.decryptBlocks += blocks
+}
+
+func TestNewObserved(t *testing.T) {
+
+	tst := tests[0]
+
+	var obs countingObserver
+	b, err := NewObserved(tst.key, &obs)
+	if err != nil {
+		t.Fatalf("NewObserved failed: %v", err)
+	}
+
+	var ct [8]byte
+	b.Encrypt(ct[:], tst.plain)
+	if !bytes.Equal(ct[:], tst.cipher) {
+		t.Errorf("NewObserved Encrypt:\ngot : % 02x\nwant: % 02x", ct[:], tst.cipher)
+	}
+	b.Decrypt(ct[:], ct[:])
+
+	o := b.(interface {
+		EncryptBlocks(dst, src []byte)
+		DecryptBlocks(dst, src []byte)
+	})
+	buf := make([]byte, 10*8)
+	o.EncryptBlocks(buf, buf)
+	o.DecryptBlocks(buf, buf[:3*8])
+
+	// modes built on the block see every block
+	cipher.NewCBCEncrypter(b, make([]byte, 8)).CryptBlocks(buf, buf)
+
+	want := countingObserver{encrypts: 12, decrypts: 2, encryptBlocks: 21, decryptBlocks: 4}
+	if obs != want {
+		t.Errorf("observer counts:\ngot : %+v\nwant: %+v", obs, want)
+	}
+
+	// methods of *Cipher which would bypass the observer are not exposed
+	if _, ok := b.(interface {
+		EncryptTokens(tokens [][8]byte) [][8]byte
+	}); ok {
+		t.Errorf("NewObserved exposes EncryptTokens")
+	}
+	if _, ok := b.(interface{ Clone() cipher.Block }); ok {
+		t.Errorf("NewObserved exposes Clone")
+	}
+
+	if b, _ := NewObserved(tst.key, nil); b == nil {
+		t.Errorf("NewObserved with a nil observer returned nil")
+	} else if _, ok := b.(*Cipher); !ok {
+		t.Errorf("NewObserved with a nil observer returned %T, want *Cipher", b)
+	}
+
+	if _, err := NewObserved(make([]byte, 8), &obs); err == nil {
+		t.Errorf("NewObserved with an 8-byte key succeeded")
+	}
+}
@@ -0,0 +1,79 @@
+package rc5
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+)
+
+// An Option configures a cipher created by New.
+type Option func(*params)
+
+// WithRounds selects the number of rounds, which must be between 0 and 255.
+// The default is 12.
+func WithRounds(rounds int) Option {
+	return func(p *params) {
+		p.rounds = rounds
+	}
+}
+
+// WithWordSize selects the word size in bits, which must be 16, 32 or 64.
+// The block size is two words.  The default is 32.
+func WithWordSize(wordSize int) Option {
+	return func(p *params) {
+		p.wordSize = wordSize
+	}
+}
+
+// WithByteOrder selects the byte order used to load and store words,
+// including the words of the key.  The default is binary.LittleEndian, as in
+// standard RC5.
+func WithByteOrder(order binary.ByteOrder) Option {
+	return func(p *params) {
+		bigEndian, err := isBigEndian(order)
+		if err != nil {
+			if p.err == nil {
+				p.err = err
+			}
+			return
+		}
+		p.bigEndian = bigEndian
+	}
+}
+
+// A RandOption configures a function which generates IVs or nonces.
+type RandOption func(*randOptions)
+
+type randOptions struct {
+	rand io.Reader
+}
+
+// WithRand selects the source of randomness used to generate nonces and IVs
+// by Seal, SealToString, SealWithHeader, SealEtM, EncryptBigInt,
+// EncryptFields, EncryptStream, EncryptStreamRekeyed and WrapConn, such as a
+// hardware RNG, or a deterministic reader in tests.  The default is
+// crypto/rand.Reader.  Those functions return an error if r fails or returns
+// too few bytes.
+func WithRand(r io.Reader) RandOption {
+	return func(o *randOptions) {
+		o.rand = r
+	}
+}
+
+// randReader returns the source of randomness selected by opts.
+func randReader(opts []RandOption) io.Reader {
+
+	o := randOptions{rand: rand.Reader}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o.rand
+}
+
+// readRandom fills b from the source selected by opts, returning an error if
+// it cannot be filled.
+func readRandom(b []byte, opts []RandOption) error {
+	_, err := io.ReadFull(randReader(opts), b)
+	return err
+}
@@ -0,0 +1,161 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"testing/iotest"
+)
+
+func TestOptions(t *testing.T) {
+
+	key := tests[0].key
+	src := make([]byte, 16)
+
+	for _, tst := range []struct {
+		opts  []Option
+		block func() (cipher.Block, error)
+	}{
+		{nil, func() (cipher.Block, error) {
+			return NewWithParameters(32, 12, key)
+		}},
+		{[]Option{WithRounds(20)}, func() (cipher.Block, error) {
+			return NewWithParameters(32, 20, key)
+		}},
+		{[]Option{WithWordSize(64), WithRounds(24)}, func() (cipher.Block, error) {
+			return NewWithParameters(64, 24, key)
+		}},
+		{[]Option{WithByteOrder(binary.BigEndian)}, func() (cipher.Block, error) {
+			return NewWithByteOrder(binary.BigEndian, key)
+		}},
+		// later options override earlier ones
+		{[]Option{WithWordSize(16), WithByteOrder(binary.BigEndian), WithByteOrder(binary.LittleEndian), WithWordSize(32)}, func() (cipher.Block, error) {
+			return New(key)
+		}},
+	} {
+		c, err := New(key, tst.opts...)
+		if err != nil {
+			t.Fatalf("New with %d options failed: %v", len(tst.opts), err)
+		}
+
+		want, _ := tst.block()
+
+		bs := c.BlockSize()
+		got, exp := make([]byte, bs), make([]byte, bs)
+		c.Encrypt(got, src[:bs])
+		want.Encrypt(exp, src[:bs])
+
+		if !bytes.Equal(got, exp) {
+			t.Errorf("New with %d options:\ngot : % 02x\nwant: % 02x", len(tst.opts), got, exp)
+		}
+	}
+
+	for _, tst := range []struct {
+		key  []byte
+		opts []Option
+		err  error
+	}{
+		{key, []Option{WithWordSize(24)}, ParameterError{"word size", 24}},
+		{key, []Option{WithRounds(256)}, ParameterError{"rounds", 256}},
+		{key, []Option{WithByteOrder(swappedOrder{})}, errByteOrder},
+		{key[:8], []Option{WithWordSize(64)}, KeySizeError(8)},
+		// the byte order error is reported before the others
+		{key[:8], []Option{WithWordSize(24), WithByteOrder(swappedOrder{})}, errByteOrder},
+	} {
+		if _, err := New(tst.key, tst.opts...); !errors.Is(err, tst.err) {
+			t.Errorf("New with %d options: got %v, want %v", len(tst.opts), err, tst.err)
+		}
+	}
+}
+
+func TestWithRand(t *testing.T) {
+
+	key, macKey := tests[0].key, seq(0x40, 32)
+	msg := []byte("hello")
+
+	a, err := Seal(key, msg, nil, WithRand(bytes.NewReader(seq(0x80, 8))))
+	if err != nil {
+		t.Fatalf("Seal with WithRand failed: %v", err)
+	}
+
+	b, _ := Seal(key, msg, nil, WithRand(bytes.NewReader(seq(0x80, 8))))
+	if !bytes.Equal(a, b) {
+		t.Errorf("Seal with the same deterministic reader:\ngot : % 02x\nwant: % 02x", b, a)
+	}
+
+	if !bytes.Equal(a[:8], seq(0x80, 8)) {
+		t.Errorf("Seal nonce:\ngot : % 02x\nwant: % 02x", a[:8], seq(0x80, 8))
+	}
+
+	if p, err := Open(key, a, nil); err != nil || !bytes.Equal(p, msg) {
+		t.Errorf("Open of Seal with WithRand: got (% 02x, %v), want % 02x", p, err, msg)
+	}
+
+	etm, _ := SealEtM(key, macKey, msg, WithRand(bytes.NewReader(seq(0x90, 8))))
+	if !bytes.Equal(etm[:8], seq(0x90, 8)) {
+		t.Errorf("SealEtM IV:\ngot : % 02x\nwant: % 02x", etm[:8], seq(0x90, 8))
+	}
+
+	h, _ := SealWithHeader(key, []byte("hdr"), msg, WithRand(bytes.NewReader(seq(0xA0, 8))))
+	if !bytes.Equal(h[7:15], seq(0xA0, 8)) {
+		t.Errorf("SealWithHeader nonce:\ngot : % 02x\nwant: % 02x", h[7:15], seq(0xA0, 8))
+	}
+
+	s, _ := SealToString(key, msg, nil, WithRand(bytes.NewReader(seq(0x80, 8))))
+	if p, err := OpenFromString(key, s, nil); err != nil || !bytes.Equal(p, msg) {
+		t.Errorf("SealToString with WithRand: got (% 02x, %v), want % 02x", p, err, msg)
+	}
+
+	v, _ := EncryptBigInt(key, big.NewInt(12345), WithRand(bytes.NewReader(seq(0xB0, 8))))
+	if !bytes.Equal(v[:8], seq(0xB0, 8)) {
+		t.Errorf("EncryptBigInt IV:\ngot : % 02x\nwant: % 02x", v[:8], seq(0xB0, 8))
+	}
+
+	f := struct {
+		Token []byte `rc5:"encrypt"`
+	}{[]byte("secret")}
+	if err := EncryptFields(key, &f, WithRand(bytes.NewReader(seq(0xC0, 8)))); err != nil || !bytes.Equal(f.Token[:8], seq(0xC0, 8)) {
+		t.Errorf("EncryptFields IV: got (% 02x, %v), want % 02x", f.Token, err, seq(0xC0, 8))
+	}
+
+	// the stream identifier and the first nonce
+	var stream bytes.Buffer
+	if err := EncryptStream(&stream, bytes.NewReader(msg), key, WithRand(bytes.NewReader(seq(0xD0, 16)))); err != nil {
+		t.Fatalf("EncryptStream with WithRand failed: %v", err)
+	}
+	if got := stream.Bytes(); !bytes.Equal(got[:8], seq(0xD0, 8)) || !bytes.Equal(got[13:21], seq(0xD8, 8)) {
+		t.Errorf("EncryptStream identifier and nonce: % 02x", got[:21])
+	}
+
+	stream.Reset()
+	if err := EncryptStreamRekeyed(&stream, bytes.NewReader(msg), key, 1, WithRand(bytes.NewReader(seq(0xD0, 12)))); err != io.ErrUnexpectedEOF {
+		t.Errorf("EncryptStreamRekeyed with a short reader: got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	ca, cb := net.Pipe()
+	defer ca.Close()
+	defer cb.Close()
+
+	wc, _ := WrapConn(ca, key, WithRand(bytes.NewReader(seq(0xE0, 8))))
+	go wc.Write(msg)
+
+	frame := make([]byte, 12)
+	if _, err := io.ReadFull(cb, frame); err != nil || !bytes.Equal(frame[4:], seq(0xE0, 8)) {
+		t.Errorf("WrapConn IV: got (% 02x, %v), want % 02x", frame[4:], err, seq(0xE0, 8))
+	}
+
+	// a short or failing source is an error
+	if _, err := Seal(key, msg, nil, WithRand(bytes.NewReader(seq(0, 5)))); err != io.ErrUnexpectedEOF {
+		t.Errorf("Seal with a short reader: got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	errRNG := errors.New("rng failure")
+	if _, err := SealEtM(key, macKey, msg, WithRand(iotest.ErrReader(errRNG))); err != errRNG {
+		t.Errorf("SealEtM with a failing reader: got %v, want %v", err, errRNG)
+	}
+}
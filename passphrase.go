@@ -0,0 +1,79 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"errors"
+	"strconv"
+)
+
+var (
+	errSalt       = errors.New("rc5: salt must not be empty")
+	errIterations = errors.New("rc5: iteration count must be positive")
+	errSubkeys    = errors.New("rc5: number of subkeys must be positive")
+	errMasterKey  = errors.New("rc5: master key must not be empty")
+)
+
+// DeriveKey derives a keyLen byte key from passphrase and salt using
+// PBKDF2-HMAC-SHA256 with the given number of iterations.
+func DeriveKey(passphrase string, salt []byte, iterations, keyLen int) ([]byte, error) {
+
+	if len(salt) == 0 {
+		return nil, errSalt
+	}
+
+	if iterations < 1 {
+		return nil, errIterations
+	}
+
+	if keyLen < 1 || keyLen > 255 {
+		return nil, KeyLengthError{keyLen, 1, 255}
+	}
+
+	return pbkdf2.Key(sha256.New, passphrase, salt, iterations, keyLen)
+}
+
+// NewFromPassphrase returns a cipher.Block implementing RC5-32/12/16 keyed
+// with DeriveKey(passphrase, salt, iterations, 16).  The salt should be random
+// and stored alongside the ciphertext.
+func NewFromPassphrase(passphrase string, salt []byte, iterations int) (cipher.Block, error) {
+
+	key, err := DeriveKey(passphrase, salt, iterations, 16)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(key)
+}
+
+// DeriveKeys derives n independent keyLen byte keys from a master secret with
+// HKDF-SHA256, using no salt and the info label "rc5 subkey i" for the i'th
+// key, counting from 0.  The master secret should already be uniformly
+// random; use DeriveKey for passphrases.
+func DeriveKeys(master []byte, n, keyLen int) ([][]byte, error) {
+
+	if len(master) == 0 {
+		return nil, errMasterKey
+	}
+
+	if n < 1 {
+		return nil, errSubkeys
+	}
+
+	if keyLen < 1 || keyLen > 255 {
+		return nil, KeyLengthError{keyLen, 1, 255}
+	}
+
+	keys := make([][]byte, n)
+	for i := range keys {
+		k, err := hkdf.Key(sha256.New, master, nil, "rc5 subkey "+strconv.Itoa(i), keyLen)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = k
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,102 @@
+package rc5
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestNewFromPassphrase(t *testing.T) {
+
+	passphrase, salt := "correct horse battery staple", []byte("go-rc5 salt")
+
+	key, err := DeriveKey(passphrase, salt, 1000, 16)
+	if err != nil {
+		t.Fatalf("DeriveKey failed: %v", err)
+	}
+
+	wantKey := []byte{0xd2, 0xd3, 0x19, 0x01, 0x9d, 0xb1, 0x5b, 0xbb, 0x75, 0x93, 0xdb, 0x53, 0x34, 0xa8, 0x2c, 0xc3}
+	if !bytes.Equal(key, wantKey) {
+		t.Errorf("DeriveKey:\ngot : % 02x\nwant: % 02x", key, wantKey)
+	}
+
+	c, err := NewFromPassphrase(passphrase, salt, 1000)
+	if err != nil {
+		t.Fatalf("NewFromPassphrase failed: %v", err)
+	}
+
+	var ct [8]byte
+	c.Encrypt(ct[:], make([]byte, 8))
+
+	want := []byte{0x58, 0xcd, 0x1c, 0x5b, 0x21, 0xcc, 0x62, 0x7a}
+	if !bytes.Equal(ct[:], want) {
+		t.Errorf("NewFromPassphrase:\ngot : % 02x\nwant: % 02x", ct[:], want)
+	}
+
+	if key, _ := DeriveKey(passphrase, salt, 1000, 32); len(key) != 32 || !bytes.Equal(key[:16], wantKey) {
+		t.Errorf("DeriveKey with 32 byte key: got % 02x", key)
+	}
+
+	for _, tst := range []struct {
+		salt       []byte
+		iterations int
+		err        error
+	}{
+		{nil, 1000, errSalt},
+		{salt, 0, errIterations},
+		{salt, -1, errIterations},
+	} {
+		if _, err := NewFromPassphrase(passphrase, tst.salt, tst.iterations); err != tst.err {
+			t.Errorf("NewFromPassphrase(%q, %d): got %v, want %v", tst.salt, tst.iterations, err, tst.err)
+		}
+	}
+
+	for _, l := range []int{-1, 0, 256} {
+		if _, err := DeriveKey(passphrase, salt, 1000, l); !errors.Is(err, KeySizeError(l)) {
+			t.Errorf("DeriveKey with %d byte key: got %v, want KeySizeError(%d)", l, err, l)
+		}
+	}
+}
+
+func TestDeriveKeys(t *testing.T) {
+
+	master := seq(0, 32)
+
+	keys, err := DeriveKeys(master, 3, 16)
+	if err != nil {
+		t.Fatalf("DeriveKeys failed: %v", err)
+	}
+
+	// computed with a reference HKDF-SHA256
+	for i, want := range []string{
+		"7cee46ae876a67c4c73d0fb75d94eb51",
+		"7bb9b33ae10fae3f3f1df12beb3e51b2",
+		"84de811f932f433370e4385c1ac786a4",
+	} {
+		if got := hex.EncodeToString(keys[i]); got != want {
+			t.Errorf("DeriveKeys[%d]=%s, want %s", i, got, want)
+		}
+	}
+
+	// the keys are usable as RC5 keys
+	if _, err := New(keys[0]); err != nil {
+		t.Errorf("New with derived key failed: %v", err)
+	}
+
+	for _, tst := range []struct {
+		master    []byte
+		n, keyLen int
+		err       error
+	}{
+		{nil, 1, 16, errMasterKey},
+		{master, 0, 16, errSubkeys},
+		{master, 1, -1, KeySizeError(-1)},
+		{master, 1, 0, KeySizeError(0)},
+		{master, 1, 256, KeySizeError(256)},
+	} {
+		if _, err := DeriveKeys(tst.master, tst.n, tst.keyLen); !errors.Is(err, tst.err) {
+			t.Errorf("DeriveKeys(%d, %d, %d): got %v, want %v", len(tst.master), tst.n, tst.keyLen, err, tst.err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"hash"
+	"math/bits"
+)
+
+type pmac struct {
+	b     cipher.Block
+	l     [][]byte // l[i] is L·x^i
+	linv  []byte   // L·x^-1
+	off   []byte   // current offset
+	sigma []byte   // running sum of the encrypted blocks
+	buf   []byte   // pending input, never more than one block
+	n     uint64   // number of blocks folded into sigma
+}
+
+// NewPMAC returns a hash.Hash computing PMAC1 of its input under
+// RC5-32/12/16 with the given key.  Unlike CMAC, every block but the last is
+// encrypted independently, so the work parallelizes.  The tag is one 8-byte
+// block.
+func NewPMAC(key []byte) (hash.Hash, error) {
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+	return newPMAC(b), nil
+}
+
+func newPMAC(b cipher.Block) *pmac {
+
+	bs := b.BlockSize()
+
+	l := make([]byte, bs)
+	b.Encrypt(l, l)
+
+	m := &pmac{
+		b:     b,
+		l:     [][]byte{l},
+		linv:  make([]byte, bs),
+		off:   make([]byte, bs),
+		sigma: make([]byte, bs),
+		buf:   make([]byte, 0, bs),
+	}
+
+	half(m.linv, l)
+
+	return m
+}
+
+// half sets dst to src divided by x in GF(2^n), the inverse of dbl.
+func half(dst, src []byte) {
+
+	rb := byte(0x1B)
+	if len(src) == 16 {
+		rb = 0x87
+	}
+
+	lsb := int(src[len(src)-1] & 1)
+
+	for i := len(src) - 1; i > 0; i-- {
+		dst[i] = src[i]>>1 | src[i-1]<<7
+	}
+	dst[0] = src[0] >> 1
+
+	dst[0] ^= byte(subtle.ConstantTimeSelect(lsb, 0x80, 0))
+	dst[len(src)-1] ^= byte(subtle.ConstantTimeSelect(lsb, int(rb>>1), 0))
+}
+
+func (m *pmac) Size() int      { return m.b.BlockSize() }
+func (m *pmac) BlockSize() int { return m.b.BlockSize() }
+
+func (m *pmac) Reset() {
+	clear(m.off)
+	clear(m.sigma)
+	m.buf = m.buf[:0]
+	m.n = 0
+}
+
+// block folds the full buffered block into sigma.
+func (m *pmac) block() {
+
+	m.n++
+
+	// the offsets follow a Gray code, so each one differs from the last in
+	// a single L·x^i term
+	i := bits.TrailingZeros64(m.n)
+	for len(m.l) <= i {
+		d := make([]byte, len(m.off))
+		dbl(d, m.l[len(m.l)-1])
+		m.l = append(m.l, d)
+	}
+	subtle.XORBytes(m.off, m.off, m.l[i])
+
+	subtle.XORBytes(m.buf, m.buf, m.off)
+	m.b.Encrypt(m.buf, m.buf)
+	subtle.XORBytes(m.sigma, m.sigma, m.buf)
+
+	m.buf = m.buf[:0]
+}
+
+func (m *pmac) Write(p []byte) (int, error) {
+
+	n := len(p)
+	bs := m.b.BlockSize()
+
+	for len(p) > 0 {
+		// as with CMAC, the final block is treated specially
+		if len(m.buf) == bs {
+			m.block()
+		}
+
+		k := min(bs-len(m.buf), len(p))
+		m.buf = append(m.buf, p[:k]...)
+		p = p[k:]
+	}
+
+	return n, nil
+}
+
+func (m *pmac) Sum(in []byte) []byte {
+
+	bs := m.b.BlockSize()
+
+	last := make([]byte, bs)
+	copy(last, m.buf)
+
+	if len(m.buf) == bs {
+		subtle.XORBytes(last, last, m.linv)
+	} else {
+		last[len(m.buf)] = 0x80
+	}
+
+	subtle.XORBytes(last, last, m.sigma)
+	m.b.Encrypt(last, last)
+
+	return append(in, last...)
+}
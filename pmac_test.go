@@ -0,0 +1,87 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestPMAC(t *testing.T) {
+
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
+
+	// computed with a reference PMAC1 built on the reference RC5 code
+	for _, tst := range []struct {
+		len int
+		tag string
+	}{
+		{0, "d874fd706f300a7e"},
+		{3, "5d294978844c99c2"},
+		{8, "56e9128042dd1a46"},
+		{20, "97b52c0db9a0e472"},
+		{32, "d13ea0e3d676ef6b"},
+		{100, "c93173a140f86a8b"},
+	} {
+		msg := make([]byte, tst.len)
+		for i := range msg {
+			msg[i] = byte(i)
+		}
+
+		want, _ := hex.DecodeString(tst.tag)
+
+		h, err := NewPMAC(key)
+		if err != nil {
+			t.Fatalf("NewPMAC failed: %v", err)
+		}
+
+		h.Write(msg)
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("PMAC(len=%d)=% 02x, want % 02x", tst.len, got, want)
+		}
+
+		h.Reset()
+		for i := range msg {
+			h.Write(msg[i : i+1])
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("bytewise PMAC(len=%d)=% 02x, want % 02x", tst.len, got, want)
+		}
+	}
+}
+
+func TestPMACAES(t *testing.T) {
+
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
+
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// PMAC1-AES-128 vectors published with the PMAC reference code
+	for _, tst := range []struct {
+		len int
+		tag string
+	}{
+		{0, "4399572cd6ea5341b8d35876a7098af7"},
+		{3, "256ba5193c1b991b4df0c51f388a9e27"},
+		{16, "ebbd822fa458daf6dfdad7c27da76338"},
+		{20, "0412ca150bbf79058d8c75a58c993f55"},
+		{32, "e97ac04e9e5e3399ce5355cd7407bc75"},
+		{34, "5cba7d5eb24f7c86ccc54604e53d5512"},
+	} {
+		msg := make([]byte, tst.len)
+		for i := range msg {
+			msg[i] = byte(i)
+		}
+
+		want, _ := hex.DecodeString(tst.tag)
+
+		h := newPMAC(b)
+		h.Write(msg)
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("PMAC-AES(len=%d)=% 02x, want % 02x", tst.len, got, want)
+		}
+	}
+}
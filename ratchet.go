@@ -0,0 +1,54 @@
+package rc5
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+)
+
+// A Ratchet is an RC5-32/12/16 cipher whose key can be replaced by one derived
+// from the current key and fresh material, for periodic rekeying of a
+// long-lived connection.  The embedded *Cipher is rekeyed in place on each call
+// to Ratchet, so modes built on it use the new key from then on.  A Ratchet is
+// not safe for concurrent use.
+type Ratchet struct {
+	*Cipher
+	key []byte
+}
+
+// NewRatchet returns a Ratchet starting from the given 16-byte key.
+func NewRatchet(key []byte) (*Ratchet, error) {
+
+	if l := len(key); l != 16 {
+		return nil, KeyLengthError{l, 16, 16}
+	}
+
+	b, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ratchet{Cipher: b.(*Cipher), key: append([]byte(nil), key...)}, nil
+}
+
+// Ratchet replaces the key with HKDF-SHA256 of newKeyMaterial, salted with the
+// current key, wipes the previous key and key schedule, and rebuilds the
+// schedule in place from the new key.  Someone who later learns the new key
+// cannot recover the old one, so traffic before the ratchet stays protected.
+func (r *Ratchet) Ratchet(newKeyMaterial []byte) error {
+
+	key, err := hkdf.Key(sha256.New, newKeyMaterial, r.key, "rc5 ratchet", len(r.key))
+	if err != nil {
+		return err
+	}
+
+	r.Cipher.Wipe()
+	if err := r.Cipher.Rekey(key); err != nil {
+		clear(key)
+		return err
+	}
+
+	clear(r.key)
+	r.key = key
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"testing"
+)
+
+func TestRatchet(t *testing.T) {
+
+	r, err := NewRatchet(tests[0].key)
+	if err != nil {
+		t.Fatalf("NewRatchet failed: %v", err)
+	}
+
+	// a ratchet starts out as the plain cipher
+	ct := make([]byte, 8)
+	r.Encrypt(ct, tests[0].plain)
+	if !bytes.Equal(ct, tests[0].cipher) {
+		t.Errorf("encrypt before ratchet:\ngot : % 02x\nwant: % 02x", ct, tests[0].cipher)
+	}
+
+	old, oldKey := r.Cipher, r.key
+	iv := seq(0, 8)
+	cbc := cipher.NewCBCEncrypter(r, iv)
+
+	if err := r.Ratchet([]byte("epoch 1")); err != nil {
+		t.Fatalf("Ratchet failed: %v", err)
+	}
+
+	r.Encrypt(ct, tests[0].plain)
+	if bytes.Equal(ct, tests[0].cipher) {
+		t.Error("ciphertext unchanged after ratchet")
+	}
+
+	// the cipher is rekeyed in place, so modes built before the ratchet
+	// follow the new key
+	if r.Cipher != old {
+		t.Fatal("Ratchet replaced the cipher instead of rekeying it")
+	}
+	if r.wiped {
+		t.Error("rekeyed cipher still marked wiped")
+	}
+	fresh, _ := New(r.key)
+	got := make([]byte, 16)
+	want := make([]byte, 16)
+	cbc.CryptBlocks(got, seq(0x10, 16))
+	cipher.NewCBCEncrypter(fresh, iv).CryptBlocks(want, seq(0x10, 16))
+	if !bytes.Equal(got, want) {
+		t.Errorf("mode built before ratchet:\ngot : % 02x\nwant: % 02x", got, want)
+	}
+
+	if !bytes.Equal(oldKey, make([]byte, len(oldKey))) {
+		t.Errorf("old key not wiped: % 02x", oldKey)
+	}
+
+	// the ratchet is deterministic
+	r2, _ := NewRatchet(tests[0].key)
+	r2.Ratchet([]byte("epoch 1"))
+	if !r.Equal(r2.Cipher) {
+		t.Error("ratchets from the same key and material differ")
+	}
+
+	// and depends on the material
+	r3, _ := NewRatchet(tests[0].key)
+	r3.Ratchet([]byte("epoch 2"))
+	if r.Equal(r3.Cipher) {
+		t.Error("ratchets with different material are equal")
+	}
+}
+
+func TestRatchetKeyLength(t *testing.T) {
+
+	for _, l := range []int{0, 8, 15, 17, 32} {
+		_, err := NewRatchet(make([]byte, l))
+		if _, ok := err.(KeyLengthError); !ok {
+			t.Errorf("NewRatchet(%d-byte key): got %v, want KeyLengthError", l, err)
+		}
+	}
+}
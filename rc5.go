@@ -12,112 +12,940 @@ package rc5
 import (
 	"crypto/cipher"
 	"encoding/binary"
-	"math/bits"
+	"errors"
+	"slices"
 	"strconv"
 )
 
+// defaultRoundKeys is the size of the RC5-32/12 key schedule
+const defaultRoundKeys = 2 * (12 + 1)
+
+// BlockSize is the RC5-32 block size in bytes, as used by New and the modes
+// in this package.
+const BlockSize = 8
+
+// BlockSizeFor returns the block size in bytes of RC5 with the given word size
+// in bits, or 0 if the word size is not 16, 32 or 64.
+func BlockSizeFor(wordSize int) int {
+	switch wordSize {
+	case 16, 32, 64:
+		return 2 * wordSize / 8
+	}
+	return 0
+}
+
+// magic constants for key expansion, computed as
+/*
+
+   Pw = Odd((e-2) * 2**w)
+   Qw = Odd((phi-1) * 2**w)
+
+*/
 const (
-	rounds    = 12
-	roundKeys = 2 * (rounds + 1)
+	p16 = 0xb7e1
+	q16 = 0x9e37
+
+	p32 = 0xb7e15163
+	q32 = 0x9e3779b9
+
+	p64 = 0xb7e151628aed2a6b
+	q64 = 0x9e3779b97f4a7c15
 )
 
-type rc5cipher struct {
-	rk [roundKeys]uint32
+// initTable fills rk with the initial key-expansion table computed from
+/*
+
+   S[0] = Pw;
+   for (i = 1 ; i < T ; i++)  {
+       S[i] = S[i-1] + Qw;
+   }
+*/
+
+func initTable[T word](rk []T, pw, qw T) {
+	rk[0] = pw
+	for i := 1; i < len(rk); i++ {
+		rk[i] = rk[i-1] + qw
+	}
+}
+
+// A Cipher is an instance of RC5 using a particular key and parameters.  The
+// constructors in this package return a *Cipher as a cipher.Block; callers
+// needing the additional methods can type-assert to *Cipher.
+//
+// The key schedule is not modified after construction, so a Cipher may be used
+// by multiple goroutines concurrently, except that Rekey, UnmarshalBinary and
+// Wipe must not run concurrently with any other method.
+type Cipher struct {
+	w      int // word size in bits
+	rounds int
+	keyLen int    // key length in bytes, or -1 if unknown
+	pw, qw uint64 // magic constants used for key expansion
+	passes int    // key expansion mixing passes, 3 unless strengthened
+	rk16   []uint16
+	rk32   []uint32
+	rk64   []uint64
+	wiped  bool
+
+	// bigEndian selects big-endian words instead of the standard little-endian
+	bigEndian bool
 }
 
+// A Wiper is a cipher.Block whose expanded key schedule can be erased.  The
+// ciphers returned by this package implement Wiper.
+type Wiper interface {
+	cipher.Block
+
+	// Wipe overwrites the key schedule with zeros.  The cipher must not be
+	// used afterwards.  Wipe may be called more than once.
+	Wipe()
+}
+
+// A KeySizeError is an invalid key length.  Constructors report the allowed
+// range with a KeyLengthError, which wraps a KeySizeError.
 type KeySizeError int
 
 func (k KeySizeError) Error() string { return "rc5: invalid key size " + strconv.Itoa(int(k)) }
 
+// A KeyLengthError reports a key whose length is outside the range, from Min
+// to Max bytes, that a constructor accepts.  It wraps the KeySizeError for the
+// same length, so errors.Is(err, KeySizeError(n)) still matches.
+type KeyLengthError struct {
+	Length   int
+	Min, Max int
+}
+
+// TooShort reports whether the key was shorter than the minimum.
+func (e KeyLengthError) TooShort() bool { return e.Length < e.Min }
+
+// TooLong reports whether the key was longer than the maximum.
+func (e KeyLengthError) TooLong() bool { return e.Length > e.Max }
+
+func (e KeyLengthError) Error() string {
+
+	s := "rc5: key too long: " + strconv.Itoa(e.Length) + " bytes, "
+	if e.TooShort() {
+		s = "rc5: key too short: " + strconv.Itoa(e.Length) + " bytes, "
+	}
+
+	switch {
+	case e.Min == e.Max:
+		return s + "need exactly " + strconv.Itoa(e.Min)
+	case e.TooShort():
+		return s + "need at least " + strconv.Itoa(e.Min)
+	}
+	return s + "maximum " + strconv.Itoa(e.Max)
+}
+
+func (e KeyLengthError) Unwrap() error { return KeySizeError(e.Length) }
+
+var errByteOrder = errors.New("rc5: byte order must be little- or big-endian")
+
+// A ParameterError is returned when a word size or round count is not supported.
+type ParameterError struct {
+	Parameter string // "word size", "rounds" or "extra passes"
+	Value     int
+}
+
+func (p ParameterError) Error() string {
+	return "rc5: invalid " + p.Parameter + " " + strconv.Itoa(p.Value)
+}
+
 // New returns a cipher.Block implementing RC5-32/12/16.  The key argument must be 16 bytes.
-func New(key []byte) (cipher.Block, error) {
+// Options may select a different word size, number of rounds or byte order;
+// they are validated together once all have been applied.
+func New(key []byte, opts ...Option) (cipher.Block, error) {
+
+	p := params{wordSize: 32, rounds: 12}
+	for _, opt := range opts {
+		opt(&p)
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
 
 	if l := len(key); l != 16 {
-		return nil, KeySizeError(l)
+		return nil, KeyLengthError{l, 16, 16}
+	}
+
+	return newBlock(p, key)
+}
+
+// NewCipher is the same as New, and matches the naming of the standard
+// library's block ciphers such as aes.NewCipher.
+func NewCipher(key []byte) (cipher.Block, error) {
+	return New(key)
+}
+
+// NewZeroPadded returns a cipher.Block implementing RC5-32/12/16 for a key of
+// at most 16 bytes, right-padding a shorter key with zero bytes to 16 bytes.
+// This reproduces legacy systems which zero-pad short keys; note that it is not
+// the same as RC5-32/12/b with a shorter key b, since the padding changes the
+// key schedule.
+func NewZeroPadded(key []byte) (cipher.Block, error) {
+
+	if l := len(key); l > 16 {
+		return nil, KeyLengthError{l, 0, 16}
+	}
+
+	var padded [16]byte
+	copy(padded[:], key)
+
+	return New(padded[:])
+}
+
+var errWeakKey = errors.New("rc5: weak key")
+
+// NewStrict is like New but rejects keys which are obviously degenerate: keys
+// whose bytes are all identical, including the all-zero key.  These usually
+// indicate an uninitialised buffer or a broken key generator rather than a
+// deliberate choice.  Passing this check says nothing about the key's entropy.
+func NewStrict(key []byte) (cipher.Block, error) {
+
+	if l := len(key); l != 16 {
+		return nil, KeyLengthError{l, 16, 16}
+	}
+
+	weak := true
+	for _, b := range key[1:] {
+		if b != key[0] {
+			weak = false
+			break
+		}
+	}
+
+	if weak {
+		return nil, errWeakKey
+	}
+
+	return New(key)
+}
+
+// NewWithRounds returns a cipher.Block implementing RC5-32/r/16 with the given
+// number of rounds, which must be between 0 and 255.
+func NewWithRounds(rounds int, key []byte) (cipher.Block, error) {
+	if l := len(key); l != 16 {
+		return nil, KeyLengthError{l, 16, 16}
+	}
+	return NewWithParameters(32, rounds, key)
+}
+
+// The preset constructors are named NewW_R for RC5-W/R/16: W is the word size
+// in bits and R the number of rounds.  The key argument must be 16 bytes.
+
+// New32_16 returns a cipher.Block implementing RC5-32/16/16.
+func New32_16(key []byte) (cipher.Block, error) {
+	return NewWithRounds(16, key)
+}
+
+// New32_20 returns a cipher.Block implementing RC5-32/20/16.
+func New32_20(key []byte) (cipher.Block, error) {
+	return NewWithRounds(20, key)
+}
+
+// New192 returns a cipher.Block implementing RC5-32/12/24, for a 192-bit key.
+// The key argument must be 24 bytes.
+func New192(key []byte) (cipher.Block, error) {
+	if l := len(key); l != 24 {
+		return nil, KeyLengthError{l, 24, 24}
+	}
+	return NewWithParameters(32, 12, key)
+}
+
+// New256 returns a cipher.Block implementing RC5-32/12/32, for a 256-bit key.
+// The key argument must be 32 bytes.
+func New256(key []byte) (cipher.Block, error) {
+	if l := len(key); l != 32 {
+		return nil, KeyLengthError{l, 32, 32}
+	}
+	return NewWithParameters(32, 12, key)
+}
+
+// NewWithParameters returns a cipher.Block implementing RC5-w/r/b.  The word
+// size w must be 16, 32 or 64 bits, the number of rounds r must be between 0
+// and 255, and the key length b must be between 0 and 255 bytes.  The block
+// size is 2*w bits.
+func NewWithParameters(wordSize, rounds int, key []byte) (cipher.Block, error) {
+	return newBlock(params{wordSize: wordSize, rounds: rounds}, key)
+}
+
+// NewWithByteOrder returns a cipher.Block implementing RC5-32/12/16 which
+// loads and stores words, including the words of the key, in the given byte
+// order.  Standard RC5 is little-endian; binary.BigEndian gives a variant
+// compatible with big-endian implementations.  The key argument must be 16 bytes.
+func NewWithByteOrder(order binary.ByteOrder, key []byte) (cipher.Block, error) {
+
+	bigEndian, err := isBigEndian(order)
+	if err != nil {
+		return nil, err
+	}
+
+	if l := len(key); l != 16 {
+		return nil, KeyLengthError{l, 16, 16}
+	}
+
+	return newBlock(params{wordSize: 32, rounds: 12, bigEndian: bigEndian}, key)
+}
+
+// isBigEndian reports whether order is big-endian, or returns an error if it
+// is neither little- nor big-endian.
+func isBigEndian(order binary.ByteOrder) (bool, error) {
+	switch order.Uint32([]byte{1, 2, 3, 4}) {
+	case 0x04030201:
+		return false, nil
+	case 0x01020304:
+		return true, nil
+	}
+	return false, errByteOrder
+}
+
+// NewWithConstants returns a cipher.Block implementing RC5-32/12/16 whose key
+// expansion is seeded from the given magic constants instead of the standard
+// Pw=0xb7e15163 and Qw=0x9e3779b9.  With any other values the result is a
+// non-standard cipher which will not interoperate with RC5.  The key argument
+// must be 16 bytes.
+func NewWithConstants(pw, qw uint32, key []byte) (cipher.Block, error) {
+
+	if l := len(key); l != 16 {
+		return nil, KeyLengthError{l, 16, 16}
+	}
+
+	return newBlock(params{wordSize: 32, rounds: 12, customConstants: true, pw: uint64(pw), qw: uint64(qw)}, key)
+}
+
+// maxExtraPasses bounds the extra key expansion passes of NewStrengthened.
+const maxExtraPasses = 1 << 16
+
+// NewStrengthened returns a cipher.Block implementing RC5-32/12/16 whose key
+// expansion runs its mixing loop for 3+extraPasses passes instead of the
+// standard 3, making each key setup, and so each guess in a brute-force
+// search, proportionally more expensive.  With extraPasses zero it is the
+// same as New.  The key argument must be 16 bytes, and extraPasses must be
+// between 0 and 65536.
+//
+// Any positive extraPasses gives a different, non-standard key schedule: the
+// cipher does not interoperate with other RC5 implementations.
+func NewStrengthened(key []byte, extraPasses int) (cipher.Block, error) {
+
+	if extraPasses < 0 || extraPasses > maxExtraPasses {
+		return nil, ParameterError{"extra passes", extraPasses}
+	}
+
+	if l := len(key); l != 16 {
+		return nil, KeyLengthError{l, 16, 16}
+	}
+
+	return newBlock(params{wordSize: 32, rounds: 12, extraPasses: extraPasses}, key)
+}
+
+var errScheduleSize = errors.New("rc5: schedule must hold 26 round keys")
+
+// NewInto is like New but expands the key into schedule, which must have
+// exactly 26 elements, instead of allocating a key schedule, so the round keys
+// can be placed in memory the caller controls.  Only the small Cipher value
+// itself is allocated.  The cipher keeps using schedule, which must not be
+// modified while the cipher is in use; Wipe clears it.
+func NewInto(schedule []uint32, key []byte) (cipher.Block, error) {
+
+	if len(schedule) != defaultRoundKeys {
+		return nil, errScheduleSize
+	}
+
+	if l := len(key); l != 16 {
+		return nil, KeyLengthError{l, 16, 16}
+	}
+
+	c := &Cipher{w: 32, rounds: 12, passes: keyPasses, rk32: schedule[:defaultRoundKeys:defaultRoundKeys]}
+	c.pw, c.qw = magicConstants(32)
+	c.expandKey(key)
+
+	return c, nil
+}
+
+// params holds the configuration of a cipher before its key is expanded.
+type params struct {
+	wordSize  int
+	rounds    int
+	bigEndian bool
+
+	// non-standard magic constants, used if customConstants is set
+	customConstants bool
+	pw, qw          uint64
+
+	extraPasses int // non-standard key expansion passes beyond the usual 3
+
+	err error // first error from an Option
+}
+
+func checkParameters(wordSize, rounds int, key []byte) error {
+
+	switch wordSize {
+	case 16, 32, 64:
+	default:
+		return ParameterError{"word size", wordSize}
+	}
+
+	if rounds < 0 || rounds > 255 {
+		return ParameterError{"rounds", rounds}
+	}
+
+	if l := len(key); l > 255 {
+		return KeyLengthError{l, 0, 255}
+	}
+
+	return nil
+}
+
+// newBlock is newCipher returning a cipher.Block, which is a nil interface
+// rather than a nil *Cipher on error.
+func newBlock(p params, key []byte) (cipher.Block, error) {
+	c, err := newCipher(p, key)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func newCipher(p params, key []byte) (*Cipher, error) {
+
+	wordSize, rounds := p.wordSize, p.rounds
+
+	if err := checkParameters(wordSize, rounds, key); err != nil {
+		return nil, err
+	}
+
+	roundKeys := 2 * (rounds + 1)
+
+	var c *Cipher
+
+	switch wordSize {
+	case 16:
+		c = &Cipher{rk16: make([]uint16, roundKeys)}
+	case 32:
+		if roundKeys == defaultRoundKeys {
+			// allocate the common RC5-32/12 schedule along with the cipher
+			a := new(struct {
+				c  Cipher
+				rk [defaultRoundKeys]uint32
+			})
+			c = &a.c
+			c.rk32 = a.rk[:]
+		} else {
+			c = &Cipher{rk32: make([]uint32, roundKeys)}
+		}
+	case 64:
+		c = &Cipher{rk64: make([]uint64, roundKeys)}
+	}
+
+	c.w, c.rounds, c.bigEndian = wordSize, rounds, p.bigEndian
+	c.passes = keyPasses + p.extraPasses
+
+	if p.customConstants {
+		c.pw, c.qw = p.pw, p.qw
+	} else {
+		c.pw, c.qw = magicConstants(c.w)
+	}
+
+	c.expandKey(key)
+
+	return c, nil
+}
+
+// Rekey replaces the cipher's key, expanding the new key into the existing
+// key schedule without allocating.  The previous schedule is overwritten.  The
+// word size, rounds and byte order are unchanged, and the key length must be
+// between 0 and 255 bytes as for NewWithParameters.
+func (c *Cipher) Rekey(key []byte) error {
+
+	if l := len(key); l > 255 {
+		return KeyLengthError{l, 0, 255}
 	}
 
-	c := &rc5cipher{}
+	c.expandKey(key)
+	c.wiped = false
 
-	const keyWords = 4
+	return nil
+}
+
+// magicConstants returns the standard Pw and Qw for a word size of w bits,
+// which must be 16, 32 or 64.
+func magicConstants(w int) (pw, qw uint64) {
+	switch w {
+	case 16:
+		return p16, q16
+	case 32:
+		return p32, q32
+	case 64:
+		return p64, q64
+	}
+	panic("rc5: no magic constants for word size " + strconv.Itoa(w))
+}
+
+func (c *Cipher) expandKey(key []byte) {
+
+	c.keyLen = len(key)
+
+	switch c.w {
+	case 16:
+		expandKeyWords(c.rk16, key, c.bigEndian, uint16(c.pw), uint16(c.qw), c.passes)
+	case 32:
+		expandKeyWords(c.rk32, key, c.bigEndian, uint32(c.pw), uint32(c.qw), c.passes)
+	case 64:
+		expandKeyWords(c.rk64, key, c.bigEndian, uint64(c.pw), uint64(c.qw), c.passes)
+	}
+}
+
+// ExpandKey returns the 2*(rounds+1) round keys of standard RC5-w/r/b for the
+// given key, widened to uint64, without creating a cipher.  It is intended
+// for comparing key schedules with other implementations.
+func ExpandKey(wordSize, rounds int, key []byte) ([]uint64, error) {
+	return expandKeyTrace(wordSize, rounds, key, nil)
+}
+
+// A TraceStep records one iteration of the key expansion mixing loop: the
+// round key S[RoundKey] and key word L[KeyWord] it updated, and the values of
+// A and B afterwards, which are the new S[RoundKey] and L[KeyWord].
+type TraceStep struct {
+	A, B     uint64
+	RoundKey int
+	KeyWord  int
+}
+
+// ExpandKeyTrace is ExpandKey, additionally returning one TraceStep for each
+// iteration of the mixing loop, KeyScheduleOps(wordSize, rounds, len(key))
+// in all.  It is a debugging aid for tracking down where a key schedule
+// diverges from another implementation's.
+func ExpandKeyTrace(wordSize, rounds int, key []byte) (schedule []uint64, trace []TraceStep, err error) {
+
+	trace = make([]TraceStep, 0, KeyScheduleOps(wordSize, rounds, len(key)))
+
+	schedule, err = expandKeyTrace(wordSize, rounds, key, &trace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return schedule, trace, nil
+}
+
+func expandKeyTrace(wordSize, rounds int, key []byte, trace *[]TraceStep) ([]uint64, error) {
+
+	if err := checkParameters(wordSize, rounds, key); err != nil {
+		return nil, err
+	}
+
+	out := make([]uint64, 2*(rounds+1))
+	pw, qw := magicConstants(wordSize)
+
+	switch wordSize {
+	case 16:
+		rk := make([]uint16, len(out))
+		expandKeyWordsTrace(rk, key, false, uint16(pw), uint16(qw), keyPasses, trace)
+		for i, k := range rk {
+			out[i] = uint64(k)
+		}
+	case 32:
+		rk := make([]uint32, len(out))
+		expandKeyWordsTrace(rk, key, false, uint32(pw), uint32(qw), keyPasses, trace)
+		for i, k := range rk {
+			out[i] = uint64(k)
+		}
+	case 64:
+		expandKeyWordsTrace(out, key, false, pw, qw, keyPasses, trace)
+	}
+
+	return out, nil
+}
 
-	var L [keyWords]uint32
+// KeyScheduleOps returns the number of mixing iterations the standard key
+// expansion of RC5-w/r/b performs for the given word size in bits, rounds and
+// key length in bytes: 3*max(2*(rounds+1), c), where c is the number of words
+// in the key, at least 1.  Each iteration updates one round key and one key
+// word, so this is a measure of the cost of key setup.  It returns 0 if the
+// parameters are not accepted by NewWithParameters.
+func KeyScheduleOps(wordSize, rounds, keyLen int) int {
 
-	for i := 0; i < keyWords; i++ {
-		L[i] = binary.LittleEndian.Uint32(key[:4])
-		key = key[4:]
+	if checkParameters(wordSize, rounds, nil) != nil || keyLen < 0 || keyLen > 255 {
+		return 0
 	}
 
-	copy(c.rk[:], skeytable)
+	u := wordSize / 8
+	keyWords := max(1, (keyLen+u-1)/u)
 
-	var A uint32
-	var B uint32
+	return keyPasses * max(2*(rounds+1), keyWords)
+}
+
+// keyPasses is the number of passes the standard key expansion makes over the
+// larger of the key schedule and the key words.
+const keyPasses = 3
+
+// expandKeyWords fills rk with the RC5 key schedule for key, using a word
+// size of the width of T, the magic constants pw and qw, and the given number
+// of mixing passes.
+func expandKeyWords[T word](rk []T, key []byte, bigEndian bool, pw, qw T, passes int) {
+	expandKeyWordsTrace(rk, key, bigEndian, pw, qw, passes, nil)
+}
+
+// expandKeyWordsTrace is expandKeyWords, appending a TraceStep for each
+// mixing iteration to trace if it is not nil.
+func expandKeyWordsTrace[T word](rk []T, key []byte, bigEndian bool, pw, qw T, passes int, trace *[]TraceStep) {
+
+	u := int(wordBits[T]() / 8)
+
+	roundKeys := len(rk)
+	keyWords := max(1, (len(key)+u-1)/u)
+
+	// enough for a 255-byte key in 16-bit words
+	var buf [128]T
+	L := buf[:keyWords]
+	loadKey(L, key, bigEndian)
+
+	initTable(rk, pw, qw)
+
+	var A T
+	var B T
 	var i, j int
 
-	for k := 0; k < 3*roundKeys; k++ {
-		c.rk[i] = bits.RotateLeft32(c.rk[i]+(A+B), 3)
-		A = c.rk[i]
-		L[j] = bits.RotateLeft32(L[j]+(A+B), int(A+B))
+	for k := 0; k < passes*max(roundKeys, keyWords); k++ {
+		rk[i] = rotl(rk[i]+(A+B), 3)
+		A = rk[i]
+		L[j] = rotl(L[j]+(A+B), A+B)
 		B = L[j]
 
-		i = (i + 1) % roundKeys
-		j = (j + 1) % keyWords
+		if trace != nil {
+			*trace = append(*trace, TraceStep{uint64(A), uint64(B), i, j})
+		}
+
+		if i++; i == roundKeys {
+			i = 0
+		}
+		if j++; j == keyWords {
+			j = 0
+		}
 	}
 
-	return c, nil
+	// don't leave key material on the stack
+	clear(L)
 }
 
-func (c *rc5cipher) BlockSize() int { return 8 }
+// loadKey copies the key into the zeroed words of L byte by byte, as in the
+// RC5 specification:
+//
+//	for i = b-1 downto 0: L[i/u] = (L[i/u] <<< 8) + K[i]
+//
+// which treats the key as little-endian words and zero-pads a short final
+// word.  A big-endian cipher places the bytes of each word in the opposite
+// order.
+func loadKey[T word](L []T, key []byte, bigEndian bool) {
+
+	u := int(wordBits[T]() / 8)
+
+	if bigEndian {
+		for i, k := range key {
+			L[i/u] |= T(k) << (8*(u-1) - 8*(i%u))
+		}
+		return
+	}
 
-func (c *rc5cipher) Encrypt(dst, src []byte) {
+	for i := len(key) - 1; i >= 0; i-- {
+		L[i/u] = L[i/u]<<8 + T(key[i])
+	}
+}
+
+func (c *Cipher) BlockSize() int { return 2 * c.w / 8 }
 
-	A := binary.LittleEndian.Uint32(src[:4]) + c.rk[0]
-	B := binary.LittleEndian.Uint32(src[4:8]) + c.rk[1]
+// Rounds returns the number of rounds.
+func (c *Cipher) Rounds() int { return c.rounds }
 
-	kidx := 2
+// WordSize returns the word size in bits.
+func (c *Cipher) WordSize() int { return c.w }
 
-	for r := 0; r < rounds; r++ {
-		A = bits.RotateLeft32(A^B, int(B)) + c.rk[kidx]
-		B = bits.RotateLeft32(B^A, int(A)) + c.rk[kidx+1]
-		kidx += 2
+// KeyLength returns the length in bytes of the key the cipher was created
+// with, or -1 for a cipher restored by UnmarshalBinary from an encoding which
+// does not record it.
+func (c *Cipher) KeyLength() int { return c.keyLen }
+
+// String describes the cipher's parameters in the RC5-w/r/b notation, such as
+// "RC5-32/12/16".  The key length is shown as "?" for a cipher restored by
+// UnmarshalBinary from an encoding which does not record it.
+func (c *Cipher) String() string {
+	b := "?"
+	if c.keyLen >= 0 {
+		b = strconv.Itoa(c.keyLen)
 	}
+	return "RC5-" + strconv.Itoa(c.w) + "/" + strconv.Itoa(c.rounds) + "/" + b
+}
 
-	binary.LittleEndian.PutUint32(dst[:4], A)
-	binary.LittleEndian.PutUint32(dst[4:8], B)
+// Clone returns a copy of the cipher with its own key schedule, so the copy
+// is unaffected by Rekey or Wipe on the original and vice versa.
+func (c *Cipher) Clone() cipher.Block {
+	n := *c
+	n.rk16 = slices.Clone(c.rk16)
+	n.rk32 = slices.Clone(c.rk32)
+	n.rk64 = slices.Clone(c.rk64)
+	return &n
 }
 
-func (c *rc5cipher) Decrypt(dst, src []byte) {
+// Equal reports whether c and other have the same parameters and key
+// schedule, and so would encrypt identically.  The round keys are compared in
+// constant time.  A wiped cipher is not equal to any cipher.
+func (c *Cipher) Equal(other *Cipher) bool {
 
-	A := binary.LittleEndian.Uint32(src[:4])
-	B := binary.LittleEndian.Uint32(src[4:8])
+	if c.wiped || other.wiped {
+		return false
+	}
 
-	kidx := 2 * rounds
+	if c.w != other.w || c.rounds != other.rounds || c.bigEndian != other.bigEndian {
+		return false
+	}
 
-	for r := 0; r < rounds; r++ {
-		B = bits.RotateLeft32(B-c.rk[kidx+1], -int(A)) ^ A
-		A = bits.RotateLeft32(A-c.rk[kidx], -int(B)) ^ B
-		kidx -= 2
+	// accumulate the differences rather than stopping at the first one
+	var d uint64
+	for i := range c.rk16 {
+		d |= uint64(c.rk16[i] ^ other.rk16[i])
+	}
+	for i := range c.rk32 {
+		d |= uint64(c.rk32[i] ^ other.rk32[i])
+	}
+	for i := range c.rk64 {
+		d |= c.rk64[i] ^ other.rk64[i]
 	}
 
-	binary.LittleEndian.PutUint32(dst[4:8], B-c.rk[1])
-	binary.LittleEndian.PutUint32(dst[:4], A-c.rk[0])
+	return d == 0
 }
 
-// skeytable computed from
-/*
+// Wipe overwrites the key schedule with zeros, leaving the cipher unusable.
+func (c *Cipher) Wipe() {
+	clear(c.rk16)
+	clear(c.rk32)
+	clear(c.rk64)
+	c.wiped = true
+}
 
-Pw = uint32(0xb7e15163)
-Qw = uint32(0x9e3779b9)
+var (
+	errUseWiped    = errors.New("rc5: use of wiped cipher")
+	errShortInput  = errors.New("rc5: input not full block")
+	errShortOutput = errors.New("rc5: output smaller than block")
+	errOverlap     = errors.New("rc5: invalid buffer overlap")
+)
 
-    T = 2*(R+1);
-    S[0] = Pw;
-    for (i = 1 ; i < T ; i++)  {
-        S[i] = S[i-1] + Qw;
-    }
-*/
+// blockError returns the reason Encrypt or Decrypt would panic, or nil.
+func (c *Cipher) blockError(dst, src []byte) error {
+	if c.wiped {
+		return errUseWiped
+	}
+	bs := c.BlockSize()
+	if len(src) < bs {
+		return errShortInput
+	}
+	if len(dst) < bs {
+		return errShortOutput
+	}
+	if inexactOverlap(dst[:bs], src[:bs]) {
+		return errOverlap
+	}
+	return nil
+}
+
+func (c *Cipher) checkBlock(dst, src []byte) {
+	if err := c.blockError(dst, src); err != nil {
+		panic(err.Error())
+	}
+}
+
+// Encrypt encrypts the first block in src into dst.  Dst and src must overlap
+// entirely or not at all, so Encrypt(buf, buf) encrypts in place.
+func (c *Cipher) Encrypt(dst, src []byte) {
+	c.checkBlock(dst, src)
+
+	switch c.w {
+	case 16:
+		c.encrypt16(dst, src)
+	case 32:
+		if c.rounds == 12 {
+			if useAsm() && !c.bigEndian {
+				encrypt12(&c.rk32[0], &dst[0], &src[0])
+				return
+			}
+			c.encrypt32r12(dst, src)
+			return
+		}
+		c.encrypt32(dst, src)
+	case 64:
+		c.encrypt64(dst, src)
+	}
+}
+
+// Decrypt decrypts the first block in src into dst.  Dst and src must overlap
+// entirely or not at all.
+func (c *Cipher) Decrypt(dst, src []byte) {
+	c.checkBlock(dst, src)
+
+	switch c.w {
+	case 16:
+		c.decrypt16(dst, src)
+	case 32:
+		if c.rounds == 12 {
+			if useAsm() && !c.bigEndian {
+				decrypt12(&c.rk32[0], &dst[0], &src[0])
+				return
+			}
+			c.decrypt32r12(dst, src)
+			return
+		}
+		c.decrypt32(dst, src)
+	case 64:
+		c.decrypt64(dst, src)
+	}
+}
+
+// EncryptSafe is like Encrypt but returns an error instead of panicking if
+// src is shorter than a block, dst is too short, the buffers overlap inexactly
+// or the cipher has been wiped.
+func (c *Cipher) EncryptSafe(dst, src []byte) error {
+	if err := c.blockError(dst, src); err != nil {
+		return err
+	}
+	c.Encrypt(dst, src)
+	return nil
+}
+
+// DecryptSafe is like Decrypt but returns an error instead of panicking, as
+// EncryptSafe.
+func (c *Cipher) DecryptSafe(dst, src []byte) error {
+	if err := c.blockError(dst, src); err != nil {
+		return err
+	}
+	c.Decrypt(dst, src)
+	return nil
+}
+
+func (c *Cipher) load16(b []byte) uint16 {
+	if c.bigEndian {
+		return binary.BigEndian.Uint16(b)
+	}
+	return binary.LittleEndian.Uint16(b)
+}
+
+func (c *Cipher) store16(b []byte, v uint16) {
+	if c.bigEndian {
+		binary.BigEndian.PutUint16(b, v)
+		return
+	}
+	binary.LittleEndian.PutUint16(b, v)
+}
+
+func (c *Cipher) load32(b []byte) uint32 {
+	if c.bigEndian {
+		return binary.BigEndian.Uint32(b)
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (c *Cipher) store32(b []byte, v uint32) {
+	if c.bigEndian {
+		binary.BigEndian.PutUint32(b, v)
+		return
+	}
+	binary.LittleEndian.PutUint32(b, v)
+}
+
+func (c *Cipher) load64(b []byte) uint64 {
+	if c.bigEndian {
+		return binary.BigEndian.Uint64(b)
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+func (c *Cipher) store64(b []byte, v uint64) {
+	if c.bigEndian {
+		binary.BigEndian.PutUint64(b, v)
+		return
+	}
+	binary.LittleEndian.PutUint64(b, v)
+}
+
+// encryptWords and decryptWords are the RC5 block transform on the words A
+// and B with the round keys rk, for any word size.
+
+func encryptWords[T word](rk []T, A, B T) (T, T) {
+
+	A += rk[0]
+	B += rk[1]
+
+	for i := 2; i+1 < len(rk); i += 2 {
+		A = rotl(A^B, B) + rk[i]
+		B = rotl(B^A, A) + rk[i+1]
+	}
+
+	return A, B
+}
+
+func decryptWords[T word](rk []T, A, B T) (T, T) {
+
+	for i := len(rk) - 2; i >= 2; i -= 2 {
+		B = rotr(B-rk[i+1], A) ^ A
+		A = rotr(A-rk[i], B) ^ B
+	}
+
+	return A - rk[0], B - rk[1]
+}
+
+func (c *Cipher) encrypt16(dst, src []byte) {
+
+	src, dst = src[:4:4], dst[:4:4]
+
+	A, B := encryptWords(c.rk16, c.load16(src[:2]), c.load16(src[2:]))
+
+	c.store16(dst[:2], A)
+	c.store16(dst[2:], B)
+}
+
+func (c *Cipher) decrypt16(dst, src []byte) {
+
+	src, dst = src[:4:4], dst[:4:4]
+
+	A, B := decryptWords(c.rk16, c.load16(src[:2]), c.load16(src[2:]))
+
+	c.store16(dst[:2], A)
+	c.store16(dst[2:], B)
+}
+
+func (c *Cipher) encrypt32(dst, src []byte) {
+
+	src, dst = src[:8:8], dst[:8:8]
+
+	A, B := encryptWords(c.rk32, c.load32(src[:4]), c.load32(src[4:]))
+
+	c.store32(dst[:4], A)
+	c.store32(dst[4:], B)
+}
+
+func (c *Cipher) decrypt32(dst, src []byte) {
+
+	src, dst = src[:8:8], dst[:8:8]
+
+	A, B := decryptWords(c.rk32, c.load32(src[:4]), c.load32(src[4:]))
+
+	c.store32(dst[:4], A)
+	c.store32(dst[4:], B)
+}
+
+func (c *Cipher) encrypt64(dst, src []byte) {
+
+	src, dst = src[:16:16], dst[:16:16]
+
+	A, B := encryptWords(c.rk64, c.load64(src[:8]), c.load64(src[8:]))
+
+	c.store64(dst[:8], A)
+	c.store64(dst[8:], B)
+}
+
+func (c *Cipher) decrypt64(dst, src []byte) {
+
+	src, dst = src[:16:16], dst[:16:16]
+
+	A, B := decryptWords(c.rk64, c.load64(src[:8]), c.load64(src[8:]))
 
-var skeytable = []uint32{
-	0xb7e15163, 0x5618cb1c, 0xf45044d5, 0x9287be8e, 0x30bf3847, 0xcef6b200, 0x6d2e2bb9, 0x0b65a572,
-	0xa99d1f2b, 0x47d498e4, 0xe60c129d, 0x84438c56, 0x227b060f, 0xc0b27fc8, 0x5ee9f981, 0xfd21733a,
-	0x9b58ecf3, 0x399066ac, 0xd7c7e065, 0x75ff5a1e, 0x1436d3d7, 0xb26e4d90, 0x50a5c749, 0xeedd4102,
-	0x8d14babb, 0x2b4c3474,
+	c.store64(dst[:8], A)
+	c.store64(dst[8:], B)
 }
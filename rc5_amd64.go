@@ -0,0 +1,14 @@
+//go:build amd64 && !purego
+
+package rc5
+
+const haveAsm = true
+
+// encrypt12 and decrypt12 implement the RC5-32/12 block transform on
+// little-endian words.  rk must point to 26 round keys, dst and src to 8 bytes.
+
+//go:noescape
+func encrypt12(rk *uint32, dst, src *byte)
+
+//go:noescape
+func decrypt12(rk *uint32, dst, src *byte)
@@ -0,0 +1,83 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// A = rotl(A^B, B) + S[2i]
+// B = rotl(B^A, A) + S[2i+1]
+#define ROUND(s0, s1) \
+	XORL BX, AX;      \
+	MOVL BX, CX;      \
+	ROLL CX, AX;      \
+	ADDL s0(SI), AX;  \
+	XORL AX, BX;      \
+	MOVL AX, CX;      \
+	ROLL CX, BX;      \
+	ADDL s1(SI), BX
+
+// B = rotr(B-S[2i+1], A) ^ A
+// A = rotr(A-S[2i], B) ^ B
+#define UNROUND(s0, s1) \
+	SUBL s1(SI), BX;    \
+	MOVL AX, CX;        \
+	RORL CX, BX;        \
+	XORL AX, BX;        \
+	SUBL s0(SI), AX;    \
+	MOVL BX, CX;        \
+	RORL CX, AX;        \
+	XORL BX, AX
+
+// func encrypt12(rk *uint32, dst, src *byte)
+TEXT ·encrypt12(SB), NOSPLIT, $0-24
+	MOVQ rk+0(FP), SI
+	MOVQ dst+8(FP), DI
+	MOVQ src+16(FP), DX
+
+	MOVL 0(DX), AX
+	MOVL 4(DX), BX
+	ADDL 0(SI), AX
+	ADDL 4(SI), BX
+
+	ROUND(8, 12)
+	ROUND(16, 20)
+	ROUND(24, 28)
+	ROUND(32, 36)
+	ROUND(40, 44)
+	ROUND(48, 52)
+	ROUND(56, 60)
+	ROUND(64, 68)
+	ROUND(72, 76)
+	ROUND(80, 84)
+	ROUND(88, 92)
+	ROUND(96, 100)
+
+	MOVL AX, 0(DI)
+	MOVL BX, 4(DI)
+	RET
+
+// func decrypt12(rk *uint32, dst, src *byte)
+TEXT ·decrypt12(SB), NOSPLIT, $0-24
+	MOVQ rk+0(FP), SI
+	MOVQ dst+8(FP), DI
+	MOVQ src+16(FP), DX
+
+	MOVL 0(DX), AX
+	MOVL 4(DX), BX
+
+	UNROUND(96, 100)
+	UNROUND(88, 92)
+	UNROUND(80, 84)
+	UNROUND(72, 76)
+	UNROUND(64, 68)
+	UNROUND(56, 60)
+	UNROUND(48, 52)
+	UNROUND(40, 44)
+	UNROUND(32, 36)
+	UNROUND(24, 28)
+	UNROUND(16, 20)
+	UNROUND(8, 12)
+
+	SUBL 4(SI), BX
+	SUBL 0(SI), AX
+	MOVL AX, 0(DI)
+	MOVL BX, 4(DI)
+	RET
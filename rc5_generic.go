@@ -0,0 +1,9 @@
+//go:build !amd64 || purego
+
+package rc5
+
+const haveAsm = false
+
+func encrypt12(rk *uint32, dst, src *byte) { panic("rc5: no assembly implementation") }
+
+func decrypt12(rk *uint32, dst, src *byte) { panic("rc5: no assembly implementation") }
@@ -2,6 +2,16 @@ package rc5
 
 import (
 	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"slices"
+	"sync"
 	"testing"
 )
 
@@ -56,3 +66,1497 @@ func TestRC5(t *testing.T) {
 		}
 	}
 }
+
+func TestNewWithParameters(t *testing.T) {
+
+	key := tests[0].key
+
+	for _, w := range []int{16, 32, 64} {
+		c, err := NewWithParameters(w, 12, key)
+		if err != nil {
+			t.Fatalf("NewWithParameters(%d, 12) failed: %v", w, err)
+		}
+		if got := c.BlockSize(); got != 2*w/8 {
+			t.Errorf("NewWithParameters(%d, 12).BlockSize()=%d, want %d", w, got, 2*w/8)
+		}
+	}
+
+	for _, tst := range []struct {
+		w, r int
+		err  ParameterError
+	}{
+		{8, 12, ParameterError{"word size", 8}},
+		{24, 12, ParameterError{"word size", 24}},
+		{128, 12, ParameterError{"word size", 128}},
+		{32, -1, ParameterError{"rounds", -1}},
+		{32, 256, ParameterError{"rounds", 256}},
+	} {
+		_, err := NewWithParameters(tst.w, tst.r, key)
+		perr, ok := err.(ParameterError)
+		if !ok {
+			t.Errorf("NewWithParameters(%d, %d): got error %v, want ParameterError", tst.w, tst.r, err)
+			continue
+		}
+		if perr != tst.err {
+			t.Errorf("NewWithParameters(%d, %d): got %+v, want %+v", tst.w, tst.r, perr, tst.err)
+		}
+	}
+}
+
+func TestNewWithRounds(t *testing.T) {
+
+	key := tests[0].key
+
+	c, _ := New(key)
+	c12, err := NewWithRounds(12, key)
+	if err != nil {
+		t.Fatalf("NewWithRounds(12) failed: %v", err)
+	}
+
+	var want, got [8]byte
+	c.Encrypt(want[:], tests[0].plain)
+	c12.Encrypt(got[:], tests[0].plain)
+	if got != want {
+		t.Errorf("NewWithRounds(12) differs from New:\ngot : % 02x\nwant: % 02x", got[:], want[:])
+	}
+
+	for _, r := range []int{0, 1, 16, 20, 255} {
+		c, err := NewWithRounds(r, key)
+		if err != nil {
+			t.Fatalf("NewWithRounds(%d) failed: %v", r, err)
+		}
+
+		var ct, p [8]byte
+		c.Encrypt(ct[:], tests[0].plain)
+		c.Decrypt(p[:], ct[:])
+		if !bytes.Equal(p[:], tests[0].plain) {
+			t.Errorf("NewWithRounds(%d) round trip failed:\ngot : % 02x\nwant: % 02x", r, p[:], tests[0].plain)
+		}
+	}
+
+	for _, r := range []int{-1, 256, 1000} {
+		if _, err := NewWithRounds(r, key); err == nil {
+			t.Errorf("NewWithRounds(%d) succeeded, want error", r)
+		}
+	}
+}
+
+func TestRC5_64RoundTrip(t *testing.T) {
+
+	key := make([]byte, 16)
+	rand.Read(key)
+
+	c, err := NewWithParameters(64, 16, key)
+	if err != nil {
+		t.Fatalf("NewWithParameters(64, 16) failed: %v", err)
+	}
+
+	if bs := c.BlockSize(); bs != 16 {
+		t.Fatalf("BlockSize()=%d, want 16", bs)
+	}
+
+	for i := 0; i < 1000; i++ {
+		var plain, ct, p [16]byte
+		rand.Read(plain[:])
+
+		c.Encrypt(ct[:], plain[:])
+		if ct == plain {
+			t.Errorf("encrypt left block unchanged: % 02x", plain[:])
+		}
+
+		c.Decrypt(p[:], ct[:])
+		if p != plain {
+			t.Errorf("round trip failed:\ngot : % 02x\nwant: % 02x", p[:], plain[:])
+		}
+	}
+}
+
+var parameterTests = []struct {
+	w, r   int
+	key    []byte
+	plain  []byte
+	cipher []byte
+}{
+	// draft-krovetz-rc6-rc5-vectors-00
+	{
+		16, 16,
+		[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+		[]byte{0x00, 0x01, 0x02, 0x03},
+		[]byte{0x23, 0xA8, 0xD7, 0x2E},
+	},
+	{
+		32, 20,
+		[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F},
+		[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+		[]byte{0x2A, 0x0E, 0xDC, 0x0E, 0x94, 0x31, 0xFF, 0x73},
+	},
+	{
+		64, 24,
+		[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F, 0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17},
+		[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F},
+		[]byte{0xA4, 0x67, 0x72, 0x82, 0x0E, 0xDB, 0xCE, 0x02, 0x35, 0xAB, 0xEA, 0x32, 0xAE, 0x71, 0x78, 0xDA},
+	},
+
+	// computed with the reference code from Rivest's RC5 paper
+	{
+		32, 12,
+		[]byte{},
+		[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		[]byte{0xEB, 0xFD, 0x9C, 0x10, 0x05, 0x43, 0xC6, 0x25},
+	},
+	{
+		32, 12,
+		[]byte{0x01, 0x02, 0x03, 0x04, 0x05},
+		[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		[]byte{0x62, 0xF1, 0x57, 0x0B, 0xF8, 0x72, 0xF5, 0xBC},
+	},
+	{
+		32, 12,
+		[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+		[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+		[]byte{0x04, 0xF6, 0xB9, 0xB1, 0x8E, 0x68, 0x28, 0xC1},
+	},
+	{
+		32, 12,
+		[]byte{
+			0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F,
+			0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1A, 0x1B, 0x1C, 0x1D, 0x1E, 0x1F,
+		},
+		[]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07},
+		[]byte{0xB2, 0x9C, 0x08, 0x0C, 0x3F, 0x94, 0x5C, 0x24},
+	},
+	{
+		16, 12,
+		[]byte{0x01, 0x02, 0x03},
+		[]byte{0x00, 0x00, 0x00, 0x00},
+		[]byte{0x55, 0xC4, 0x01, 0x37},
+	},
+	{
+		64, 12,
+		[]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A},
+		[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		[]byte{0x6F, 0x20, 0xAC, 0x58, 0xE2, 0x82, 0xD2, 0x9B, 0x8E, 0x14, 0x40, 0x34, 0x3D, 0x38, 0xEA, 0x45},
+	},
+}
+
+func TestRC5Parameters(t *testing.T) {
+
+	for _, tst := range parameterTests {
+
+		c, err := NewWithParameters(tst.w, tst.r, tst.key)
+		if err != nil {
+			t.Fatalf("NewWithParameters(%d, %d) failed: %v", tst.w, tst.r, err)
+		}
+
+		ct := make([]byte, c.BlockSize())
+
+		c.Encrypt(ct, tst.plain)
+
+		if !bytes.Equal(ct, tst.cipher) {
+			t.Errorf("RC5-%d/%d/%d encrypt failed:\ngot : % 02x\nwant: % 02x", tst.w, tst.r, len(tst.key), ct, tst.cipher)
+		}
+
+		p := make([]byte, c.BlockSize())
+
+		c.Decrypt(p, ct)
+
+		if !bytes.Equal(p, tst.plain) {
+			t.Errorf("RC5-%d/%d/%d decrypt failed:\ngot : % 02x\nwant: % 02x", tst.w, tst.r, len(tst.key), p, tst.plain)
+		}
+	}
+}
+
+func TestKeySize(t *testing.T) {
+
+	for _, l := range []int{0, 1, 8, 16, 32, 255} {
+		if _, err := NewWithParameters(32, 12, make([]byte, l)); err != nil {
+			t.Errorf("NewWithParameters(32, 12) with %d byte key failed: %v", l, err)
+		}
+	}
+
+	for _, l := range []int{256, 1024} {
+		_, err := NewWithParameters(32, 12, make([]byte, l))
+		if !errors.Is(err, KeySizeError(l)) {
+			t.Errorf("NewWithParameters(32, 12) with %d byte key: got error %v, want KeySizeError(%d)", l, err, l)
+		}
+	}
+}
+
+// skeytable is the precomputed RC5-32/12 key-expansion table
+var skeytable = []uint32{
+	0xb7e15163, 0x5618cb1c, 0xf45044d5, 0x9287be8e, 0x30bf3847, 0xcef6b200, 0x6d2e2bb9, 0x0b65a572,
+	0xa99d1f2b, 0x47d498e4, 0xe60c129d, 0x84438c56, 0x227b060f, 0xc0b27fc8, 0x5ee9f981, 0xfd21733a,
+	0x9b58ecf3, 0x399066ac, 0xd7c7e065, 0x75ff5a1e, 0x1436d3d7, 0xb26e4d90, 0x50a5c749, 0xeedd4102,
+	0x8d14babb, 0x2b4c3474,
+}
+
+func TestInitTable(t *testing.T) {
+
+	rk := make([]uint32, 2*(12+1))
+	initTable(rk, p32, q32)
+
+	for i := range skeytable {
+		if rk[i] != skeytable[i] {
+			t.Errorf("initTable()[%d]=%08x, want %08x", i, rk[i], skeytable[i])
+		}
+	}
+}
+
+func TestBlockBounds(t *testing.T) {
+
+	for _, w := range []int{16, 32, 64} {
+
+		c, _ := NewWithParameters(w, 12, tests[0].key)
+		bs := c.BlockSize()
+
+		for _, tst := range []struct {
+			dst, src []byte
+			msg      string
+		}{
+			{make([]byte, bs), make([]byte, bs-1), "rc5: input not full block"},
+			{make([]byte, bs-1), make([]byte, bs), "rc5: output smaller than block"},
+			{make([]byte, 1), make([]byte, bs), "rc5: output smaller than block"},
+		} {
+			for _, f := range []struct {
+				name string
+				fn   func(dst, src []byte)
+			}{
+				{"Encrypt", c.Encrypt},
+				{"Decrypt", c.Decrypt},
+			} {
+				for i := range tst.dst {
+					tst.dst[i] = 0xAA
+				}
+
+				func() {
+					defer func() {
+						if r := recover(); r != tst.msg {
+							t.Errorf("RC5-%d %s(len(dst)=%d, len(src)=%d): got panic %v, want %q", w, f.name, len(tst.dst), len(tst.src), r, tst.msg)
+						}
+					}()
+					f.fn(tst.dst, tst.src)
+				}()
+
+				// the panic happens before anything is written
+				for i, b := range tst.dst {
+					if b != 0xAA {
+						t.Errorf("RC5-%d %s(len(dst)=%d, len(src)=%d) wrote dst[%d] before panicking", w, f.name, len(tst.dst), len(tst.src), i)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestInPlace(t *testing.T) {
+
+	for _, tst := range tests {
+
+		c, _ := New(tst.key)
+
+		buf := append([]byte(nil), tst.plain...)
+
+		c.Encrypt(buf, buf)
+		if !bytes.Equal(buf, tst.cipher) {
+			t.Errorf("in-place encrypt failed:\ngot : % 02x\nwant: % 02x", buf, tst.cipher)
+		}
+
+		c.Decrypt(buf, buf)
+		if !bytes.Equal(buf, tst.plain) {
+			t.Errorf("in-place decrypt failed:\ngot : % 02x\nwant: % 02x", buf, tst.plain)
+		}
+	}
+}
+
+func TestInexactOverlap(t *testing.T) {
+
+	c, _ := New(tests[0].key)
+
+	buf := make([]byte, 12)
+
+	for _, f := range []struct {
+		name string
+		fn   func(dst, src []byte)
+	}{
+		{"Encrypt", c.Encrypt},
+		{"Decrypt", c.Decrypt},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != "rc5: invalid buffer overlap" {
+					t.Errorf("%s with overlapping buffers: got panic %v", f.name, r)
+				}
+			}()
+			f.fn(buf[2:10], buf[:8])
+		}()
+	}
+}
+
+func TestWipe(t *testing.T) {
+
+	for _, w := range []int{16, 32, 64} {
+
+		b, _ := NewWithParameters(w, 12, tests[0].key)
+
+		wp, ok := b.(Wiper)
+		if !ok {
+			t.Fatalf("RC5-%d cipher does not implement Wiper", w)
+		}
+
+		wp.Wipe()
+		wp.Wipe()
+
+		c := b.(*Cipher)
+		for _, v := range c.rk16 {
+			if v != 0 {
+				t.Errorf("RC5-%d schedule not zero after Wipe: %v", w, c.rk16)
+				break
+			}
+		}
+		for _, v := range c.rk32 {
+			if v != 0 {
+				t.Errorf("RC5-%d schedule not zero after Wipe: %v", w, c.rk32)
+				break
+			}
+		}
+		for _, v := range c.rk64 {
+			if v != 0 {
+				t.Errorf("RC5-%d schedule not zero after Wipe: %v", w, c.rk64)
+				break
+			}
+		}
+
+		func() {
+			defer func() {
+				if r := recover(); r != "rc5: use of wiped cipher" {
+					t.Errorf("RC5-%d Encrypt after Wipe: got panic %v", w, r)
+				}
+			}()
+			buf := make([]byte, b.BlockSize())
+			b.Encrypt(buf, buf)
+		}()
+	}
+}
+
+func TestNewWithByteOrder(t *testing.T) {
+
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
+	plain := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77}
+
+	for _, tst := range []struct {
+		order  binary.ByteOrder
+		cipher []byte
+	}{
+		{binary.LittleEndian, []byte{0x2D, 0xDC, 0x14, 0x9B, 0xCF, 0x08, 0x8B, 0x9E}},
+		// computed with the reference code, loading and storing words big-endian
+		{binary.BigEndian, []byte{0x65, 0xF9, 0x20, 0x3B, 0x76, 0x6F, 0x90, 0x2C}},
+	} {
+		c, err := NewWithByteOrder(tst.order, key)
+		if err != nil {
+			t.Fatalf("NewWithByteOrder(%v) failed: %v", tst.order, err)
+		}
+
+		var ct, p [8]byte
+
+		c.Encrypt(ct[:], plain)
+		if !bytes.Equal(ct[:], tst.cipher) {
+			t.Errorf("%v encrypt failed:\ngot : % 02x\nwant: % 02x", tst.order, ct[:], tst.cipher)
+		}
+
+		c.Decrypt(p[:], ct[:])
+		if !bytes.Equal(p[:], plain) {
+			t.Errorf("%v decrypt failed:\ngot : % 02x\nwant: % 02x", tst.order, p[:], plain)
+		}
+	}
+
+	if _, err := NewWithByteOrder(swappedOrder{}, key); err != errByteOrder {
+		t.Errorf("NewWithByteOrder(swappedOrder): got %v, want %v", err, errByteOrder)
+	}
+}
+
+// swappedOrder is neither little- nor big-endian
+type swappedOrder struct{ binary.ByteOrder }
+
+func (swappedOrder) Uint32(b []byte) uint32 {
+	return uint32(b[1]) | uint32(b[0])<<8 | uint32(b[3])<<16 | uint32(b[2])<<24
+}
+
+func TestRekey(t *testing.T) {
+
+	for _, tst := range parameterTests {
+
+		b, _ := NewWithParameters(tst.w, tst.r, make([]byte, 3))
+		c := b.(*Cipher)
+
+		if err := c.Rekey(tst.key); err != nil {
+			t.Fatalf("Rekey failed: %v", err)
+		}
+
+		ct := make([]byte, c.BlockSize())
+		c.Encrypt(ct, tst.plain)
+
+		if !bytes.Equal(ct, tst.cipher) {
+			t.Errorf("RC5-%d/%d/%d encrypt after Rekey failed:\ngot : % 02x\nwant: % 02x", tst.w, tst.r, len(tst.key), ct, tst.cipher)
+		}
+	}
+
+	b, _ := New(tests[0].key)
+	c := b.(*Cipher)
+	rk := &c.rk32[0]
+
+	c.Wipe()
+
+	if err := c.Rekey(tests[1].key); err != nil {
+		t.Fatalf("Rekey failed: %v", err)
+	}
+
+	if &c.rk32[0] != rk {
+		t.Errorf("Rekey allocated a new key schedule")
+	}
+
+	var ct [8]byte
+	c.Encrypt(ct[:], tests[1].plain)
+	if !bytes.Equal(ct[:], tests[1].cipher) {
+		t.Errorf("encrypt after Wipe and Rekey failed:\ngot : % 02x\nwant: % 02x", ct[:], tests[1].cipher)
+	}
+
+	if err := c.Rekey(make([]byte, 256)); !errors.Is(err, KeySizeError(256)) {
+		t.Errorf("Rekey with 256 byte key: got %v, want KeySizeError(256)", err)
+	}
+}
+
+func BenchmarkEncrypt(b *testing.B) {
+	c, _ := New(tests[0].key)
+	var buf [8]byte
+	b.SetBytes(8)
+	for i := 0; i < b.N; i++ {
+		c.Encrypt(buf[:], buf[:])
+	}
+}
+
+func BenchmarkDecrypt(b *testing.B) {
+	c, _ := New(tests[0].key)
+	var buf [8]byte
+	b.SetBytes(8)
+	for i := 0; i < b.N; i++ {
+		c.Decrypt(buf[:], buf[:])
+	}
+}
+
+// The parameter benchmarks cover every combination of these word sizes and
+// round counts, as sub-benchmarks named RC5-w-r, so one can be selected with,
+// for example, -bench EncryptParameters/RC5-64-16.
+var (
+	benchWordSizes = []int{16, 32, 64}
+	benchRounds    = []int{8, 12, 16, 20}
+)
+
+func BenchmarkEncryptParameters(b *testing.B) {
+	for _, w := range benchWordSizes {
+		for _, r := range benchRounds {
+			c, _ := NewWithParameters(w, r, tests[0].key)
+			buf := make([]byte, c.BlockSize())
+			b.Run(fmt.Sprintf("RC5-%d-%d", w, r), func(b *testing.B) {
+				b.SetBytes(int64(len(buf)))
+				for i := 0; i < b.N; i++ {
+					c.Encrypt(buf, buf)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkDecryptParameters(b *testing.B) {
+	for _, w := range benchWordSizes {
+		for _, r := range benchRounds {
+			c, _ := NewWithParameters(w, r, tests[0].key)
+			buf := make([]byte, c.BlockSize())
+			b.Run(fmt.Sprintf("RC5-%d-%d", w, r), func(b *testing.B) {
+				b.SetBytes(int64(len(buf)))
+				for i := 0; i < b.N; i++ {
+					c.Decrypt(buf, buf)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	key := tests[0].key
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		New(key)
+	}
+}
+
+func TestClone(t *testing.T) {
+
+	for _, tst := range parameterTests {
+
+		b, _ := NewWithParameters(tst.w, tst.r, tst.key)
+		c := b.(*Cipher)
+		clone := c.Clone()
+
+		c.Wipe()
+
+		ct := make([]byte, clone.BlockSize())
+		clone.Encrypt(ct, tst.plain)
+
+		if !bytes.Equal(ct, tst.cipher) {
+			t.Errorf("RC5-%d/%d/%d clone encrypt failed:\ngot : % 02x\nwant: % 02x", tst.w, tst.r, len(tst.key), ct, tst.cipher)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+
+	c, _ := New(tests[0].key)
+	if s := c.(*Cipher).String(); s != "RC5-32/12/16" {
+		t.Errorf("New().String()=%q, want %q", s, "RC5-32/12/16")
+	}
+
+	c, _ = NewWithParameters(64, 24, make([]byte, 24))
+	if s := c.(*Cipher).String(); s != "RC5-64/24/24" {
+		t.Errorf("NewWithParameters(64, 24).String()=%q, want %q", s, "RC5-64/24/24")
+	}
+
+	c.(*Cipher).Rekey(make([]byte, 5))
+	if s := c.(*Cipher).String(); s != "RC5-64/24/5" {
+		t.Errorf("String() after Rekey=%q, want %q", s, "RC5-64/24/5")
+	}
+}
+
+func TestNewCipher(t *testing.T) {
+
+	// NewCipher fits the same factory type as the standard library ciphers
+	var factory func([]byte) (cipher.Block, error) = NewCipher
+
+	for _, tst := range tests {
+
+		c, err := factory(tst.key)
+		if err != nil {
+			t.Fatalf("NewCipher failed: %v", err)
+		}
+
+		var ct [8]byte
+		c.Encrypt(ct[:], tst.plain)
+		if !bytes.Equal(ct[:], tst.cipher) {
+			t.Errorf("NewCipher encrypt failed:\ngot : % 02x\nwant: % 02x", ct[:], tst.cipher)
+		}
+	}
+}
+
+func TestNewWithConstants(t *testing.T) {
+
+	for _, tst := range tests {
+
+		c, err := NewWithConstants(0xb7e15163, 0x9e3779b9, tst.key)
+		if err != nil {
+			t.Fatalf("NewWithConstants failed: %v", err)
+		}
+
+		var ct [8]byte
+		c.Encrypt(ct[:], tst.plain)
+		if !bytes.Equal(ct[:], tst.cipher) {
+			t.Errorf("NewWithConstants with standard constants:\ngot : % 02x\nwant: % 02x", ct[:], tst.cipher)
+		}
+
+		c, _ = NewWithConstants(0xb7e15163, 0x9e3779bb, tst.key)
+		c.Encrypt(ct[:], tst.plain)
+		if bytes.Equal(ct[:], tst.cipher) {
+			t.Errorf("NewWithConstants with custom constants matches standard RC5")
+		}
+
+		// Rekey keeps the custom constants
+		c.(*Cipher).Rekey(tst.key)
+		var ct2 [8]byte
+		c.Encrypt(ct2[:], tst.plain)
+		if ct2 != ct {
+			t.Errorf("Rekey lost the custom constants:\ngot : % 02x\nwant: % 02x", ct2[:], ct[:])
+		}
+	}
+
+	if _, err := NewWithConstants(0, 0, make([]byte, 15)); !errors.Is(err, KeySizeError(15)) {
+		t.Errorf("NewWithConstants with 15 byte key: got %v, want KeySizeError(15)", err)
+	}
+}
+
+func TestNewStrengthened(t *testing.T) {
+
+	for _, tst := range tests {
+
+		c, err := NewStrengthened(tst.key, 0)
+		if err != nil {
+			t.Fatalf("NewStrengthened failed: %v", err)
+		}
+
+		var ct [8]byte
+		c.Encrypt(ct[:], tst.plain)
+		if !bytes.Equal(ct[:], tst.cipher) {
+			t.Errorf("NewStrengthened with no extra passes:\ngot : % 02x\nwant: % 02x", ct[:], tst.cipher)
+		}
+
+		seen := map[[8]byte]int{ct: 0}
+		for _, extra := range []int{1, 2, 10} {
+
+			c, _ := NewStrengthened(tst.key, extra)
+			c.Encrypt(ct[:], tst.plain)
+			if prev, ok := seen[ct]; ok {
+				t.Errorf("NewStrengthened with %d and %d extra passes both give % 02x", extra, prev, ct[:])
+			}
+			seen[ct] = extra
+
+			var pt [8]byte
+			c.Decrypt(pt[:], ct[:])
+			if !bytes.Equal(pt[:], tst.plain) {
+				t.Errorf("NewStrengthened(%d) decrypt:\ngot : % 02x\nwant: % 02x", extra, pt[:], tst.plain)
+			}
+
+			// Rekey keeps the extra passes
+			c.(*Cipher).Rekey(tst.key)
+			var ct2 [8]byte
+			c.Encrypt(ct2[:], tst.plain)
+			if ct2 != ct {
+				t.Errorf("Rekey lost the extra passes:\ngot : % 02x\nwant: % 02x", ct2[:], ct[:])
+			}
+		}
+	}
+
+	for _, extra := range []int{-1, maxExtraPasses + 1} {
+		if _, err := NewStrengthened(tests[0].key, extra); err != (ParameterError{"extra passes", extra}) {
+			t.Errorf("NewStrengthened(%d): got %v, want ParameterError", extra, err)
+		}
+	}
+
+	if _, err := NewStrengthened(make([]byte, 15), 1); !errors.Is(err, KeySizeError(15)) {
+		t.Errorf("NewStrengthened with 15 byte key: got %v, want KeySizeError(15)", err)
+	}
+}
+
+func TestKeyScheduleOps(t *testing.T) {
+
+	for _, tst := range []struct {
+		w, r, b int
+		want    int
+	}{
+		{32, 12, 16, 78}, // 3 * 26 round keys
+		{32, 12, 0, 78},
+		{32, 0, 16, 12},   // 3 * 4 key words
+		{32, 0, 255, 192}, // 3 * 64 key words
+		{16, 0, 255, 384}, // 3 * 128 key words
+		{16, 16, 10, 102},
+		{64, 24, 255, 150},
+		{64, 12, 255, 96},
+		{8, 12, 16, 0},
+		{32, 256, 16, 0},
+		{32, 12, 256, 0},
+		{32, 12, -1, 0},
+	} {
+		if got := KeyScheduleOps(tst.w, tst.r, tst.b); got != tst.want {
+			t.Errorf("KeyScheduleOps(%d, %d, %d)=%d, want %d", tst.w, tst.r, tst.b, got, tst.want)
+		}
+	}
+}
+
+func TestNewStrict(t *testing.T) {
+
+	key := make([]byte, 16)
+	rand.Read(key)
+
+	c, err := NewStrict(key)
+	if err != nil {
+		t.Fatalf("NewStrict with random key failed: %v", err)
+	}
+
+	want, _ := New(key)
+	var got, exp [8]byte
+	c.Encrypt(got[:], tests[0].plain)
+	want.Encrypt(exp[:], tests[0].plain)
+	if got != exp {
+		t.Errorf("NewStrict differs from New:\ngot : % 02x\nwant: % 02x", got[:], exp[:])
+	}
+
+	for _, key := range [][]byte{
+		make([]byte, 16),
+		bytes.Repeat([]byte{0xa5}, 16),
+	} {
+		if _, err := NewStrict(key); err != errWeakKey {
+			t.Errorf("NewStrict(% 02x): got %v, want %v", key, err, errWeakKey)
+		}
+
+		// New remains permissive
+		if _, err := New(key); err != nil {
+			t.Errorf("New(% 02x) failed: %v", key, err)
+		}
+	}
+
+	if _, err := NewStrict(make([]byte, 8)); !errors.Is(err, KeySizeError(8)) {
+		t.Errorf("NewStrict with 8 byte key: got %v, want KeySizeError(8)", err)
+	}
+}
+
+func TestAccessors(t *testing.T) {
+
+	for _, tst := range []struct {
+		w, r, b int
+	}{
+		{32, 12, 16},
+		{16, 16, 8},
+		{64, 24, 24},
+		{32, 0, 0},
+	} {
+		b, _ := NewWithParameters(tst.w, tst.r, make([]byte, tst.b))
+		c := b.(*Cipher)
+
+		if c.WordSize() != tst.w || c.Rounds() != tst.r || c.KeyLength() != tst.b {
+			t.Errorf("RC5-%d/%d/%d: got WordSize=%d Rounds=%d KeyLength=%d", tst.w, tst.r, tst.b, c.WordSize(), c.Rounds(), c.KeyLength())
+		}
+	}
+
+	b, _ := New(tests[0].key)
+	c := b.(*Cipher)
+	if c.WordSize() != 32 || c.Rounds() != 12 || c.KeyLength() != 16 {
+		t.Errorf("New: got WordSize=%d Rounds=%d KeyLength=%d, want 32, 12, 16", c.WordSize(), c.Rounds(), c.KeyLength())
+	}
+}
+
+func TestPresets(t *testing.T) {
+
+	for _, tst := range []struct {
+		name   string
+		new    func([]byte) (cipher.Block, error)
+		rounds int
+	}{
+		{"New32_16", New32_16, 16},
+		{"New32_20", New32_20, 20},
+	} {
+		b, err := tst.new(tests[0].key)
+		if err != nil {
+			t.Fatalf("%s failed: %v", tst.name, err)
+		}
+
+		if c := b.(*Cipher); c.Rounds() != tst.rounds || c.WordSize() != 32 || c.KeyLength() != 16 {
+			t.Errorf("%s: got %s, want RC5-32/%d/16", tst.name, c, tst.rounds)
+		}
+
+		if _, err := tst.new(make([]byte, 8)); !errors.Is(err, KeySizeError(8)) {
+			t.Errorf("%s with 8 byte key: got %v, want KeySizeError(8)", tst.name, err)
+		}
+	}
+}
+
+func TestNew192And256(t *testing.T) {
+
+	for _, tst := range []struct {
+		name   string
+		new    func([]byte) (cipher.Block, error)
+		keyLen int
+		cipher string // encryption of the zero block under key 00 01 02 ...
+	}{
+		{"New192", New192, 24, "bc6caf856ef75b6c"},
+		{"New256", New256, 32, "630d65359d7b371a"},
+	} {
+		b, err := tst.new(seq(0, tst.keyLen))
+		if err != nil {
+			t.Fatalf("%s failed: %v", tst.name, err)
+		}
+
+		if c := b.(*Cipher); c.Rounds() != 12 || c.WordSize() != 32 || c.KeyLength() != tst.keyLen {
+			t.Errorf("%s: got %s, want RC5-32/12/%d", tst.name, c, tst.keyLen)
+		}
+
+		want, _ := hex.DecodeString(tst.cipher)
+		ct := make([]byte, 8)
+		b.Encrypt(ct, ct)
+		if !bytes.Equal(ct, want) {
+			t.Errorf("%s:\ngot : % 02x\nwant: % 02x", tst.name, ct, want)
+		}
+
+		for _, l := range []int{16, tst.keyLen - 1, tst.keyLen + 1} {
+			if _, err := tst.new(make([]byte, l)); !errors.Is(err, KeySizeError(l)) {
+				t.Errorf("%s with %d byte key: got %v, want KeySizeError(%d)", tst.name, l, err, l)
+			}
+		}
+	}
+}
+
+// zeroKeySchedule is the RC5-32/12/16 key schedule for the all-zero key
+var zeroKeySchedule = []uint64{
+	0x9bbbd8c8, 0x1a37f7fb, 0x46f8e8c5, 0x460c6085, 0x70f83b8a, 0x284b8303, 0x513e1454, 0xf621ed22,
+	0x3125065d, 0x11a83a5d, 0xd427686b, 0x713ad82d, 0x4b792f99, 0x2799a4dd, 0xa7901c49, 0xdede871a,
+	0x36c03196, 0xa7efc249, 0x61a78bb8, 0x3b0a1d2b, 0x4dbfca76, 0xae162167, 0x30d76b0a, 0x43192304,
+	0xf6cc1431, 0x65046380,
+}
+
+func TestExpandKey(t *testing.T) {
+
+	rk, err := ExpandKey(32, 12, make([]byte, 16))
+	if err != nil {
+		t.Fatalf("ExpandKey failed: %v", err)
+	}
+
+	for i := range zeroKeySchedule {
+		if rk[i] != zeroKeySchedule[i] {
+			t.Errorf("ExpandKey(32, 12, zero key)[%d]=%08x, want %08x", i, rk[i], zeroKeySchedule[i])
+		}
+	}
+
+	// a 5-byte key fills one whole word and one byte of the next, computed
+	// with a reference implementation
+	want := []uint64{
+		0xfe901c32, 0x89fa5c4d, 0xda161963, 0xbf1f2a4d, 0x8f6e510f, 0x9c656707, 0xe41efede, 0x0aef35ce,
+		0x9bdf81cc, 0x182ea25a, 0xe37aec25, 0xbed3b060, 0xe38764bc, 0x21e79772, 0x86ab9188, 0x46b21761,
+		0x723d0d95, 0x0d89799a, 0x50c1edc8, 0x5cb6e5ac, 0x557bb7c6, 0x65ab5fa8, 0x5da25914, 0x67ca96e0,
+		0xee6375f1, 0x2116d6f2,
+	}
+
+	rk, _ = ExpandKey(32, 12, []byte{1, 2, 3, 4, 5})
+	for i := range want {
+		if rk[i] != want[i] {
+			t.Errorf("ExpandKey(32, 12, 5 byte key)[%d]=%08x, want %08x", i, rk[i], want[i])
+		}
+	}
+
+	// other word sizes should match the cipher's own schedule
+	for _, tst := range parameterTests {
+
+		rk, err := ExpandKey(tst.w, tst.r, tst.key)
+		if err != nil {
+			t.Fatalf("ExpandKey(%d, %d) failed: %v", tst.w, tst.r, err)
+		}
+
+		b, _ := NewWithParameters(tst.w, tst.r, tst.key)
+		c := b.(*Cipher)
+
+		var want []uint64
+		switch tst.w {
+		case 16:
+			for _, k := range c.rk16 {
+				want = append(want, uint64(k))
+			}
+		case 32:
+			for _, k := range c.rk32 {
+				want = append(want, uint64(k))
+			}
+		case 64:
+			want = c.rk64
+		}
+
+		if len(rk) != len(want) {
+			t.Fatalf("ExpandKey(%d, %d): got %d round keys, want %d", tst.w, tst.r, len(rk), len(want))
+		}
+
+		for i := range want {
+			if rk[i] != want[i] {
+				t.Errorf("ExpandKey(%d, %d)[%d]=%x, want %x", tst.w, tst.r, i, rk[i], want[i])
+			}
+		}
+	}
+
+	if _, err := ExpandKey(24, 12, nil); err != (ParameterError{"word size", 24}) {
+		t.Errorf("ExpandKey(24, 12): got %v, want word size error", err)
+	}
+}
+
+func TestExpandKeyTrace(t *testing.T) {
+
+	for _, tst := range []struct {
+		w, r   int
+		keyLen int
+	}{
+		{32, 12, 16},
+		{16, 16, 8},
+		{64, 24, 24},
+		{32, 0, 255}, // more key words than round keys
+	} {
+		key := seq(1, tst.keyLen)
+
+		rk, trace, err := ExpandKeyTrace(tst.w, tst.r, key)
+		if err != nil {
+			t.Fatalf("ExpandKeyTrace(%d, %d) failed: %v", tst.w, tst.r, err)
+		}
+
+		want, _ := ExpandKey(tst.w, tst.r, key)
+		if !slices.Equal(rk, want) {
+			t.Errorf("ExpandKeyTrace(%d, %d) schedule:\ngot : %x\nwant: %x", tst.w, tst.r, rk, want)
+		}
+
+		roundKeys := 2 * (tst.r + 1)
+		keyWords := (tst.keyLen + tst.w/8 - 1) / (tst.w / 8)
+
+		if n := KeyScheduleOps(tst.w, tst.r, tst.keyLen); len(trace) != n {
+			t.Fatalf("ExpandKeyTrace(%d, %d): got %d steps, want %d", tst.w, tst.r, len(trace), n)
+		}
+
+		for k, step := range trace {
+			if step.RoundKey != k%roundKeys || step.KeyWord != k%keyWords {
+				t.Fatalf("ExpandKeyTrace(%d, %d) step %d updated S[%d] and L[%d], want S[%d] and L[%d]",
+					tst.w, tst.r, k, step.RoundKey, step.KeyWord, k%roundKeys, k%keyWords)
+			}
+		}
+
+		// the last update of each round key is its final value
+		for _, step := range trace[len(trace)-roundKeys:] {
+			if step.A != rk[step.RoundKey] {
+				t.Errorf("ExpandKeyTrace(%d, %d): last A for S[%d]=%x, want %x", tst.w, tst.r, step.RoundKey, step.A, rk[step.RoundKey])
+			}
+		}
+	}
+
+	// the mixing loop runs 3*roundKeys times for RC5-32/12/16
+	if _, trace, _ := ExpandKeyTrace(32, 12, make([]byte, 16)); len(trace) != 3*26 {
+		t.Errorf("ExpandKeyTrace(32, 12): got %d steps, want %d", len(trace), 3*26)
+	}
+
+	if _, _, err := ExpandKeyTrace(24, 12, nil); err != (ParameterError{"word size", 24}) {
+		t.Errorf("ExpandKeyTrace(24, 12): got %v, want word size error", err)
+	}
+}
+
+func FuzzRoundTrip(f *testing.F) {
+
+	for _, tst := range parameterTests {
+		f.Add(tst.key, tst.w, tst.r, tst.plain)
+	}
+	f.Add([]byte{}, 32, 0, make([]byte, 8))
+	f.Add(make([]byte, 255), 64, 255, make([]byte, 16))
+
+	f.Fuzz(func(t *testing.T, key []byte, wordSize, rounds int, block []byte) {
+
+		c, err := NewWithParameters(wordSize, rounds, key)
+		if err != nil {
+			t.Skip()
+		}
+
+		bs := c.BlockSize()
+		if len(block) < bs {
+			t.Skip()
+		}
+		block = block[:bs]
+
+		ct := make([]byte, bs)
+		c.Encrypt(ct, block)
+
+		pt := make([]byte, bs)
+		c.Decrypt(pt, ct)
+
+		if !bytes.Equal(pt, block) {
+			t.Errorf("RC5-%d/%d/%d round trip:\ngot : % 02x\nwant: % 02x", wordSize, rounds, len(key), pt, block)
+		}
+	})
+}
+
+func TestConstructionErrors(t *testing.T) {
+
+	// a key which would show up in any schedule built from it
+	sentinel := bytes.Repeat([]byte{0xA5}, 300)
+
+	for _, tst := range []struct {
+		name string
+		new  func() (cipher.Block, error)
+	}{
+		{"New", func() (cipher.Block, error) { return New(sentinel[:15]) }},
+		{"New(WithWordSize(24))", func() (cipher.Block, error) { return New(sentinel[:16], WithWordSize(24)) }},
+		{"NewWithRounds", func() (cipher.Block, error) { return NewWithRounds(256, sentinel[:16]) }},
+		{"NewWithParameters", func() (cipher.Block, error) { return NewWithParameters(32, 12, sentinel[:256]) }},
+		{"NewWithByteOrder", func() (cipher.Block, error) { return NewWithByteOrder(swappedOrder{}, sentinel[:16]) }},
+		{"NewWithConstants", func() (cipher.Block, error) { return NewWithConstants(0, 0, sentinel[:17]) }},
+		{"NewStrict", func() (cipher.Block, error) { return NewStrict(sentinel[:16]) }},
+	} {
+		b, err := tst.new()
+		if err == nil {
+			t.Errorf("%s succeeded", tst.name)
+		}
+		if b != nil {
+			t.Errorf("%s returned %v alongside error %v", tst.name, b, err)
+		}
+	}
+
+	// the validation comes before anything is allocated or expanded, so no
+	// cipher is built and the key expansion never copies the sentinel key
+	// into L or mixes it into a round key
+	for _, tst := range []struct {
+		p   params
+		key []byte
+	}{
+		{params{wordSize: 24, rounds: 12}, sentinel[:16]},
+		{params{wordSize: 32, rounds: 256}, sentinel[:16]},
+		{params{wordSize: 32, rounds: -1}, sentinel[:16]},
+		{params{wordSize: 32, rounds: 12}, sentinel[:256]},
+	} {
+		if c, err := newCipher(tst.p, tst.key); c != nil || err == nil {
+			t.Errorf("newCipher(%d, %d, %d byte key)=%v, %v, want nil and an error", tst.p.wordSize, tst.p.rounds, len(tst.key), c, err)
+		}
+
+		// each trace step records the L word updated, so an empty trace
+		// means L was never touched
+		var trace []TraceStep
+		schedule, err := expandKeyTrace(tst.p.wordSize, tst.p.rounds, tst.key, &trace)
+		if err == nil || schedule != nil || len(trace) != 0 {
+			t.Errorf("expandKeyTrace(%d, %d, %d byte key): %d round keys and %d steps alongside error %v", tst.p.wordSize, tst.p.rounds, len(tst.key), len(schedule), len(trace), err)
+		}
+	}
+
+	// while a valid expansion of the same key does record its steps
+	var trace []TraceStep
+	if _, err := expandKeyTrace(32, 12, sentinel[:16], &trace); err != nil || len(trace) == 0 {
+		t.Errorf("expandKeyTrace of a valid key: %d steps, %v", len(trace), err)
+	}
+
+	// a failed Rekey leaves the existing schedule untouched
+	b, _ := New(tests[0].key)
+	c := b.(*Cipher)
+	want := slices.Clone(c.rk32)
+
+	if err := c.Rekey(sentinel); !errors.Is(err, KeySizeError(len(sentinel))) {
+		t.Errorf("Rekey with %d byte key: got %v, want KeySizeError", len(sentinel), err)
+	}
+
+	if !slices.Equal(c.rk32, want) {
+		t.Errorf("failed Rekey modified the key schedule")
+	}
+}
+
+func TestConcurrentUse(t *testing.T) {
+
+	for _, tst := range parameterTests {
+
+		c, _ := NewWithParameters(tst.w, tst.r, tst.key)
+
+		var wg sync.WaitGroup
+		for g := 0; g < 16; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 200; i++ {
+					ct := make([]byte, len(tst.plain))
+					c.Encrypt(ct, tst.plain)
+					if !bytes.Equal(ct, tst.cipher) {
+						t.Errorf("RC5-%d/%d concurrent Encrypt:\ngot : % 02x\nwant: % 02x", tst.w, tst.r, ct, tst.cipher)
+						return
+					}
+					c.Decrypt(ct, ct)
+					if !bytes.Equal(ct, tst.plain) {
+						t.Errorf("RC5-%d/%d concurrent Decrypt:\ngot : % 02x\nwant: % 02x", tst.w, tst.r, ct, tst.plain)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+func TestSafe(t *testing.T) {
+
+	tst := tests[1]
+	b, _ := New(tst.key)
+	c := b.(*Cipher)
+
+	ct := make([]byte, 8)
+	if err := c.EncryptSafe(ct, tst.plain); err != nil {
+		t.Fatalf("EncryptSafe failed: %v", err)
+	}
+	if !bytes.Equal(ct, tst.cipher) {
+		t.Errorf("EncryptSafe:\ngot : % 02x\nwant: % 02x", ct, tst.cipher)
+	}
+
+	pt := make([]byte, 8)
+	if err := c.DecryptSafe(pt, ct); err != nil {
+		t.Fatalf("DecryptSafe failed: %v", err)
+	}
+	if !bytes.Equal(pt, tst.plain) {
+		t.Errorf("DecryptSafe:\ngot : % 02x\nwant: % 02x", pt, tst.plain)
+	}
+
+	buf := make([]byte, 9)
+
+	for _, e := range []struct {
+		dst, src []byte
+		err      error
+	}{
+		{make([]byte, 8), make([]byte, 7), errShortInput},
+		{make([]byte, 7), make([]byte, 8), errShortOutput},
+		{nil, nil, errShortInput},
+		{buf[1:], buf[:8], errOverlap},
+	} {
+		if err := c.EncryptSafe(e.dst, e.src); err != e.err {
+			t.Errorf("EncryptSafe(len(dst)=%d, len(src)=%d): got %v, want %v", len(e.dst), len(e.src), err, e.err)
+		}
+		if err := c.DecryptSafe(e.dst, e.src); err != e.err {
+			t.Errorf("DecryptSafe(len(dst)=%d, len(src)=%d): got %v, want %v", len(e.dst), len(e.src), err, e.err)
+		}
+	}
+
+	c.Wipe()
+	if err := c.EncryptSafe(ct, tst.plain); err != errUseWiped {
+		t.Errorf("EncryptSafe after Wipe: got %v, want %v", err, errUseWiped)
+	}
+	if err := c.DecryptSafe(pt, ct); err != errUseWiped {
+		t.Errorf("DecryptSafe after Wipe: got %v, want %v", err, errUseWiped)
+	}
+}
+
+// expandKeyModulo is the RC5-32 key schedule as written in the specification,
+// advancing i and j modulo the table sizes.
+func expandKeyModulo(rounds int, key []byte) []uint32 {
+
+	c := max(1, (len(key)+3)/4)
+	L := make([]uint32, c)
+	for i := len(key) - 1; i >= 0; i-- {
+		L[i/4] = L[i/4]<<8 + uint32(key[i])
+	}
+
+	t := 2 * (rounds + 1)
+	S := make([]uint32, t)
+	S[0] = p32
+	for i := 1; i < t; i++ {
+		S[i] = S[i-1] + q32
+	}
+
+	var A, B uint32
+	var i, j int
+	for k := 0; k < 3*max(t, c); k++ {
+		S[i] = bits.RotateLeft32(S[i]+A+B, 3)
+		A = S[i]
+		L[j] = bits.RotateLeft32(L[j]+A+B, int(A+B))
+		B = L[j]
+		i = (i + 1) % t
+		j = (j + 1) % c
+	}
+
+	return S
+}
+
+func TestExpandKeySchedule(t *testing.T) {
+
+	for _, rounds := range []int{0, 1, 12, 20, 255} {
+		for _, l := range []int{0, 1, 4, 5, 16, 31, 255} {
+
+			key := make([]byte, l)
+			rand.Read(key)
+
+			b, _ := NewWithParameters(32, rounds, key)
+			got := b.(*Cipher).rk32
+			want := expandKeyModulo(rounds, key)
+
+			if !slices.Equal(got, want) {
+				t.Errorf("RC5-32/%d/%d schedule differs from the specification:\ngot : %08x\nwant: %08x", rounds, l, got, want)
+			}
+		}
+	}
+}
+
+func TestMagicConstants(t *testing.T) {
+
+	// Pw = Odd((e-2) * 2**w) and Qw = Odd((phi-1) * 2**w), where Odd(x) is
+	// the odd integer nearest to x
+	e, _ := new(big.Float).SetPrec(256).SetString("2.71828182845904523536028747135266249775724709369995957496696762772407663")
+	phi, _ := new(big.Float).SetPrec(256).SetString("1.61803398874989484820458683436563811772030917980576286213544862270526046")
+
+	odd := func(x *big.Float, w int) uint64 {
+		x = new(big.Float).SetPrec(256).SetMantExp(x, w)
+		n, _ := x.Int(nil)
+		if n.Bit(0) == 0 {
+			n.Add(n, big.NewInt(1))
+		}
+		return n.Uint64()
+	}
+
+	frac := func(x *big.Float, n int64) *big.Float {
+		return new(big.Float).SetPrec(256).Sub(x, big.NewFloat(float64(n)))
+	}
+
+	for _, tst := range []struct {
+		w      int
+		pw, qw uint64
+	}{
+		{16, 0xb7e1, 0x9e37},
+		{32, 0xb7e15163, 0x9e3779b9},
+		{64, 0xb7e151628aed2a6b, 0x9e3779b97f4a7c15},
+	} {
+		pw, qw := magicConstants(tst.w)
+		if pw != tst.pw || qw != tst.qw {
+			t.Errorf("magicConstants(%d)=%x, %x, want %x, %x", tst.w, pw, qw, tst.pw, tst.qw)
+		}
+
+		if p, q := odd(frac(e, 2), tst.w), odd(frac(phi, 1), tst.w); pw != p || qw != q {
+			t.Errorf("magicConstants(%d)=%x, %x, computed %x, %x", tst.w, pw, qw, p, q)
+		}
+	}
+}
+
+func TestNewZeroPadded(t *testing.T) {
+
+	key := seq(1, 10)
+	padded := append(seq(1, 10), make([]byte, 6)...)
+
+	c, err := NewZeroPadded(key)
+	if err != nil {
+		t.Fatalf("NewZeroPadded failed: %v", err)
+	}
+
+	want, _ := New(padded)
+
+	var got, exp [8]byte
+	c.Encrypt(got[:], tests[0].plain)
+	want.Encrypt(exp[:], tests[0].plain)
+	if got != exp {
+		t.Errorf("NewZeroPadded(10 byte key):\ngot : % 02x\nwant: % 02x", got[:], exp[:])
+	}
+
+	// padding changes the schedule relative to the shorter key
+	short, _ := NewWithParameters(32, 12, key)
+	short.Encrypt(exp[:], tests[0].plain)
+	if got == exp {
+		t.Errorf("NewZeroPadded matches RC5-32/12/10")
+	}
+
+	for _, l := range []int{0, 16} {
+		if _, err := NewZeroPadded(make([]byte, l)); err != nil {
+			t.Errorf("NewZeroPadded(%d byte key) failed: %v", l, err)
+		}
+	}
+
+	if _, err := NewZeroPadded(make([]byte, 17)); !errors.Is(err, KeySizeError(17)) {
+		t.Errorf("NewZeroPadded(17 byte key): got %v, want KeySizeError(17)", err)
+	}
+}
+
+func TestEqual(t *testing.T) {
+
+	newCipher := func(w, r int, key []byte) *Cipher {
+		c, err := NewWithParameters(w, r, key)
+		if err != nil {
+			t.Fatalf("NewWithParameters(%d, %d) failed: %v", w, r, err)
+		}
+		return c.(*Cipher)
+	}
+
+	for _, w := range []int{16, 32, 64} {
+		a := newCipher(w, 12, tests[0].key)
+		b := newCipher(w, 12, tests[0].key)
+
+		if !a.Equal(b) || !b.Equal(a) {
+			t.Errorf("RC5-%d/12 ciphers with the same key are not equal", w)
+		}
+
+		if a.Equal(newCipher(w, 12, tests[1].key)) {
+			t.Errorf("RC5-%d/12 ciphers with different keys are equal", w)
+		}
+
+		if a.Equal(newCipher(w, 16, tests[0].key)) {
+			t.Errorf("RC5-%d ciphers with different rounds are equal", w)
+		}
+	}
+
+	a := newCipher(32, 12, tests[0].key)
+
+	if a.Equal(newCipher(64, 12, tests[0].key)) {
+		t.Error("ciphers with different word sizes are equal")
+	}
+
+	be, _ := NewWithByteOrder(binary.BigEndian, tests[0].key)
+	if a.Equal(be.(*Cipher)) {
+		t.Error("ciphers with different byte orders are equal")
+	}
+
+	b := a.Clone().(*Cipher)
+	b.Wipe()
+	if a.Equal(b) || b.Equal(b) {
+		t.Error("wiped cipher compares equal")
+	}
+}
+
+// wordsRoundTrip runs the generic key schedule and block transform directly
+// for one instantiation, bypassing the Cipher dispatch.
+func wordsRoundTrip[T word](t *testing.T, r int, key, plain, want []byte) {
+
+	t.Helper()
+
+	u := int(wordBits[T]() / 8)
+
+	load := func(b []byte) T {
+		var v T
+		for i := u - 1; i >= 0; i-- {
+			v = v<<8 | T(b[i])
+		}
+		return v
+	}
+
+	pw, qw := magicConstants(8 * u)
+	rk := make([]T, 2*(r+1))
+	expandKeyWords(rk, key, false, T(pw), T(qw), keyPasses)
+
+	A, B := encryptWords(rk, load(plain[:u]), load(plain[u:]))
+	if wA, wB := load(want[:u]), load(want[u:]); A != wA || B != wB {
+		t.Errorf("encryptWords[uint%d] r=%d: got %x %x, want %x %x", 8*u, r, A, B, wA, wB)
+	}
+
+	A, B = decryptWords(rk, A, B)
+	if pA, pB := load(plain[:u]), load(plain[u:]); A != pA || B != pB {
+		t.Errorf("decryptWords[uint%d] r=%d: got %x %x, want %x %x", 8*u, r, A, B, pA, pB)
+	}
+}
+
+func TestWordsInstantiations(t *testing.T) {
+
+	for _, tst := range parameterTests {
+		switch tst.w {
+		case 16:
+			wordsRoundTrip[uint16](t, tst.r, tst.key, tst.plain, tst.cipher)
+		case 32:
+			wordsRoundTrip[uint32](t, tst.r, tst.key, tst.plain, tst.cipher)
+		case 64:
+			wordsRoundTrip[uint64](t, tst.r, tst.key, tst.plain, tst.cipher)
+		}
+	}
+
+	// the generic RC5-32/12 transform agrees with the specialized paths
+	c, _ := New(tests[0].key)
+	rc := c.(*Cipher)
+
+	var src, dst [8]byte
+	for i := 0; i < 1000; i++ {
+		rand.Read(src[:])
+		c.Encrypt(dst[:], src[:])
+
+		A, B := encryptWords(rc.rk32, binary.LittleEndian.Uint32(src[:4]), binary.LittleEndian.Uint32(src[4:]))
+		if A != binary.LittleEndian.Uint32(dst[:4]) || B != binary.LittleEndian.Uint32(dst[4:]) {
+			t.Fatalf("encryptWords(% 02x)=%08x %08x, Encrypt gives % 02x", src, A, B, dst)
+		}
+	}
+}
+
+func TestBlockSizeFor(t *testing.T) {
+
+	for _, tst := range []struct {
+		w, bs int
+	}{
+		{16, 4},
+		{32, 8},
+		{64, 16},
+		{8, 0},
+		{128, 0},
+	} {
+		if got := BlockSizeFor(tst.w); got != tst.bs {
+			t.Errorf("BlockSizeFor(%d)=%d, want %d", tst.w, got, tst.bs)
+		}
+
+		if tst.bs == 0 {
+			continue
+		}
+
+		c, _ := NewWithParameters(tst.w, 12, tests[0].key)
+		if got := c.BlockSize(); got != tst.bs {
+			t.Errorf("RC5-%d BlockSize()=%d, BlockSizeFor gives %d", tst.w, got, tst.bs)
+		}
+	}
+
+	c, _ := New(tests[0].key)
+	if c.BlockSize() != BlockSize {
+		t.Errorf("New BlockSize()=%d, want BlockSize=%d", c.BlockSize(), BlockSize)
+	}
+}
+
+func TestKeyLengthError(t *testing.T) {
+
+	for _, tst := range []struct {
+		err       error
+		short     bool
+		long      bool
+		message   string
+		keySizeOf int
+	}{
+		{
+			func() error { _, err := New(make([]byte, 8)); return err }(),
+			true, false, "rc5: key too short: 8 bytes, need exactly 16", 8,
+		},
+		{
+			func() error { _, err := New(make([]byte, 20)); return err }(),
+			false, true, "rc5: key too long: 20 bytes, need exactly 16", 20,
+		},
+		{
+			func() error { _, err := NewWithParameters(32, 12, make([]byte, 256)); return err }(),
+			false, true, "rc5: key too long: 256 bytes, maximum 255", 256,
+		},
+		{
+			func() error { _, err := NewZeroPadded(make([]byte, 17)); return err }(),
+			false, true, "rc5: key too long: 17 bytes, maximum 16", 17,
+		},
+		{
+			func() error { _, err := DeriveKey("passphrase", []byte("salt"), 1, 0); return err }(),
+			true, false, "rc5: key too short: 0 bytes, need at least 1", 0,
+		},
+	} {
+		var e KeyLengthError
+		if !errors.As(tst.err, &e) {
+			t.Errorf("%v is not a KeyLengthError", tst.err)
+			continue
+		}
+
+		if e.TooShort() != tst.short || e.TooLong() != tst.long {
+			t.Errorf("%v: TooShort()=%v TooLong()=%v, want %v %v", e, e.TooShort(), e.TooLong(), tst.short, tst.long)
+		}
+
+		if got := e.Error(); got != tst.message {
+			t.Errorf("Error()=%q, want %q", got, tst.message)
+		}
+
+		// still usable as a KeySizeError
+		var k KeySizeError
+		if !errors.As(tst.err, &k) || int(k) != tst.keySizeOf {
+			t.Errorf("%v does not wrap KeySizeError(%d)", tst.err, tst.keySizeOf)
+		}
+	}
+}
+
+func TestNewInto(t *testing.T) {
+
+	var schedule [26]uint32
+
+	for _, tst := range tests {
+
+		c, err := NewInto(schedule[:], tst.key)
+		if err != nil {
+			t.Fatalf("NewInto failed: %v", err)
+		}
+
+		ref, _ := New(tst.key)
+		if !slices.Equal(schedule[:], ref.(*Cipher).rk32) {
+			t.Errorf("NewInto schedule:\ngot : %08x\nwant: %08x", schedule, ref.(*Cipher).rk32)
+		}
+
+		ct := make([]byte, 8)
+		c.Encrypt(ct, tst.plain)
+		if !bytes.Equal(ct, tst.cipher) {
+			t.Errorf("NewInto encrypt:\ngot : % 02x\nwant: % 02x", ct, tst.cipher)
+		}
+
+		c.Decrypt(ct, ct)
+		if !bytes.Equal(ct, tst.plain) {
+			t.Errorf("NewInto decrypt:\ngot : % 02x\nwant: % 02x", ct, tst.plain)
+		}
+	}
+
+	// only the Cipher itself is allocated
+	key := tests[0].key
+	if n := testing.AllocsPerRun(100, func() { NewInto(schedule[:], key) }); n > 1 {
+		t.Errorf("NewInto made %v allocations, want at most 1", n)
+	}
+
+	c, _ := NewInto(schedule[:], key)
+	c.(*Cipher).Wipe()
+	if schedule != [26]uint32{} {
+		t.Error("Wipe did not clear the caller's schedule")
+	}
+
+	if _, err := NewInto(schedule[:25], key); err != errScheduleSize {
+		t.Errorf("NewInto with 25 round keys: got %v, want %v", err, errScheduleSize)
+	}
+
+	if _, err := NewInto(schedule[:], key[:8]); !errors.Is(err, KeySizeError(8)) {
+		t.Errorf("NewInto with 8 byte key: got %v, want KeySizeError(8)", err)
+	}
+}
@@ -0,0 +1,74 @@
+package rc5
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	errThroughput = errors.New("rc5: target throughput must be positive")
+	errNoRounds   = errors.New("rc5: no round count meets the target throughput")
+)
+
+const (
+	recommendBuffer = 4 << 10              // bytes encrypted per pass when measuring
+	recommendTime   = 2 * time.Millisecond // minimum time measured per round count
+)
+
+// RecommendRounds returns the largest number of rounds, at least minSecure,
+// at which RC5-32 with a 16-byte key encrypts at targetMBps megabytes (10^6
+// bytes) per second or faster on this machine.  It measures minSecure first,
+// returning an error if even that is too slow, and then binary searches the
+// round counts up to 255, measuring each for a couple of milliseconds.
+// Timings are noisy, so the answer near a boundary can vary between calls;
+// treat it as a guide to the cost of extra rounds.
+func RecommendRounds(minSecure int, targetMBps float64) (int, error) {
+
+	if err := checkParameters(32, minSecure, nil); err != nil {
+		return 0, err
+	}
+
+	if targetMBps <= 0 {
+		return 0, errThroughput
+	}
+
+	fast := func(rounds int) bool {
+		return roundsThroughput(rounds) >= targetMBps
+	}
+
+	if !fast(minSecure) {
+		return 0, errNoRounds
+	}
+
+	// fast(lo) holds; find the largest such count in [lo, hi]
+	lo, hi := minSecure, 255
+	for lo < hi {
+		mid := lo + (hi-lo+1)/2
+		if fast(mid) {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+
+	return lo, nil
+}
+
+// roundsThroughput measures the speed of RC5-32/rounds/16 in megabytes per
+// second.
+func roundsThroughput(rounds int) float64 {
+
+	c, _ := newCipher(params{wordSize: 32, rounds: rounds}, make([]byte, 16))
+	buf := make([]byte, recommendBuffer)
+
+	var n int
+	start := time.Now()
+	for time.Since(start) < recommendTime {
+		for i := 0; i < len(buf); i += BlockSize {
+			c.Encrypt(buf[i:], buf[i:])
+		}
+		n += len(buf)
+	}
+
+	return float64(n) / 1e6 / time.Since(start).Seconds()
+}
@@ -0,0 +1,42 @@
+package rc5
+
+import "testing"
+
+func TestRecommendRounds(t *testing.T) {
+
+	const minSecure = 16
+
+	// a throughput RC5-32/16 reaches on any machine, and one it cannot
+	r, err := RecommendRounds(minSecure, 0.1)
+	if err != nil {
+		t.Fatalf("RecommendRounds(%d, 0.1) failed: %v", minSecure, err)
+	}
+	if r < minSecure {
+		t.Errorf("RecommendRounds(%d, 0.1)=%d, want at least %d", minSecure, r, minSecure)
+	}
+
+	if _, err := RecommendRounds(minSecure, 1e9); err != errNoRounds {
+		t.Errorf("RecommendRounds(%d, 1e9): got %v, want %v", minSecure, err, errNoRounds)
+	}
+
+	// a higher target never allows more rounds; the targets are far enough
+	// apart for timing noise not to matter, and one too fast may fail
+	prev := r
+	for _, target := range []float64{4, 40, 400} {
+		r, err := RecommendRounds(minSecure, target)
+		if err == errNoRounds {
+			break
+		}
+		if err != nil || r < minSecure || r > prev {
+			t.Errorf("RecommendRounds(%d, %v)=(%d, %v), want between %d and %d", minSecure, target, r, err, minSecure, prev)
+		}
+		prev = r
+	}
+
+	if _, err := RecommendRounds(256, 1); err != (ParameterError{"rounds", 256}) {
+		t.Errorf("RecommendRounds(256, 1): got %v, want rounds error", err)
+	}
+	if _, err := RecommendRounds(minSecure, 0); err != errThroughput {
+		t.Errorf("RecommendRounds(%d, 0): got %v, want %v", minSecure, err, errThroughput)
+	}
+}
@@ -0,0 +1,43 @@
+package rc5
+
+import (
+	"bytes"
+	"errors"
+)
+
+var errDelimiter = errors.New("rc5: empty record delimiter")
+
+// DecryptRecords decrypts data, which is RC5-32/12/16 in the RC5-CBC-Pad mode
+// as from EncryptCBCPad, removes the padding and splits the plaintext into
+// records each terminated by CRLF.  It is DecryptRecordsWithDelimiter with a
+// delimiter of "\r\n".
+func DecryptRecords(key, iv, data []byte) ([][]byte, error) {
+	return DecryptRecordsWithDelimiter(key, iv, data, []byte("\r\n"))
+}
+
+// DecryptRecordsWithDelimiter is DecryptRecords for records terminated by
+// delim, which must not be empty.  The records do not include the delimiter
+// and share the storage of the decrypted plaintext.  Consecutive delimiters
+// give empty records, and a final record without a delimiter is returned as
+// if it had one, so "a\r\n\r\nb" and "a\r\n\r\nb\r\n" both give the records
+// "a", "" and "b".  Empty plaintext gives no records.
+func DecryptRecordsWithDelimiter(key, iv, data, delim []byte) ([][]byte, error) {
+
+	if len(delim) == 0 {
+		return nil, errDelimiter
+	}
+
+	plain, err := DecryptCBCPad(key, iv, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var records [][]byte
+	for len(plain) > 0 {
+		rec, rest, _ := bytes.Cut(plain, delim)
+		records = append(records, rec[:len(rec):len(rec)])
+		plain = rest
+	}
+
+	return records, nil
+}
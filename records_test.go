@@ -0,0 +1,64 @@
+package rc5
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecryptRecords(t *testing.T) {
+
+	key := tests[0].key
+	iv := seq(0x40, 8)
+
+	for _, tst := range []struct {
+		plain string
+		want  []string
+	}{
+		{"", nil},
+		{"one\r\n", []string{"one"}},
+		{"one\r\ntwo\r\nthree\r\n", []string{"one", "two", "three"}},
+		{"one\r\n\r\nthree\r\n", []string{"one", "", "three"}},
+		{"\r\n", []string{""}},
+		{"one\r\ntwo", []string{"one", "two"}},
+		{"a\rb\nc\r\n", []string{"a\rb\nc"}},
+	} {
+		ct, _ := EncryptCBCPad(key, iv, []byte(tst.plain))
+
+		got, err := DecryptRecords(key, iv, ct)
+		if err != nil {
+			t.Fatalf("DecryptRecords(%q) failed: %v", tst.plain, err)
+		}
+
+		if len(got) != len(tst.want) {
+			t.Fatalf("DecryptRecords(%q): got %d records %q, want %q", tst.plain, len(got), got, tst.want)
+		}
+
+		for i := range got {
+			if string(got[i]) != tst.want[i] {
+				t.Errorf("DecryptRecords(%q)[%d]=%q, want %q", tst.plain, i, got[i], tst.want[i])
+			}
+		}
+
+		// appending to one record must not clobber the next
+		if len(got) > 1 {
+			_ = append(got[0], 'X', 'X', 'X', 'X')
+			if string(got[1]) != tst.want[1] {
+				t.Errorf("DecryptRecords(%q): appending to record 0 changed record 1 to %q", tst.plain, got[1])
+			}
+		}
+	}
+
+	ct, _ := EncryptCBCPad(key, iv, []byte("a||b||||c"))
+	got, err := DecryptRecordsWithDelimiter(key, iv, ct, []byte("||"))
+	if err != nil || !bytes.Equal(bytes.Join(got, []byte(",")), []byte("a,b,,c")) {
+		t.Errorf("DecryptRecordsWithDelimiter(||): got (%q, %v), want a, b, \"\", c", got, err)
+	}
+
+	if _, err := DecryptRecordsWithDelimiter(key, iv, ct, nil); err != errDelimiter {
+		t.Errorf("DecryptRecordsWithDelimiter with no delimiter: got %v, want %v", err, errDelimiter)
+	}
+
+	if _, err := DecryptRecords(key, iv, ct[:len(ct)-1]); err != errInputSize {
+		t.Errorf("DecryptRecords of truncated data: got %v, want %v", err, errInputSize)
+	}
+}
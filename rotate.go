@@ -0,0 +1,29 @@
+package rc5
+
+import "unsafe"
+
+// A word is an unsigned integer type usable as an RC5 word.
+type word interface {
+	~uint16 | ~uint32 | ~uint64
+}
+
+// wordBits returns the size of T in bits.
+func wordBits[T word]() T {
+	var v T
+	return T(8 * unsafe.Sizeof(v))
+}
+
+// rotl rotates v left by n mod w bits, as RC5 specifies for a word size of w
+// bits, and rotr rotates right.  Once instantiated w is a constant, so the
+// compiler folds the mask into a single rotate instruction.
+func rotl[T word](v, n T) T {
+	w := wordBits[T]()
+	n &= w - 1
+	return v<<n | v>>(w-n)
+}
+
+func rotr[T word](v, n T) T {
+	w := wordBits[T]()
+	n &= w - 1
+	return v>>n | v<<(w-n)
+}
@@ -0,0 +1,51 @@
+package rc5
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func TestRotate(t *testing.T) {
+
+	var v uint64 = 0x0123456789abcdef
+
+	for n := uint64(0); n < 256; n++ {
+
+		s := n % 64
+		want64 := v<<s | v>>(64-s)
+		if got := rotl(v, n); got != want64 {
+			t.Errorf("rotl[uint64](%x, %d)=%x, want %x", v, n, got, want64)
+		}
+		if got := rotr(want64, n); got != v {
+			t.Errorf("rotr[uint64](%x, %d)=%x, want %x", want64, n, got, v)
+		}
+
+		s = n % 32
+		want32 := uint32(v)<<s | uint32(v)>>(32-s)
+		if got := rotl(uint32(v), uint32(n)); got != want32 {
+			t.Errorf("rotl[uint32](%x, %d)=%x, want %x", uint32(v), n, got, want32)
+		}
+		if got := rotr(want32, uint32(n)); got != uint32(v) {
+			t.Errorf("rotr[uint32](%x, %d)=%x, want %x", want32, n, got, uint32(v))
+		}
+
+		// unchanged from rotating directly by the full count
+		if got, want := rotl(uint32(v), uint32(n)), bits.RotateLeft32(uint32(v), int(n)); got != want {
+			t.Errorf("rotl[uint32](%x, %d)=%x, bits.RotateLeft32 gives %x", uint32(v), n, got, want)
+		}
+
+		s = n % 16
+		want16 := uint16(v)<<s | uint16(v)>>(16-s)
+		if got := rotl(uint16(v), uint16(n)); got != want16 {
+			t.Errorf("rotl[uint16](%x, %d)=%x, want %x", uint16(v), n, got, want16)
+		}
+		if got := rotr(want16, uint16(n)); got != uint16(v) {
+			t.Errorf("rotr[uint16](%x, %d)=%x, want %x", want16, n, got, uint16(v))
+		}
+	}
+
+	// counts beyond the word size wrap around
+	if got := rotl[uint32](1, 0xffffffff); got != 1<<31 {
+		t.Errorf("rotl[uint32](1, 0xffffffff)=%x, want %x", got, uint32(1<<31))
+	}
+}
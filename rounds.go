@@ -0,0 +1,28 @@
+package rc5
+
+// EncryptRounds applies the RC5-32 encryption transform to the word pair a, b
+// with the round keys rk: a and b are whitened with rk[0] and rk[1], followed
+// by the given number of rounds, each using the next two round keys.  It
+// panics unless rk holds at least 2*(rounds+1) round keys.  Loading a and b
+// from the block as little-endian words and storing the result the same way
+// gives Encrypt.
+//
+// EncryptRounds is a low-level primitive for building experimental
+// constructions on the RC5 round function.  It checks nothing about the round
+// keys or the number of rounds, and carries no security guarantees.
+func EncryptRounds(rk []uint32, a, b uint32, rounds int) (uint32, uint32) {
+	return encryptWords(roundKeys(rk, rounds), a, b)
+}
+
+// DecryptRounds is the inverse of EncryptRounds with the same round keys and
+// rounds.  It is a low-level primitive, as is EncryptRounds.
+func DecryptRounds(rk []uint32, a, b uint32, rounds int) (uint32, uint32) {
+	return decryptWords(roundKeys(rk, rounds), a, b)
+}
+
+func roundKeys(rk []uint32, rounds int) []uint32 {
+	if rounds < 0 || len(rk)/2 <= rounds {
+		panic("rc5: too few round keys for the number of rounds")
+	}
+	return rk[:2*(rounds+1)]
+}
@@ -0,0 +1,62 @@
+package rc5
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestEncryptRounds(t *testing.T) {
+
+	for _, tst := range tests {
+
+		wide, _ := ExpandKey(32, 12, tst.key)
+		rk := make([]uint32, len(wide))
+		for i, k := range wide {
+			rk[i] = uint32(k)
+		}
+
+		a, b := EncryptRounds(rk, binary.LittleEndian.Uint32(tst.plain), binary.LittleEndian.Uint32(tst.plain[4:]), 12)
+
+		ct := binary.LittleEndian.AppendUint32(nil, a)
+		ct = binary.LittleEndian.AppendUint32(ct, b)
+		if !bytes.Equal(ct, tst.cipher) {
+			t.Errorf("EncryptRounds:\ngot : % 02x\nwant: % 02x", ct, tst.cipher)
+		}
+
+		a, b = DecryptRounds(rk, a, b, 12)
+		pt := binary.LittleEndian.AppendUint32(nil, a)
+		pt = binary.LittleEndian.AppendUint32(pt, b)
+		if !bytes.Equal(pt, tst.plain) {
+			t.Errorf("DecryptRounds:\ngot : % 02x\nwant: % 02x", pt, tst.plain)
+		}
+
+		// fewer rounds use a prefix of the round keys
+		c, _ := NewWithRounds(8, tst.key)
+		var want [8]byte
+		c.Encrypt(want[:], tst.plain)
+
+		wide, _ = ExpandKey(32, 8, tst.key)
+		for i, k := range wide {
+			rk[i] = uint32(k)
+		}
+		a, b = EncryptRounds(rk, binary.LittleEndian.Uint32(tst.plain), binary.LittleEndian.Uint32(tst.plain[4:]), 8)
+		if a != binary.LittleEndian.Uint32(want[:]) || b != binary.LittleEndian.Uint32(want[4:]) {
+			t.Errorf("EncryptRounds with 8 rounds: got %08x %08x, want % 02x", a, b, want[:])
+		}
+	}
+}
+
+func TestEncryptRoundsPanics(t *testing.T) {
+
+	for _, rounds := range []int{-1, 13} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("EncryptRounds with 26 round keys and %d rounds did not panic", rounds)
+				}
+			}()
+			EncryptRounds(make([]uint32, 26), 0, 0, rounds)
+		}()
+	}
+}
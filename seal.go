@@ -0,0 +1,177 @@
+package rc5
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var (
+	errSealedLength = errors.New("rc5: sealed data too short")
+	errHeaderSize   = errors.New("rc5: header too long")
+)
+
+// Seal encrypts and authenticates plaintext and authenticates additionalData
+// with EAX over RC5-32/12/16, using a random nonce.  It returns the nonce
+// followed by the ciphertext and tag, which Open accepts.  WithRand selects
+// the source of the nonce.
+func Seal(key, plaintext, additionalData []byte, opts ...RandOption) ([]byte, error) {
+
+	aead, err := NewEAX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if err := readRandom(nonce, opts); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open verifies and decrypts a sealed message produced by Seal with the same
+// key and additional data.
+func Open(key, sealed, additionalData []byte) ([]byte, error) {
+
+	aead, err := NewEAX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, errSealedLength
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+// SealToString is Seal returning the sealed message in the unpadded base64url
+// encoding (RFC 4648 section 5 without '=' padding), which is safe in URLs,
+// file names and configuration files.
+func SealToString(key, plaintext, additionalData []byte, opts ...RandOption) (string, error) {
+
+	sealed, err := Seal(key, plaintext, additionalData, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// OpenFromString decodes a string produced by SealToString and opens it with
+// Open.  A string which is not valid unpadded base64url is reported with the
+// error from encoding/base64.
+func OpenFromString(key []byte, s string, additionalData []byte) ([]byte, error) {
+
+	sealed, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return Open(key, sealed, additionalData)
+}
+
+// SealWithHeader encrypts and authenticates body, and authenticates but does
+// not encrypt header, with EAX over RC5-32/12/16 using a random nonce.  The
+// output is the big-endian 32-bit length of header, header itself, and then
+// the nonce, ciphertext and tag.  The length and header are the additional
+// data, so the header can be read before opening but not changed.  WithRand
+// selects the source of the nonce.
+func SealWithHeader(key, header, body []byte, opts ...RandOption) (out []byte, err error) {
+
+	if uint64(len(header)) > math.MaxUint32 {
+		return nil, errHeaderSize
+	}
+
+	aead, err := NewEAX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	n := 4 + len(header)
+	out = make([]byte, 4, n+aead.NonceSize()+len(body)+aead.Overhead())
+	binary.BigEndian.PutUint32(out, uint32(len(header)))
+	out = append(out, header...)
+
+	nonce := out[n : n+aead.NonceSize()]
+	if err := readRandom(nonce, opts); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(out[:n+len(nonce)], nonce, body, out[:n]), nil
+}
+
+// OpenWithHeader verifies and decrypts a message produced by SealWithHeader,
+// returning the header, which aliases sealed, and the decrypted body.  It
+// returns an error if either has been modified.
+func OpenWithHeader(key, sealed []byte) (header, body []byte, err error) {
+
+	aead, err := NewEAX(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(sealed) < 4 {
+		return nil, nil, errSealedLength
+	}
+
+	hl := uint64(binary.BigEndian.Uint32(sealed))
+	if uint64(len(sealed)-4) < hl+uint64(aead.NonceSize()+aead.Overhead()) {
+		return nil, nil, errSealedLength
+	}
+
+	n := 4 + int(hl)
+	nonce, ciphertext := sealed[n:n+aead.NonceSize()], sealed[n+aead.NonceSize():]
+
+	body, err = aead.Open(nil, nonce, ciphertext, sealed[:n])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sealed[4:n], body, nil
+}
+
+// SealChunk encrypts and authenticates chunk number chunkIndex of a larger
+// message with EAX over RC5-32/12/16, so each chunk can be decrypted, or
+// retried, on its own.  The nonce is the big-endian chunk index, and the index
+// is also authenticated with additionalData, so a chunk opened at any other
+// index is rejected.  Sealing the same chunk again gives the same output.
+//
+// Because the nonce is not random, a key must be used for only one chunked
+// message, and a chunk index must never be reused for different plaintext.
+func SealChunk(key []byte, chunkIndex uint64, plaintext, additionalData []byte) ([]byte, error) {
+
+	aead, err := NewEAX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, ad := chunkNonce(chunkIndex, additionalData)
+
+	return aead.Seal(nil, nonce, plaintext, ad), nil
+}
+
+// OpenChunk verifies and decrypts a chunk produced by SealChunk with the same
+// key, chunk index and additional data.
+func OpenChunk(key []byte, chunkIndex uint64, ciphertext, additionalData []byte) ([]byte, error) {
+
+	aead, err := NewEAX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, ad := chunkNonce(chunkIndex, additionalData)
+
+	return aead.Open(nil, nonce, ciphertext, ad)
+}
+
+// chunkNonce returns the nonce and additional data for a chunk.
+func chunkNonce(index uint64, additionalData []byte) (nonce, ad []byte) {
+	nonce = binary.BigEndian.AppendUint64(nil, index)
+	ad = append(binary.BigEndian.AppendUint64(nil, index), additionalData...)
+	return nonce, ad
+}
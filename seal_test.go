@@ -0,0 +1,217 @@
+package rc5
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSeal(t *testing.T) {
+
+	key := tests[0].key
+	ad := []byte("header")
+
+	for _, l := range []int{0, 1, 8, 100} {
+
+		msg := seq(0x80, l)
+
+		sealed, err := Seal(key, msg, ad)
+		if err != nil {
+			t.Fatalf("Seal failed: %v", err)
+		}
+
+		if len(sealed) != 8+l+8 {
+			t.Errorf("Seal (len=%d): got %d bytes, want %d", l, len(sealed), 8+l+8)
+		}
+
+		pt, err := Open(key, sealed, ad)
+		if err != nil {
+			t.Fatalf("Open (len=%d) failed: %v", l, err)
+		}
+
+		if !bytes.Equal(pt, msg) {
+			t.Errorf("Seal/Open round trip:\ngot : % 02x\nwant: % 02x", pt, msg)
+		}
+
+		for i := range sealed {
+			sealed[i] ^= 0x01
+			if _, err := Open(key, sealed, ad); err != errOpen {
+				t.Errorf("Open (len=%d) with byte %d flipped: got %v, want %v", l, i, err, errOpen)
+			}
+			sealed[i] ^= 0x01
+		}
+
+		if _, err := Open(key, sealed, nil); err != errOpen {
+			t.Errorf("Open (len=%d) without additional data: got %v, want %v", l, err, errOpen)
+		}
+	}
+
+	// each call uses a fresh nonce
+	a, _ := Seal(key, nil, nil)
+	b, _ := Seal(key, nil, nil)
+	if bytes.Equal(a, b) {
+		t.Errorf("Seal produced identical output twice: % 02x", a)
+	}
+
+	if _, err := Open(key, make([]byte, 15), nil); err != errSealedLength {
+		t.Errorf("Open of 15 bytes: got %v, want %v", err, errSealedLength)
+	}
+
+	if _, err := Seal(key[:8], nil, nil); !errors.Is(err, KeySizeError(8)) {
+		t.Errorf("Seal with 8 byte key: got %v, want KeySizeError(8)", err)
+	}
+}
+
+func TestSealToString(t *testing.T) {
+
+	key := tests[0].key
+	ad := []byte("header")
+
+	for _, l := range []int{0, 1, 8, 100} {
+
+		msg := seq(0x80, l)
+
+		s, err := SealToString(key, msg, ad)
+		if err != nil {
+			t.Fatalf("SealToString failed: %v", err)
+		}
+
+		if strings.ContainsAny(s, "+/=") {
+			t.Errorf("SealToString (len=%d) is not unpadded base64url: %q", l, s)
+		}
+
+		pt, err := OpenFromString(key, s, ad)
+		if err != nil {
+			t.Fatalf("OpenFromString (len=%d) failed: %v", l, err)
+		}
+
+		if !bytes.Equal(pt, msg) {
+			t.Errorf("SealToString round trip:\ngot : % 02x\nwant: % 02x", pt, msg)
+		}
+
+		// change one character to another valid one
+		b := []byte(s)
+		if b[len(b)/2] == 'A' {
+			b[len(b)/2] = 'B'
+		} else {
+			b[len(b)/2] = 'A'
+		}
+		if _, err := OpenFromString(key, string(b), ad); err != errOpen {
+			t.Errorf("OpenFromString (len=%d) of a corrupted string: got %v, want %v", l, err, errOpen)
+		}
+
+		if _, err := OpenFromString(key, s, nil); err != errOpen {
+			t.Errorf("OpenFromString (len=%d) with wrong ad: got %v, want %v", l, err, errOpen)
+		}
+	}
+
+	var corrupt base64.CorruptInputError
+	if _, err := OpenFromString(key, "not*base64", ad); !errors.As(err, &corrupt) {
+		t.Errorf("OpenFromString of invalid base64: got %v, want a base64.CorruptInputError", err)
+	}
+
+	if _, err := OpenFromString(key, "AAAA", ad); err != errSealedLength {
+		t.Errorf("OpenFromString of a short string: got %v, want %v", err, errSealedLength)
+	}
+}
+
+func TestSealWithHeader(t *testing.T) {
+
+	key := tests[0].key
+
+	for _, tst := range []struct{ headerLen, bodyLen int }{
+		{0, 0}, {5, 0}, {0, 5}, {3, 8}, {20, 100},
+	} {
+		header := seq(0x40, tst.headerLen)
+		body := seq(0x80, tst.bodyLen)
+
+		out, err := SealWithHeader(key, header, body)
+		if err != nil {
+			t.Fatalf("SealWithHeader failed: %v", err)
+		}
+
+		if want := 4 + tst.headerLen + 8 + tst.bodyLen + 8; len(out) != want {
+			t.Errorf("SealWithHeader(%d, %d): got %d bytes, want %d", tst.headerLen, tst.bodyLen, len(out), want)
+		}
+
+		// the header is in the clear
+		if !bytes.Equal(out[4:4+tst.headerLen], header) {
+			t.Errorf("SealWithHeader(%d, %d) header:\ngot : % 02x\nwant: % 02x", tst.headerLen, tst.bodyLen, out[4:4+tst.headerLen], header)
+		}
+
+		h, b, err := OpenWithHeader(key, out)
+		if err != nil {
+			t.Fatalf("OpenWithHeader(%d, %d) failed: %v", tst.headerLen, tst.bodyLen, err)
+		}
+
+		if !bytes.Equal(h, header) || !bytes.Equal(b, body) {
+			t.Errorf("SealWithHeader(%d, %d) round trip: got (% 02x, % 02x)", tst.headerLen, tst.bodyLen, h, b)
+		}
+
+		for i := range out {
+			out[i] ^= 0x01
+			if _, _, err := OpenWithHeader(key, out); err == nil {
+				t.Errorf("OpenWithHeader(%d, %d) with byte %d flipped succeeded", tst.headerLen, tst.bodyLen, i)
+			}
+			out[i] ^= 0x01
+		}
+	}
+
+	for _, l := range []int{0, 3, 4 + 15} {
+		if _, _, err := OpenWithHeader(key, make([]byte, l)); err != errSealedLength {
+			t.Errorf("OpenWithHeader of %d bytes: got %v, want %v", l, err, errSealedLength)
+		}
+	}
+
+	// a header length beyond the end of the input
+	if _, _, err := OpenWithHeader(key, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0, 0}); err != errSealedLength {
+		t.Errorf("OpenWithHeader with a long header length: got %v, want %v", err, errSealedLength)
+	}
+}
+
+func TestSealChunk(t *testing.T) {
+
+	key := tests[0].key
+	ad := []byte("upload 42")
+
+	chunks := [][]byte{seq(0x00, 100), seq(0x40, 100), seq(0x80, 17)}
+
+	sealed := make([][]byte, len(chunks))
+	for i, c := range chunks {
+
+		ct, err := SealChunk(key, uint64(i), c, ad)
+		if err != nil {
+			t.Fatalf("SealChunk(%d) failed: %v", i, err)
+		}
+		sealed[i] = ct
+
+		// a retry gives identical ciphertext
+		again, _ := SealChunk(key, uint64(i), c, ad)
+		if !bytes.Equal(again, ct) {
+			t.Errorf("SealChunk(%d) retry:\ngot : % 02x\nwant: % 02x", i, again, ct)
+		}
+
+		p, err := OpenChunk(key, uint64(i), ct, ad)
+		if err != nil || !bytes.Equal(p, c) {
+			t.Errorf("OpenChunk(%d): got (% 02x, %v), want % 02x", i, p, err, c)
+		}
+	}
+
+	// each chunk only opens at its own index
+	for i, ct := range sealed {
+		for j := range sealed {
+			if i == j {
+				continue
+			}
+			if _, err := OpenChunk(key, uint64(j), ct, ad); err != errOpen {
+				t.Errorf("OpenChunk of chunk %d at index %d: got %v, want %v", i, j, err, errOpen)
+			}
+		}
+	}
+
+	if _, err := OpenChunk(key, 0, sealed[0], []byte("upload 43")); err != errOpen {
+		t.Errorf("OpenChunk with different additional data: got %v, want %v", err, errOpen)
+	}
+}
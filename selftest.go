@@ -0,0 +1,117 @@
+package rc5
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+// knownAnswers are the RC5-32/12/16 examples from Rivest's "The RC5
+// Encryption Algorithm".  (The test vectors in RFC 2040 exercise the CBC modes
+// across many key lengths and round counts rather than the basic cipher.)
+var knownAnswers = [...]struct {
+	key    [16]byte
+	plain  [8]byte
+	cipher [8]byte
+}{
+	{
+		[16]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		[8]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		[8]byte{0x21, 0xA5, 0xDB, 0xEE, 0x15, 0x4B, 0x8F, 0x6D},
+	},
+	{
+		[16]byte{0x91, 0x5F, 0x46, 0x19, 0xBE, 0x41, 0xB2, 0x51, 0x63, 0x55, 0xA5, 0x01, 0x10, 0xA9, 0xCE, 0x91},
+		[8]byte{0x21, 0xA5, 0xDB, 0xEE, 0x15, 0x4B, 0x8F, 0x6D},
+		[8]byte{0xF7, 0xC0, 0x13, 0xAC, 0x5B, 0x2B, 0x89, 0x52},
+	},
+	{
+		[16]byte{0x78, 0x33, 0x48, 0xE7, 0x5A, 0xEB, 0x0F, 0x2F, 0xD7, 0xB1, 0x69, 0xBB, 0x8D, 0xC1, 0x67, 0x87},
+		[8]byte{0xF7, 0xC0, 0x13, 0xAC, 0x5B, 0x2B, 0x89, 0x52},
+		[8]byte{0x2F, 0x42, 0xB3, 0xB7, 0x03, 0x69, 0xFC, 0x92},
+	},
+	{
+		[16]byte{0xDC, 0x49, 0xDB, 0x13, 0x75, 0xA5, 0x58, 0x4F, 0x64, 0x85, 0xB4, 0x13, 0xB5, 0xF1, 0x2B, 0xAF},
+		[8]byte{0x2F, 0x42, 0xB3, 0xB7, 0x03, 0x69, 0xFC, 0x92},
+		[8]byte{0x65, 0xC1, 0x78, 0xB2, 0x84, 0xD1, 0x97, 0xCC},
+	},
+	{
+		[16]byte{0x52, 0x69, 0xF1, 0x49, 0xD4, 0x1B, 0xA0, 0x15, 0x24, 0x97, 0x57, 0x4D, 0x7F, 0x15, 0x31, 0x25},
+		[8]byte{0x65, 0xC1, 0x78, 0xB2, 0x84, 0xD1, 0x97, 0xCC},
+		[8]byte{0xEB, 0x44, 0xE4, 0x15, 0xDA, 0x31, 0x98, 0x24},
+	},
+}
+
+// SelfTest checks that RC5-32/12/16 as built encrypts and decrypts a set of
+// known-answer vectors correctly.  It is intended to be called at startup to
+// detect a broken build, in the style of a FIPS power-on self test.
+func SelfTest() error {
+
+	for i, v := range knownAnswers {
+
+		c, err := New(v.key[:])
+		if err != nil {
+			return err
+		}
+
+		var out [8]byte
+
+		c.Encrypt(out[:], v.plain[:])
+		if !bytes.Equal(out[:], v.cipher[:]) {
+			return errors.New("rc5: self test failed: encrypt vector " + strconv.Itoa(i))
+		}
+
+		c.Decrypt(out[:], v.cipher[:])
+		if !bytes.Equal(out[:], v.plain[:]) {
+			return errors.New("rc5: self test failed: decrypt vector " + strconv.Itoa(i))
+		}
+	}
+
+	return nil
+}
+
+// A KAT is a known-answer vector for RunKATs: the variant, the key, and a
+// single block of plaintext with its expected ciphertext.
+type KAT struct {
+	Parameters
+	Key, Plaintext, Ciphertext []byte
+}
+
+// RunKATs checks each vector by constructing the cipher it describes and
+// verifying that it encrypts the plaintext to the ciphertext and decrypts it
+// back.  Like SelfTest it is intended for startup or CI checks, with vectors
+// agreed with particular peers.  It returns an error identifying the first
+// vector that fails, including one whose parameters, key or block lengths are
+// invalid.
+func RunKATs(vectors []KAT) error {
+
+	for i, v := range vectors {
+
+		fail := func(msg string) error {
+			return errors.New("rc5: known-answer test " + strconv.Itoa(i) + " (" + v.Parameters.String() + ") failed: " + msg)
+		}
+
+		c, err := v.New(v.Key)
+		if err != nil {
+			return fail(err.Error())
+		}
+
+		bs := c.BlockSize()
+		if len(v.Plaintext) != bs || len(v.Ciphertext) != bs {
+			return fail("vector is not one block")
+		}
+
+		out := make([]byte, bs)
+
+		c.Encrypt(out, v.Plaintext)
+		if !bytes.Equal(out, v.Ciphertext) {
+			return fail("encrypt")
+		}
+
+		c.Decrypt(out, v.Ciphertext)
+		if !bytes.Equal(out, v.Plaintext) {
+			return fail("decrypt")
+		}
+	}
+
+	return nil
+}
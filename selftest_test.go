@@ -0,0 +1,57 @@
+package rc5
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+
+	if err := SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := knownAnswers[2].cipher
+	defer func() { knownAnswers[2].cipher = saved }()
+
+	knownAnswers[2].cipher[0] ^= 1
+
+	if err := SelfTest(); err == nil {
+		t.Errorf("SelfTest succeeded with a corrupted vector")
+	}
+}
+
+func TestRunKATs(t *testing.T) {
+
+	// the RC5-16 and RC5-64 vectors are from draft-krovetz-rc6-rc5-vectors
+	vectors := []KAT{
+		{Parameters{32, 12, 16}, tests[0].key, tests[0].plain, tests[0].cipher},
+		{Parameters{16, 16, 8}, seq(0, 8), seq(0, 4), []byte{0x23, 0xA8, 0xD7, 0x2E}},
+		{
+			Parameters{64, 24, 24}, seq(0, 24), seq(0, 16),
+			[]byte{0xA4, 0x67, 0x72, 0x82, 0x0E, 0xDB, 0xCE, 0x02, 0x35, 0xAB, 0xEA, 0x32, 0xAE, 0x71, 0x78, 0xDA},
+		},
+	}
+
+	if err := RunKATs(vectors); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RunKATs(nil); err != nil {
+		t.Errorf("RunKATs(nil): %v", err)
+	}
+
+	wrong := bytes.Clone(vectors[1].Ciphertext)
+	wrong[3] ^= 1
+
+	for _, bad := range []KAT{
+		{Parameters{16, 16, 8}, seq(0, 8), seq(0, 4), wrong},
+		{Parameters{16, 16, 8}, seq(0, 7), seq(0, 4), vectors[1].Ciphertext},
+		{Parameters{24, 16, 8}, seq(0, 8), seq(0, 4), vectors[1].Ciphertext},
+		{Parameters{16, 16, 8}, seq(0, 8), seq(0, 8), vectors[1].Ciphertext},
+	} {
+		if err := RunKATs(append(vectors, bad)); err == nil {
+			t.Errorf("RunKATs succeeded with bad vector %+v", bad)
+		}
+	}
+}
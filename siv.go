@@ -0,0 +1,132 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+)
+
+type siv struct {
+	mac, ctr cipher.Block
+}
+
+// NewSIV returns a cipher.AEAD implementing the deterministic authenticated
+// encryption mode SIV of RFC 5297 over RC5-32/12/16, with S2V built on CMAC.
+// The 32-byte key is split into a 16-byte MAC key followed by a 16-byte
+// encryption key.
+//
+// Seal with a nil nonce is deterministic: the same plaintext and additional
+// data always give the same ciphertext, revealing only whether two messages
+// are equal.  A nonce of any length may be given for probabilistic
+// encryption; NonceSize returns 0.  The output is the 8-byte synthetic IV
+// followed by the ciphertext.  As for 128-bit SIV, the top bits of the two
+// 32-bit halves of the IV are cleared to form the initial counter.
+func NewSIV(key []byte) (cipher.AEAD, error) {
+
+	if l := len(key); l != 32 {
+		return nil, KeyLengthError{l, 32, 32}
+	}
+
+	mac, _ := New(key[:16])
+	ctr, _ := New(key[16:])
+
+	return newSIV(mac, ctr), nil
+}
+
+func newSIV(mac, ctr cipher.Block) *siv {
+	return &siv{mac: mac, ctr: ctr}
+}
+
+func (s *siv) NonceSize() int { return 0 }
+func (s *siv) Overhead() int  { return s.mac.BlockSize() }
+
+// s2v computes S2V over the additional data, the nonce if not empty, and the
+// plaintext.
+func (s *siv) s2v(additionalData, nonce, plaintext []byte) []byte {
+
+	bs := s.mac.BlockSize()
+	m := newCMAC(s.mac)
+
+	d := make([]byte, bs)
+	m.Write(d)
+	d = m.Sum(d[:0])
+
+	components := [][]byte{additionalData}
+	if len(nonce) > 0 {
+		components = append(components, nonce)
+	}
+
+	for _, c := range components {
+		dbl(d, d)
+		m.Reset()
+		m.Write(c)
+		subtle.XORBytes(d, d, m.Sum(nil))
+	}
+
+	m.Reset()
+
+	if len(plaintext) >= bs {
+		// xorend: XOR d into the final block of the plaintext
+		n := len(plaintext) - bs
+		m.Write(plaintext[:n])
+		subtle.XORBytes(d, d, plaintext[n:])
+		m.Write(d)
+	} else {
+		dbl(d, d)
+		subtle.XORBytes(d, d, plaintext)
+		d[len(plaintext)] ^= 0x80
+		m.Write(d)
+	}
+
+	return m.Sum(nil)
+}
+
+func (s *siv) crypt(dst, src, v []byte) {
+	q := append([]byte(nil), v...)
+	q[len(q)-8] &= 0x7f
+	q[len(q)-4] &= 0x7f
+	cipher.NewCTR(s.ctr, q).XORKeyStream(dst, src)
+}
+
+func (s *siv) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+
+	bs := s.mac.BlockSize()
+
+	ret, out := sliceForAppend(dst, bs+len(plaintext))
+	if inexactOverlap(out, plaintext) {
+		panic("rc5: invalid buffer overlap")
+	}
+
+	v := s.s2v(additionalData, nonce, plaintext)
+
+	s.crypt(out[bs:], plaintext, v)
+	copy(out, v)
+
+	return ret
+}
+
+func (s *siv) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+
+	bs := s.mac.BlockSize()
+
+	if len(ciphertext) < bs {
+		return nil, errOpen
+	}
+
+	v, ciphertext := ciphertext[:bs], ciphertext[bs:]
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	if inexactOverlap(out, ciphertext) {
+		panic("rc5: invalid buffer overlap")
+	}
+
+	// SIV authenticates the plaintext, so it is decrypted before the tag is
+	// checked and cleared if the check fails
+	s.crypt(out, ciphertext, v)
+
+	if subtle.ConstantTimeCompare(s.s2v(additionalData, nonce, out), v) != 1 {
+		clear(out)
+		return nil, errOpen
+	}
+
+	return ret, nil
+}
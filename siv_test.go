@@ -0,0 +1,95 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestSIVVector(t *testing.T) {
+
+	// RFC 5297 appendix A.1, which uses AES; S2V and the counter derivation
+	// only depend on the block size
+	key, _ := hex.DecodeString("fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	ad, _ := hex.DecodeString("101112131415161718191a1b1c1d1e1f2021222324252627")
+	plain, _ := hex.DecodeString("112233445566778899aabbccddee")
+	want, _ := hex.DecodeString("85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c")
+
+	mac, _ := aes.NewCipher(key[:16])
+	ctr, _ := aes.NewCipher(key[16:])
+	s := newSIV(mac, ctr)
+
+	got := s.Seal(nil, nil, plain, ad)
+	if !bytes.Equal(got, want) {
+		t.Errorf("SIV Seal:\ngot : % 02x\nwant: % 02x", got, want)
+	}
+
+	pt, err := s.Open(nil, nil, want, ad)
+	if err != nil || !bytes.Equal(pt, plain) {
+		t.Errorf("SIV Open: got % 02x, %v, want % 02x", pt, err, plain)
+	}
+}
+
+func TestSIV(t *testing.T) {
+
+	aead, err := NewSIV(seq(0, 32))
+	if err != nil {
+		t.Fatalf("NewSIV failed: %v", err)
+	}
+
+	for _, nonce := range [][]byte{nil, seq(0x40, 12)} {
+		for _, l := range []int{0, 1, 7, 8, 9, 40} {
+
+			msg := seq(0x80, l)
+			ad := seq(0xC0, 5)
+
+			ct := aead.Seal(nil, nonce, msg, ad)
+			if len(ct) != l+aead.Overhead() {
+				t.Errorf("Seal: got %d bytes, want %d", len(ct), l+aead.Overhead())
+			}
+
+			// deterministic for the same inputs
+			if ct2 := aead.Seal(nil, nonce, msg, ad); !bytes.Equal(ct, ct2) {
+				t.Errorf("SIV Seal (len=%d) not deterministic:\ngot : % 02x\nwant: % 02x", l, ct2, ct)
+			}
+
+			pt, err := aead.Open(nil, nonce, ct, ad)
+			if err != nil {
+				t.Fatalf("Open (len=%d) failed: %v", l, err)
+			}
+
+			if !bytes.Equal(pt, msg) {
+				t.Errorf("SIV round trip:\ngot : % 02x\nwant: % 02x", pt, msg)
+			}
+
+			for i := range ct {
+				ct[i] ^= 0x01
+				if _, err := aead.Open(nil, nonce, ct, ad); err != errOpen {
+					t.Errorf("Open (len=%d) with byte %d flipped: got %v, want %v", l, i, err, errOpen)
+				}
+				ct[i] ^= 0x01
+			}
+
+			if _, err := aead.Open(nil, nonce, ct, ad[1:]); err != errOpen {
+				t.Errorf("Open (len=%d) with modified additional data: got %v, want %v", l, err, errOpen)
+			}
+
+			if _, err := aead.Open(nil, seq(0x41, 12), ct, ad); err != errOpen {
+				t.Errorf("Open (len=%d) with another nonce: got %v, want %v", l, err, errOpen)
+			}
+		}
+	}
+
+	// a different plaintext gives a different synthetic IV
+	a := aead.Seal(nil, nil, []byte("message one"), nil)
+	b := aead.Seal(nil, nil, []byte("message two"), nil)
+	if bytes.Equal(a[:8], b[:8]) {
+		t.Errorf("SIV gave the same IV for different messages")
+	}
+
+	if _, err := NewSIV(seq(0, 16)); !errors.Is(err, KeySizeError(16)) {
+		t.Errorf("NewSIV with 16 byte key: got %v, want KeySizeError(16)", err)
+	}
+}
@@ -0,0 +1,54 @@
+package rc5
+
+import "crypto/cipher"
+
+// NewOFB returns a cipher.Stream which encrypts or decrypts with
+// RC5-32/12/16 in output feedback mode.  The length of iv must be the same as
+// the block size.
+func NewOFB(key, iv []byte) (cipher.Stream, error) {
+
+	block, err := newStreamBlock(key, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewOFB(block, iv), nil
+}
+
+// NewCFBEncrypter returns a cipher.Stream which encrypts with RC5-32/12/16 in
+// cipher feedback mode.  The length of iv must be the same as the block size.
+func NewCFBEncrypter(key, iv []byte) (cipher.Stream, error) {
+
+	block, err := newStreamBlock(key, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewCFBEncrypter(block, iv), nil
+}
+
+// NewCFBDecrypter returns a cipher.Stream which decrypts with RC5-32/12/16 in
+// cipher feedback mode.  The length of iv must be the same as the block size.
+func NewCFBDecrypter(key, iv []byte) (cipher.Stream, error) {
+
+	block, err := newStreamBlock(key, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewCFBDecrypter(block, iv), nil
+}
+
+func newStreamBlock(key, iv []byte) (cipher.Block, error) {
+
+	block, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(iv) != block.BlockSize() {
+		return nil, errIVSize
+	}
+
+	return block, nil
+}
@@ -0,0 +1,295 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// streamChunkSize is the amount of plaintext in each frame written by
+// EncryptStream.
+const streamChunkSize = 64 << 10
+
+// frame flags
+const (
+	frameFinal = 1 << iota
+	frameRekey // first frame under a new key
+)
+
+var (
+	errStreamTruncated = errors.New("rc5: encrypted stream truncated")
+	errStreamFrame     = errors.New("rc5: invalid encrypted stream frame")
+	errStreamTrailing  = errors.New("rc5: trailing data after encrypted stream")
+	errStreamRekey     = errors.New("rc5: encrypted stream rekey out of sync")
+	errRekeyBlocks     = errors.New("rc5: rekey interval must be positive")
+)
+
+// EncryptStream encrypts everything read from src with EAX over RC5-32/12/16
+// and writes it to dst in authenticated frames.  The output starts with a
+// random 8-byte stream identifier, followed by one frame per 64 KiB chunk of
+// plaintext: a flags byte, the 4-byte big-endian length of the rest of the
+// frame, and the chunk sealed under a fresh random nonce.  The stream
+// identifier, the chunk index and the flags are authenticated with each chunk,
+// and the last frame is flagged, so frames cannot be reordered, dropped or
+// moved between streams.  WithRand selects the source of the identifier and
+// nonces.
+func EncryptStream(dst io.Writer, src io.Reader, key []byte, opts ...RandOption) error {
+	return encryptStream(dst, src, key, 0, randReader(opts))
+}
+
+// DecryptStream authenticates and decrypts a stream written by EncryptStream
+// with the same key, writing the plaintext to dst.  Each chunk is written as
+// soon as it authenticates, so if DecryptStream returns an error the output so
+// far is incomplete and must be discarded.
+func DecryptStream(dst io.Writer, src io.Reader, key []byte) error {
+	return decryptStream(dst, src, key, 0)
+}
+
+// EncryptStreamRekeyed is like EncryptStream but limits the data sealed under
+// any one key to rekeyBlocks 8-byte blocks of plaintext.  Once that many
+// blocks have been sealed, the key is replaced by HKDF-SHA256 of the previous
+// key, salted with the stream identifier, and the first frame under the new
+// key carries a rekey flag.  Frames never span a rekey, so the last frame
+// under each key may be shorter than 64 KiB.
+func EncryptStreamRekeyed(dst io.Writer, src io.Reader, key []byte, rekeyBlocks int, opts ...RandOption) error {
+	if rekeyBlocks < 1 {
+		return errRekeyBlocks
+	}
+	return encryptStream(dst, src, key, int64(rekeyBlocks)*BlockSize, randReader(opts))
+}
+
+// DecryptStreamRekeyed authenticates and decrypts a stream written by
+// EncryptStreamRekeyed with the same key and rekeyBlocks, ratcheting its key
+// in step with the encrypter.  A rekey flag where none is expected, or a
+// missing one, means the two sides are out of sync and is an error.
+func DecryptStreamRekeyed(dst io.Writer, src io.Reader, key []byte, rekeyBlocks int) error {
+	if rekeyBlocks < 1 {
+		return errRekeyBlocks
+	}
+	return decryptStream(dst, src, key, int64(rekeyBlocks)*BlockSize)
+}
+
+// encryptStream writes the stream for EncryptStream, rekeying after every
+// rekeyBytes bytes of plaintext, or never if rekeyBytes is zero, and taking
+// the stream id and nonces from rnd.
+func encryptStream(dst io.Writer, src io.Reader, key []byte, rekeyBytes int64, rnd io.Reader) error {
+
+	key = append([]byte(nil), key...)
+	defer func() { clear(key) }()
+
+	aead, err := NewEAX(key)
+	if err != nil {
+		return err
+	}
+
+	var id [8]byte
+	if _, err := io.ReadFull(rnd, id[:]); err != nil {
+		return err
+	}
+
+	if _, err := dst.Write(id[:]); err != nil {
+		return err
+	}
+
+	// read one byte beyond the chunk so we know whether this is the last one
+	buf := make([]byte, streamChunkSize+1)
+	frame := make([]byte, 0, 5+aead.NonceSize()+streamChunkSize+aead.Overhead())
+
+	var n int
+	var eof, rekeyed bool
+	budget := rekeyBytes
+
+	for index := uint64(0); ; index++ {
+
+		chunk := streamChunkSize
+		if rekeyBytes > 0 {
+			chunk = int(min(int64(chunk), budget))
+		}
+
+		if !eof {
+			m, err := io.ReadFull(src, buf[n:chunk+1])
+			n += m
+			switch err {
+			case nil:
+			case io.EOF, io.ErrUnexpectedEOF:
+				eof = true
+			default:
+				return err
+			}
+		}
+
+		var flags byte
+		if eof {
+			flags |= frameFinal
+		}
+		if rekeyed {
+			flags |= frameRekey
+		}
+
+		frame = append(frame[:0], flags, 0, 0, 0, 0)
+		frame = append(frame, make([]byte, aead.NonceSize())...)
+
+		nonce := frame[5:]
+		if _, err := io.ReadFull(rnd, nonce); err != nil {
+			return err
+		}
+
+		sealed := min(n, chunk)
+		frame = aead.Seal(frame, nonce, buf[:sealed], streamAD(id, index, flags))
+		binary.BigEndian.PutUint32(frame[1:5], uint32(len(frame)-5))
+
+		if _, err := dst.Write(frame); err != nil {
+			return err
+		}
+
+		if eof {
+			return nil
+		}
+
+		n = copy(buf, buf[sealed:n])
+
+		budget -= int64(sealed)
+		rekeyed = rekeyBytes > 0 && budget == 0
+		if rekeyed {
+			if key, aead, err = rekeyStream(key, id); err != nil {
+				return err
+			}
+			budget = rekeyBytes
+		}
+	}
+}
+
+// decryptStream reads a stream written by encryptStream with the same
+// rekeyBytes.
+func decryptStream(dst io.Writer, src io.Reader, key []byte, rekeyBytes int64) error {
+
+	key = append([]byte(nil), key...)
+	defer func() { clear(key) }()
+
+	aead, err := NewEAX(key)
+	if err != nil {
+		return err
+	}
+
+	var knownFlags byte = frameFinal
+	if rekeyBytes > 0 {
+		knownFlags |= frameRekey
+	}
+
+	var id [8]byte
+	if _, err := io.ReadFull(src, id[:]); err != nil {
+		return streamReadError(err)
+	}
+
+	minFrame := aead.NonceSize() + aead.Overhead()
+	maxFrame := minFrame + streamChunkSize
+
+	frame := make([]byte, maxFrame)
+	var plain []byte
+
+	budget := rekeyBytes
+
+	for index := uint64(0); ; index++ {
+
+		var hdr [5]byte
+		if _, err := io.ReadFull(src, hdr[:]); err != nil {
+			return streamReadError(err)
+		}
+
+		flags := hdr[0]
+		if flags&^knownFlags != 0 {
+			return errStreamFrame
+		}
+
+		if rekeyBytes > 0 {
+			rekey := budget == 0
+			if rekey != (flags&frameRekey != 0) {
+				return errStreamRekey
+			}
+			if rekey {
+				if key, aead, err = rekeyStream(key, id); err != nil {
+					return err
+				}
+				budget = rekeyBytes
+			}
+		}
+
+		n := binary.BigEndian.Uint32(hdr[1:])
+		if n < uint32(minFrame) || n > uint32(maxFrame) {
+			return errStreamFrame
+		}
+
+		if _, err := io.ReadFull(src, frame[:n]); err != nil {
+			return streamReadError(err)
+		}
+
+		nonce, ciphertext := frame[:aead.NonceSize()], frame[aead.NonceSize():n]
+
+		plain, err = aead.Open(plain[:0], nonce, ciphertext, streamAD(id, index, flags))
+		if err != nil {
+			return err
+		}
+
+		if rekeyBytes > 0 {
+			if int64(len(plain)) > budget {
+				return errStreamRekey
+			}
+			budget -= int64(len(plain))
+		}
+
+		if _, err := dst.Write(plain); err != nil {
+			return err
+		}
+
+		if flags&frameFinal != 0 {
+			break
+		}
+	}
+
+	var b [1]byte
+	switch _, err := io.ReadFull(src, b[:]); err {
+	case io.EOF:
+		return nil
+	case nil:
+		return errStreamTrailing
+	default:
+		return err
+	}
+}
+
+// rekeyStream returns the key following key in the stream id, which replaces
+// and wipes key, and an AEAD using it.
+func rekeyStream(key []byte, id [8]byte) ([]byte, cipher.AEAD, error) {
+
+	next, err := hkdf.Key(sha256.New, key, id[:], "rc5 stream rekey", len(key))
+	clear(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aead, err := NewEAX(next)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return next, aead, nil
+}
+
+// streamAD returns the additional data authenticated with a frame.
+func streamAD(id [8]byte, index uint64, flags byte) []byte {
+	ad := append(id[:], 0, 0, 0, 0, 0, 0, 0, 0, flags)
+	binary.BigEndian.PutUint64(ad[8:], index)
+	return ad
+}
+
+// streamReadError maps the end of the input part way through a stream to
+// errStreamTruncated.
+func streamReadError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errStreamTruncated
+	}
+	return err
+}
@@ -0,0 +1,211 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"testing"
+)
+
+func TestEncryptStream(t *testing.T) {
+
+	key := tests[0].key
+
+	for _, l := range []int{0, 1, streamChunkSize - 1, streamChunkSize, streamChunkSize + 1, 3<<20 + 17} {
+
+		plain := make([]byte, l)
+		rand.Read(plain)
+
+		var ct bytes.Buffer
+		if err := EncryptStream(&ct, bytes.NewReader(plain), key); err != nil {
+			t.Fatalf("EncryptStream(len=%d) failed: %v", l, err)
+		}
+
+		frames := max(1, (l+streamChunkSize-1)/streamChunkSize)
+		if want := 8 + l + frames*(5+8+8); ct.Len() != want {
+			t.Errorf("EncryptStream(len=%d) wrote %d bytes, want %d", l, ct.Len(), want)
+		}
+
+		var p bytes.Buffer
+		if err := DecryptStream(&p, bytes.NewReader(ct.Bytes()), key); err != nil {
+			t.Fatalf("DecryptStream(len=%d) failed: %v", l, err)
+		}
+
+		if !bytes.Equal(p.Bytes(), plain) {
+			t.Errorf("stream round trip failed (len=%d)", l)
+		}
+	}
+}
+
+func TestDecryptStreamInvalid(t *testing.T) {
+
+	key := tests[0].key
+
+	plain := make([]byte, 2*streamChunkSize+100)
+	rand.Read(plain)
+
+	var buf bytes.Buffer
+	EncryptStream(&buf, bytes.NewReader(plain), key)
+	ct := buf.Bytes()
+
+	frameLen := 5 + 8 + streamChunkSize + 8
+
+	decrypt := func(ct []byte) error {
+		var p bytes.Buffer
+		return DecryptStream(&p, bytes.NewReader(ct), key)
+	}
+
+	// cut at a frame boundary, part way through a header and part way through
+	// a frame
+	for _, n := range []int{0, 4, 8, 8 + frameLen, 8 + frameLen + 3, 8 + frameLen + 100, len(ct) - 1} {
+		if err := decrypt(ct[:n]); err != errStreamTruncated {
+			t.Errorf("DecryptStream(truncated to %d): got %v, want %v", n, err, errStreamTruncated)
+		}
+	}
+
+	if err := decrypt(append(bytes.Clone(ct), 0)); err != errStreamTrailing {
+		t.Errorf("DecryptStream(trailing byte): got %v, want %v", err, errStreamTrailing)
+	}
+
+	// dropping a middle frame or swapping frames fails authentication
+	first, second := ct[8:8+frameLen], ct[8+frameLen:8+2*frameLen]
+	dropped := append(bytes.Clone(ct[:8+frameLen]), ct[8+2*frameLen:]...)
+	if err := decrypt(dropped); err != errOpen {
+		t.Errorf("DecryptStream(dropped frame): got %v, want %v", err, errOpen)
+	}
+
+	swapped := append(append(append(bytes.Clone(ct[:8]), second...), first...), ct[8+2*frameLen:]...)
+	if err := decrypt(swapped); err != errOpen {
+		t.Errorf("DecryptStream(swapped frames): got %v, want %v", err, errOpen)
+	}
+
+	// marking the first frame as final fails authentication
+	bad := bytes.Clone(ct)
+	bad[8] |= frameFinal
+	if err := decrypt(bad); err != errOpen {
+		t.Errorf("DecryptStream(flipped final flag): got %v, want %v", err, errOpen)
+	}
+
+	bad = bytes.Clone(ct)
+	bad[8] = 0x80
+	if err := decrypt(bad); err != errStreamFrame {
+		t.Errorf("DecryptStream(unknown flag): got %v, want %v", err, errStreamFrame)
+	}
+
+	bad = bytes.Clone(ct)
+	binary.BigEndian.PutUint32(bad[9:], uint32(frameLen))
+	if err := decrypt(bad); err != errStreamFrame {
+		t.Errorf("DecryptStream(oversized frame): got %v, want %v", err, errStreamFrame)
+	}
+
+	bad = bytes.Clone(ct)
+	bad[len(bad)-1] ^= 1
+	if err := decrypt(bad); err != errOpen {
+		t.Errorf("DecryptStream(corrupt tag): got %v, want %v", err, errOpen)
+	}
+}
+
+// streamFrames returns the flags of each frame of an encrypted stream.
+func streamFrames(ct []byte) []byte {
+	var flags []byte
+	for p := ct[8:]; len(p) >= 5; {
+		flags = append(flags, p[0])
+		p = p[5+binary.BigEndian.Uint32(p[1:]):]
+	}
+	return flags
+}
+
+func TestEncryptStreamRekeyed(t *testing.T) {
+
+	key := tests[0].key
+
+	for _, tst := range []struct {
+		l, blocks int
+		rekeys    int
+	}{
+		{0, 10, 0},
+		{80, 10, 0},
+		{81, 10, 1},
+		{1000, 10, 12},
+		{3 * streamChunkSize, 10000, 2},
+		{3 * streamChunkSize, 1 << 20, 0},
+	} {
+		plain := make([]byte, tst.l)
+		rand.Read(plain)
+
+		var ct bytes.Buffer
+		if err := EncryptStreamRekeyed(&ct, bytes.NewReader(plain), key, tst.blocks); err != nil {
+			t.Fatalf("EncryptStreamRekeyed(len=%d, blocks=%d) failed: %v", tst.l, tst.blocks, err)
+		}
+
+		rekeys := 0
+		for _, f := range streamFrames(ct.Bytes()) {
+			if f&frameRekey != 0 {
+				rekeys++
+			}
+		}
+		if rekeys != tst.rekeys {
+			t.Errorf("EncryptStreamRekeyed(len=%d, blocks=%d) rekeyed %d times, want %d", tst.l, tst.blocks, rekeys, tst.rekeys)
+		}
+
+		var p bytes.Buffer
+		if err := DecryptStreamRekeyed(&p, bytes.NewReader(ct.Bytes()), key, tst.blocks); err != nil {
+			t.Fatalf("DecryptStreamRekeyed(len=%d, blocks=%d) failed: %v", tst.l, tst.blocks, err)
+		}
+
+		if !bytes.Equal(p.Bytes(), plain) {
+			t.Errorf("rekeyed stream round trip failed (len=%d, blocks=%d)", tst.l, tst.blocks)
+		}
+	}
+
+	for _, blocks := range []int{0, -1} {
+		if err := EncryptStreamRekeyed(&bytes.Buffer{}, bytes.NewReader(nil), key, blocks); err != errRekeyBlocks {
+			t.Errorf("EncryptStreamRekeyed(blocks=%d): got %v, want %v", blocks, err, errRekeyBlocks)
+		}
+	}
+}
+
+func TestDecryptStreamRekeyedDesync(t *testing.T) {
+
+	key := tests[0].key
+
+	plain := make([]byte, 1000)
+	rand.Read(plain)
+
+	var buf bytes.Buffer
+	EncryptStreamRekeyed(&buf, bytes.NewReader(plain), key, 10)
+	ct := buf.Bytes()
+
+	decrypt := func(ct []byte, blocks int) error {
+		var p bytes.Buffer
+		return DecryptStreamRekeyed(&p, bytes.NewReader(ct), key, blocks)
+	}
+
+	for _, blocks := range []int{9, 11, 20} {
+		if err := decrypt(ct, blocks); err != errStreamRekey {
+			t.Errorf("DecryptStreamRekeyed(blocks=%d) of a 10 block stream: got %v, want %v", blocks, err, errStreamRekey)
+		}
+	}
+
+	frameLen := 5 + 8 + 80 + 8
+
+	// clearing the rekey flag on the second frame
+	bad := bytes.Clone(ct)
+	bad[8+frameLen] &^= frameRekey
+	if err := decrypt(bad, 10); err != errStreamRekey {
+		t.Errorf("DecryptStreamRekeyed(cleared rekey flag): got %v, want %v", err, errStreamRekey)
+	}
+
+	// a non-rekeying decrypter rejects the flag
+	var p bytes.Buffer
+	if err := DecryptStream(&p, bytes.NewReader(ct), key); err != errStreamFrame {
+		t.Errorf("DecryptStream of a rekeyed stream: got %v, want %v", err, errStreamFrame)
+	}
+
+	// and a rekeying decrypter never sees a rekey in a plain stream
+	buf.Reset()
+	EncryptStream(&buf, bytes.NewReader(plain), key)
+	if err := decrypt(buf.Bytes(), 10); err != errStreamRekey {
+		t.Errorf("DecryptStreamRekeyed of a plain stream: got %v, want %v", err, errStreamRekey)
+	}
+}
@@ -0,0 +1,236 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"errors"
+	"io"
+)
+
+var errClosed = errors.New("rc5: write to closed Writer")
+
+type writer struct {
+	w    io.Writer
+	mode cipher.BlockMode
+	buf  []byte    // pending plaintext, less than one block between writes
+	out  [512]byte // ciphertext of whole blocks taken straight from p
+	err  error
+}
+
+// NewWriter returns an io.WriteCloser which encrypts data written to it with
+// RC5-32/12/16 in the RC5-CBC-Pad mode of RFC 2040 and writes the ciphertext
+// to w.  Partial blocks are buffered until more data arrives, and Close
+// writes the final padded block.  Close does not close w.  The output is
+// identical to EncryptCBCPad of all the data written.
+func NewWriter(w io.Writer, key, iv []byte) (io.WriteCloser, error) {
+
+	block, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(iv) != block.BlockSize() {
+		return nil, errIVSize
+	}
+
+	return &writer{w: w, mode: cipher.NewCBCEncrypter(block, iv)}, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	bs := w.mode.BlockSize()
+
+	var n int
+
+	// complete a pending partial block first
+	if len(w.buf) > 0 {
+		need := bs - len(w.buf)
+		if len(p) < need {
+			w.buf = append(w.buf, p...)
+			return len(p), nil
+		}
+
+		w.buf = append(w.buf, p[:need]...)
+		w.mode.CryptBlocks(w.buf, w.buf)
+		if _, err := w.w.Write(w.buf); err != nil {
+			// the first need bytes of p are consumed either way
+			w.err = err
+			return need, err
+		}
+		w.buf = w.buf[:0]
+		n = need
+	}
+
+	for len(p)-n >= bs {
+		k := min(len(p)-n, len(w.out))
+		k -= k % bs
+		w.mode.CryptBlocks(w.out[:k], p[n:n+k])
+		if _, err := w.w.Write(w.out[:k]); err != nil {
+			w.err = err
+			return n, err
+		}
+		n += k
+	}
+
+	w.buf = append(w.buf, p[n:]...)
+
+	return len(p), nil
+}
+
+func (w *writer) Close() error {
+
+	if w.err != nil {
+		if w.err == errClosed {
+			return nil
+		}
+		return w.err
+	}
+
+	bs := w.mode.BlockSize()
+
+	padLen := bs - len(w.buf)
+	for i := 0; i < padLen; i++ {
+		w.buf = append(w.buf, byte(padLen))
+	}
+
+	w.mode.CryptBlocks(w.buf, w.buf)
+	if _, err := w.w.Write(w.buf); err != nil {
+		w.err = err
+		return err
+	}
+
+	w.err = errClosed
+
+	return nil
+}
+
+// An IncrementalCBC encrypts data written to it in pieces of any length with
+// RC5-32/12/16 in the RC5-CBC-Pad mode, collecting the ciphertext in memory.
+type IncrementalCBC struct {
+	w   *writer
+	out bytes.Buffer
+}
+
+// NewIncrementalCBC returns an IncrementalCBC for key and iv.  Complete
+// blocks are encrypted as they are written and partial blocks are buffered;
+// Close adds the padding and returns the whole ciphertext, which is identical
+// to EncryptCBCPad of all the data written.  To send the ciphertext to an
+// io.Writer as it is produced, use NewWriter.
+func NewIncrementalCBC(key, iv []byte) (*IncrementalCBC, error) {
+
+	x := &IncrementalCBC{}
+
+	w, err := NewWriter(&x.out, key, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	x.w = w.(*writer)
+
+	return x, nil
+}
+
+// Write encrypts p, buffering any final partial block.  It always returns
+// len(p) and a nil error, unless the IncrementalCBC has been closed.
+func (x *IncrementalCBC) Write(p []byte) (int, error) { return x.w.Write(p) }
+
+// Close encrypts the final, padded block and returns the ciphertext.  If the
+// data written is a whole number of blocks, the padding is a block of its
+// own.  Calling Close again returns the same ciphertext.
+func (x *IncrementalCBC) Close() ([]byte, error) {
+
+	if err := x.w.Close(); err != nil {
+		return nil, err
+	}
+
+	return x.out.Bytes(), nil
+}
+
+type reader struct {
+	r     io.Reader
+	mode  cipher.BlockMode
+	buf   []byte // ciphertext not yet decrypted
+	plain []byte // decrypted plaintext not yet returned
+	err   error
+}
+
+// NewReader returns an io.Reader which decrypts RC5-CBC-Pad ciphertext read
+// from r, as written by NewWriter or EncryptCBCPad, and removes the padding.
+// The final block is held back until r returns io.EOF, and a malformed final
+// block is reported as an error instead of io.EOF.
+func NewReader(r io.Reader, key, iv []byte) (io.Reader, error) {
+
+	block, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(iv) != block.BlockSize() {
+		return nil, errIVSize
+	}
+
+	return &reader{r: r, mode: cipher.NewCBCDecrypter(block, iv)}, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.fill()
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+
+	return n, nil
+}
+
+// fill reads more ciphertext and decrypts every complete block except the
+// last, which may hold the padding.
+func (r *reader) fill() {
+
+	bs := r.mode.BlockSize()
+
+	var chunk [512]byte
+	n, err := r.r.Read(chunk[:])
+	r.buf = append(r.buf, chunk[:n]...)
+
+	if err == io.EOF {
+		if len(r.buf) == 0 || len(r.buf)%bs != 0 {
+			r.err = errInputSize
+			return
+		}
+
+		r.mode.CryptBlocks(r.buf, r.buf)
+
+		padLen, err := checkPadding(r.buf[len(r.buf)-bs:])
+		if err != nil {
+			r.err = err
+			return
+		}
+
+		r.plain = r.buf[:len(r.buf)-padLen]
+		r.buf = nil
+		r.err = io.EOF
+		return
+	}
+
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	avail := len(r.buf) - bs
+	avail -= avail % bs
+	if avail > 0 {
+		r.plain = append(r.plain[:0], r.buf[:avail]...)
+		r.mode.CryptBlocks(r.plain, r.plain)
+		r.buf = append(r.buf[:0], r.buf[avail:]...)
+	}
+}
@@ -0,0 +1,207 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestWriterReader(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+	for _, l := range []int{0, 1, 7, 8, 9, 16, 100, 511, 512, 513, 5000} {
+
+		src := make([]byte, l)
+		rand.Read(src)
+
+		var ct bytes.Buffer
+		w, err := NewWriter(&ct, key, iv)
+		if err != nil {
+			t.Fatalf("NewWriter failed: %v", err)
+		}
+
+		// uneven chunks exercise the partial block buffering
+		for i, n := 0, 1; i < l; i, n = i+n, n+3 {
+			n = min(n, l-i)
+			if _, err := w.Write(src[i : i+n]); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+		}
+
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+
+		want, _ := EncryptCBCPad(key, iv, src)
+		if !bytes.Equal(ct.Bytes(), want) {
+			t.Errorf("NewWriter differs from EncryptCBCPad (len=%d):\ngot : % 02x\nwant: % 02x", l, ct.Bytes(), want)
+		}
+
+		// a short write followed by one large one spans several output
+		// chunks and must leave the caller's buffer alone
+		var big bytes.Buffer
+		w, _ = NewWriter(&big, key, iv)
+		orig := append([]byte(nil), src...)
+		w.Write(src[:min(3, l)])
+		w.Write(src[min(3, l):])
+		w.Close()
+		if !bytes.Equal(big.Bytes(), want) {
+			t.Errorf("large Write differs from EncryptCBCPad (len=%d):\ngot : % 02x\nwant: % 02x", l, big.Bytes(), want)
+		}
+		if !bytes.Equal(src, orig) {
+			t.Errorf("Write modified its input (len=%d)", l)
+		}
+
+		r, err := NewReader(iotest.OneByteReader(bytes.NewReader(ct.Bytes())), key, iv)
+		if err != nil {
+			t.Fatalf("NewReader failed: %v", err)
+		}
+
+		pt, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("ReadAll (len=%d) failed: %v", l, err)
+		}
+
+		if !bytes.Equal(pt, src) {
+			t.Errorf("Writer/Reader round trip (len=%d):\ngot : % 02x\nwant: % 02x", l, pt, src)
+		}
+
+		r, _ = NewReader(bytes.NewReader(ct.Bytes()), key, iv)
+		if err := iotest.TestReader(r, src); err != nil {
+			t.Errorf("TestReader (len=%d): %v", l, err)
+		}
+	}
+}
+
+func TestWriterClosed(t *testing.T) {
+
+	var ct bytes.Buffer
+	w, _ := NewWriter(&ct, tests[0].key, make([]byte, 8))
+	w.Close()
+
+	if _, err := w.Write([]byte{1}); err != errClosed {
+		t.Errorf("Write after Close: got %v, want %v", err, errClosed)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close: got %v, want nil", err)
+	}
+
+	if ct.Len() != 8 {
+		t.Errorf("ciphertext length after second Close: got %d, want 8", ct.Len())
+	}
+}
+
+func TestWriterError(t *testing.T) {
+
+	pr, pw := io.Pipe()
+	pr.Close()
+
+	w, _ := NewWriter(pw, tests[0].key, make([]byte, 8))
+
+	if n, err := w.Write(seq(0, 3)); n != 3 || err != nil {
+		t.Fatalf("buffered Write: got %d, %v, want 3, nil", n, err)
+	}
+
+	// completing the pending block takes 5 bytes from p before the write fails
+	if n, err := w.Write(seq(3, 20)); n != 5 || err != io.ErrClosedPipe {
+		t.Errorf("Write completing a block: got %d, %v, want 5, %v", n, err, io.ErrClosedPipe)
+	}
+
+	if n, err := w.Write(seq(0, 8)); n != 0 || err != io.ErrClosedPipe {
+		t.Errorf("Write after error: got %d, %v, want 0, %v", n, err, io.ErrClosedPipe)
+	}
+
+	if err := w.Close(); err != io.ErrClosedPipe {
+		t.Errorf("Close after error: got %v, want %v", err, io.ErrClosedPipe)
+	}
+
+	// with nothing pending, a failed write of whole blocks consumes nothing
+	w, _ = NewWriter(pw, tests[0].key, make([]byte, 8))
+	if n, err := w.Write(seq(0, 20)); n != 0 || err != io.ErrClosedPipe {
+		t.Errorf("Write of whole blocks: got %d, %v, want 0, %v", n, err, io.ErrClosedPipe)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+
+	key, iv := tests[0].key, make([]byte, 8)
+
+	ct, _ := EncryptCBCPad(key, iv, []byte("hello, world"))
+
+	for _, tst := range []struct {
+		ct  []byte
+		err error
+	}{
+		{nil, errInputSize},
+		{ct[:len(ct)-1], errInputSize},
+		// a corrupt final block breaks the padding
+		{append(ct[:len(ct)-1:len(ct)-1], ct[len(ct)-1]^0x80), errPadding},
+	} {
+		r, _ := NewReader(bytes.NewReader(tst.ct), key, iv)
+		if _, err := io.ReadAll(r); err != tst.err {
+			t.Errorf("ReadAll(% 02x): got %v, want %v", tst.ct, err, tst.err)
+		}
+	}
+
+	if _, err := NewReader(nil, key, iv[:4]); err != errIVSize {
+		t.Errorf("NewReader with short IV: got %v, want %v", err, errIVSize)
+	}
+
+	if _, err := NewWriter(nil, key, iv[:4]); err != errIVSize {
+		t.Errorf("NewWriter with short IV: got %v, want %v", err, errIVSize)
+	}
+}
+
+func TestIncrementalCBC(t *testing.T) {
+
+	key := tests[0].key
+	iv := seq(0x40, 8)
+	data := seq(0, 100)
+
+	for _, l := range []int{0, 1, 7, 8, 16, 37, 100} {
+
+		want, _ := EncryptCBCPad(key, iv, data[:l])
+
+		for _, chunk := range []int{1, 3, 5, 8, 13} {
+
+			x, err := NewIncrementalCBC(key, iv)
+			if err != nil {
+				t.Fatalf("NewIncrementalCBC failed: %v", err)
+			}
+
+			for p := data[:l]; len(p) > 0; {
+				k := min(chunk, len(p))
+				if n, err := x.Write(p[:k]); n != k || err != nil {
+					t.Fatalf("Write(%d bytes)=(%d, %v)", k, n, err)
+				}
+				p = p[k:]
+			}
+
+			got, err := x.Close()
+			if err != nil {
+				t.Fatalf("Close (len=%d, chunk=%d) failed: %v", l, chunk, err)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("IncrementalCBC (len=%d, chunk=%d):\ngot : % 02x\nwant: % 02x", l, chunk, got, want)
+			}
+
+			if again, err := x.Close(); err != nil || !bytes.Equal(again, want) {
+				t.Errorf("second Close (len=%d): got (% 02x, %v)", l, again, err)
+			}
+
+			if _, err := x.Write([]byte{1}); err != errClosed {
+				t.Errorf("Write after Close: got %v, want %v", err, errClosed)
+			}
+		}
+	}
+
+	if _, err := NewIncrementalCBC(key, iv[:7]); err != errIVSize {
+		t.Errorf("NewIncrementalCBC with a 7-byte IV: got %v, want %v", err, errIVSize)
+	}
+}
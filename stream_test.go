@@ -0,0 +1,105 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+)
+
+func TestOFB(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+	src := make([]byte, 100)
+	rand.Read(src)
+
+	s, err := NewOFB(key, iv)
+	if err != nil {
+		t.Fatalf("NewOFB failed: %v", err)
+	}
+
+	ct := make([]byte, len(src))
+	s.XORKeyStream(ct, src)
+
+	s, _ = NewOFB(key, iv)
+	pt := make([]byte, len(ct))
+	s.XORKeyStream(pt, ct)
+
+	if !bytes.Equal(pt, src) {
+		t.Errorf("OFB round trip:\ngot : % 02x\nwant: % 02x", pt, src)
+	}
+
+	// the keystream depends only on the key and iv
+	ks1, ks2 := make([]byte, 64), make([]byte, 64)
+	s, _ = NewOFB(key, iv)
+	s.XORKeyStream(ks1, ks1)
+	s, _ = NewOFB(key, iv)
+	s.XORKeyStream(ks2, ks2)
+
+	if !bytes.Equal(ks1, ks2) {
+		t.Errorf("OFB keystream not deterministic:\ngot : % 02x\nwant: % 02x", ks2, ks1)
+	}
+
+	// the first keystream block is the encrypted iv
+	block, _ := New(key)
+	want := make([]byte, 8)
+	block.Encrypt(want, iv)
+
+	if !bytes.Equal(ks1[:8], want) {
+		t.Errorf("OFB first keystream block:\ngot : % 02x\nwant: % 02x", ks1[:8], want)
+	}
+
+	if _, err := NewOFB(key, iv[:7]); err != errIVSize {
+		t.Errorf("NewOFB with short IV: got %v, want %v", err, errIVSize)
+	}
+}
+
+func TestCFB(t *testing.T) {
+
+	key := tests[0].key
+	iv := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+	for _, l := range []int{0, 1, 8, 13, 100} {
+
+		src := make([]byte, l)
+		rand.Read(src)
+
+		enc, err := NewCFBEncrypter(key, iv)
+		if err != nil {
+			t.Fatalf("NewCFBEncrypter failed: %v", err)
+		}
+
+		ct := make([]byte, l)
+		enc.XORKeyStream(ct, src)
+
+		block, _ := New(key)
+		want := make([]byte, l)
+		cipher.NewCFBEncrypter(block, iv).XORKeyStream(want, src)
+
+		if !bytes.Equal(ct, want) {
+			t.Errorf("NewCFBEncrypter (len=%d):\ngot : % 02x\nwant: % 02x", l, ct, want)
+		}
+
+		dec, err := NewCFBDecrypter(key, iv)
+		if err != nil {
+			t.Fatalf("NewCFBDecrypter failed: %v", err)
+		}
+
+		pt := make([]byte, l)
+		dec.XORKeyStream(pt, ct)
+
+		if !bytes.Equal(pt, src) {
+			t.Errorf("CFB round trip (len=%d):\ngot : % 02x\nwant: % 02x", l, pt, src)
+		}
+	}
+
+	if _, err := NewCFBEncrypter(key, make([]byte, 16)); err != errIVSize {
+		t.Errorf("NewCFBEncrypter with long IV: got %v, want %v", err, errIVSize)
+	}
+
+	if _, err := NewCFBDecrypter(key, nil); err != errIVSize {
+		t.Errorf("NewCFBDecrypter with nil IV: got %v, want %v", err, errIVSize)
+	}
+}
@@ -0,0 +1,136 @@
+package rc5
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"reflect"
+)
+
+var errFieldsTarget = errors.New("rc5: fields target must be a non-nil pointer to a struct")
+
+// A FieldTypeError is returned by EncryptFields and DecryptFields for a field
+// tagged rc5:"encrypt" which is not an exported string or []byte.
+type FieldTypeError struct {
+	Field string
+	Type  reflect.Type
+}
+
+func (e FieldTypeError) Error() string {
+	return "rc5: cannot encrypt field " + e.Field + " of type " + e.Type.String()
+}
+
+// EncryptFields encrypts, in place, each field of the struct pointed to by v
+// which is tagged rc5:"encrypt", using RC5-32/12/16 in the RC5-CBC-Pad mode
+// with a fresh random IV per field.  The IV is stored with the ciphertext: a
+// []byte field becomes the IV followed by the ciphertext, and a string field
+// the standard base64 encoding of the same, so it stays printable.  Untagged
+// fields, including nested structs, are left alone.  Tagged fields must be
+// exported and of type string or []byte.  The fields are not authenticated.
+// WithRand selects the source of the IVs.
+func EncryptFields(key []byte, v any, opts ...RandOption) error {
+	return cryptFields(key, v, false, randReader(opts))
+}
+
+// DecryptFields reverses EncryptFields with the same key.
+func DecryptFields(key []byte, v any) error {
+	return cryptFields(key, v, true, nil)
+}
+
+// cryptFields encrypts or decrypts the tagged fields of v, taking IVs from rnd
+// when encrypting.
+func cryptFields(key []byte, v any, decrypt bool, rnd io.Reader) error {
+
+	p := reflect.ValueOf(v)
+	if p.Kind() != reflect.Pointer || p.IsNil() || p.Elem().Kind() != reflect.Struct {
+		return errFieldsTarget
+	}
+
+	s := p.Elem()
+	t := s.Type()
+
+	// check every tagged field before changing any of them
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+
+		f := t.Field(i)
+		if f.Tag.Get("rc5") != "encrypt" {
+			continue
+		}
+
+		if !f.IsExported() || (f.Type.Kind() != reflect.String && f.Type != reflect.TypeOf([]byte(nil))) {
+			return FieldTypeError{f.Name, f.Type}
+		}
+
+		fields = append(fields, i)
+	}
+
+	out := make([][]byte, len(fields))
+	for j, i := range fields {
+
+		fv := s.Field(i)
+
+		var data []byte
+		if fv.Kind() == reflect.String {
+			data = []byte(fv.String())
+		} else {
+			data = fv.Bytes()
+		}
+
+		var err error
+		if decrypt {
+			out[j], err = decryptField(key, data, fv.Kind() == reflect.String)
+		} else {
+			out[j], err = encryptField(key, data, fv.Kind() == reflect.String, rnd)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for j, i := range fields {
+		fv := s.Field(i)
+		if fv.Kind() == reflect.String {
+			fv.SetString(string(out[j]))
+		} else {
+			fv.SetBytes(out[j])
+		}
+	}
+
+	return nil
+}
+
+func encryptField(key, data []byte, text bool, rnd io.Reader) ([]byte, error) {
+
+	iv := make([]byte, BlockSize)
+	if _, err := io.ReadFull(rnd, iv); err != nil {
+		return nil, err
+	}
+
+	sealed, err := EncryptCBCPadAppend(iv, key, iv, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if text {
+		sealed = base64.StdEncoding.AppendEncode(nil, sealed)
+	}
+
+	return sealed, nil
+}
+
+func decryptField(key, data []byte, text bool) ([]byte, error) {
+
+	if text {
+		var err error
+		if data, err = base64.StdEncoding.AppendDecode(nil, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(data) < BlockSize {
+		return nil, errInputSize
+	}
+
+	return DecryptCBCPad(key, data[:BlockSize], data[BlockSize:])
+}
@@ -0,0 +1,107 @@
+package rc5
+
+import (
+	"bytes"
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+type testConfig struct {
+	Host     string
+	Password string `rc5:"encrypt"`
+	Token    []byte `rc5:"encrypt"`
+	Empty    string `rc5:"encrypt"`
+	Port     int
+	Comment  string `rc5:"other"`
+}
+
+func TestEncryptFields(t *testing.T) {
+
+	key := tests[0].key
+
+	orig := testConfig{
+		Host:     "db.example.com",
+		Password: "correct horse battery staple",
+		Token:    seq(0, 20),
+		Port:     5432,
+		Comment:  "not secret",
+	}
+
+	c := orig
+	c.Token = bytes.Clone(orig.Token)
+
+	if err := EncryptFields(key, &c); err != nil {
+		t.Fatalf("EncryptFields failed: %v", err)
+	}
+
+	if c.Host != orig.Host || c.Port != orig.Port || c.Comment != orig.Comment {
+		t.Errorf("EncryptFields changed untagged fields: %+v", c)
+	}
+
+	// the IV and padded ciphertext, base64 encoded for strings
+	if raw, err := base64.StdEncoding.DecodeString(c.Password); err != nil || len(raw) != 8+32 {
+		t.Errorf("encrypted Password %q: %d bytes, %v, want 40 bytes of base64", c.Password, len(raw), err)
+	}
+	if len(c.Token) != 8+24 {
+		t.Errorf("encrypted Token is %d bytes, want 32", len(c.Token))
+	}
+	if raw, _ := base64.StdEncoding.DecodeString(c.Empty); len(raw) != 8+8 {
+		t.Errorf("encrypted Empty is %d bytes, want 16", len(raw))
+	}
+
+	// a fresh IV each time
+	d := orig
+	EncryptFields(key, &d)
+	if d.Password == c.Password || bytes.Equal(d.Token, c.Token) {
+		t.Errorf("EncryptFields gave the same ciphertext twice")
+	}
+
+	if err := DecryptFields(key, &c); err != nil {
+		t.Fatalf("DecryptFields failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(c, orig) {
+		t.Errorf("DecryptFields:\ngot : %+v\nwant: %+v", c, orig)
+	}
+}
+
+func TestEncryptFieldsInvalid(t *testing.T) {
+
+	key := tests[0].key
+
+	for _, v := range []any{nil, testConfig{}, (*testConfig)(nil), new(int)} {
+		if err := EncryptFields(key, v); err != errFieldsTarget {
+			t.Errorf("EncryptFields(%T): got %v, want %v", v, err, errFieldsTarget)
+		}
+	}
+
+	type badType struct {
+		Name string `rc5:"encrypt"`
+		PIN  int    `rc5:"encrypt"`
+	}
+	b := badType{Name: "x", PIN: 1234}
+	if err := EncryptFields(key, &b); err != (FieldTypeError{"PIN", reflect.TypeOf(0)}) {
+		t.Errorf("EncryptFields with an int field: got %v, want FieldTypeError", err)
+	}
+	if b.Name != "x" {
+		t.Errorf("EncryptFields changed a field before failing: %q", b.Name)
+	}
+
+	type unexported struct {
+		secret string `rc5:"encrypt"`
+	}
+	if err := EncryptFields(key, &unexported{}); err != (FieldTypeError{"secret", reflect.TypeOf("")}) {
+		t.Errorf("EncryptFields with an unexported field: got %v, want FieldTypeError", err)
+	}
+
+	c := testConfig{Password: "not base64!", Token: seq(0, 16)}
+	if err := DecryptFields(key, &c); err == nil {
+		t.Errorf("DecryptFields of invalid base64 succeeded")
+	}
+
+	c = testConfig{Password: base64.StdEncoding.EncodeToString(seq(0, 4))}
+	if err := DecryptFields(key, &c); err != errInputSize {
+		t.Errorf("DecryptFields of a short field: got %v, want %v", err, errInputSize)
+	}
+}
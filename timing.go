@@ -0,0 +1,128 @@
+package rc5
+
+import (
+	"math"
+	"time"
+)
+
+// timingBatch is the number of encryptions timed together for one sample, to
+// rise above the resolution of the clock.
+const timingBatch = 64
+
+// Stats summarizes the time taken by Encrypt, in nanoseconds per block.
+type Stats struct {
+	Samples int
+
+	Mean, StdDev float64
+	Min, Max     float64
+
+	// The means for inputs whose first data-dependent rotation is by zero
+	// bits, by one less than the word size, and for random inputs.
+	ZeroRotation, MaxRotation, Random float64
+}
+
+// TimingProfile times Encrypt on samples batches of inputs and summarizes the
+// results.  The samples cycle through three input classes: blocks whose first
+// rotation amount is zero, blocks whose first rotation amount is the largest
+// possible, and blocks from a fixed pseudo-random sequence.  RC5's rotations
+// depend on the data, so on hardware where rotation time varies with the
+// amount, the class means differ; on common 64-bit CPUs they should agree to
+// within the noise.  This characterizes timing variability for a threat model;
+// it does not make RC5 constant time.
+func (c *Cipher) TimingProfile(samples int) Stats {
+
+	if samples <= 0 {
+		return Stats{}
+	}
+
+	bs := c.BlockSize()
+	u := bs / 2
+
+	var s1 uint64
+	switch c.w {
+	case 16:
+		s1 = uint64(c.rk16[1])
+	case 32:
+		s1 = uint64(c.rk32[1])
+	case 64:
+		s1 = c.rk64[1]
+	}
+
+	// the first rotation is by (B + S[1]) mod w, so choose B to make it r
+	block := func(dst []byte, a, r uint64) {
+		c.storeWord(dst[:u], a)
+		c.storeWord(dst[u:], r-s1)
+	}
+
+	var classes [3][]byte
+	for i := range classes {
+		classes[i] = make([]byte, timingBatch*bs)
+	}
+
+	rnd := uint64(0x9e3779b97f4a7c15)
+	for i := 0; i < timingBatch; i++ {
+		b := classes[0][i*bs : (i+1)*bs]
+		block(b, uint64(i)*0x0101010101010101, 0)
+
+		b = classes[1][i*bs : (i+1)*bs]
+		block(b, uint64(i)*0x0101010101010101, uint64(c.w-1))
+
+		// xorshift64
+		for j := 0; j < bs; j++ {
+			rnd ^= rnd << 13
+			rnd ^= rnd >> 7
+			rnd ^= rnd << 17
+			classes[2][i*bs+j] = byte(rnd)
+		}
+	}
+
+	dst := make([]byte, bs)
+
+	st := Stats{Samples: samples, Min: math.Inf(1), Max: math.Inf(-1)}
+	var sum, sumSq float64
+	var classSum [3]float64
+	var classN [3]int
+
+	for i := 0; i < samples; i++ {
+		k := i % len(classes)
+		src := classes[k]
+
+		start := time.Now()
+		for j := 0; j < len(src); j += bs {
+			c.Encrypt(dst, src[j:j+bs])
+		}
+		ns := float64(time.Since(start).Nanoseconds()) / timingBatch
+
+		sum += ns
+		sumSq += ns * ns
+		st.Min = min(st.Min, ns)
+		st.Max = max(st.Max, ns)
+		classSum[k] += ns
+		classN[k]++
+	}
+
+	n := float64(samples)
+	st.Mean = sum / n
+	st.StdDev = math.Sqrt(max(0, sumSq/n-st.Mean*st.Mean))
+
+	means := [3]*float64{&st.ZeroRotation, &st.MaxRotation, &st.Random}
+	for k, m := range means {
+		if classN[k] > 0 {
+			*m = classSum[k] / float64(classN[k])
+		}
+	}
+
+	return st
+}
+
+// storeWord stores the low c.w bits of v into b in the cipher's byte order.
+func (c *Cipher) storeWord(b []byte, v uint64) {
+	switch c.w {
+	case 16:
+		c.store16(b, uint16(v))
+	case 32:
+		c.store32(b, uint32(v))
+	case 64:
+		c.store64(b, v)
+	}
+}
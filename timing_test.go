@@ -0,0 +1,31 @@
+package rc5
+
+import "testing"
+
+func TestTimingProfile(t *testing.T) {
+
+	for _, w := range []int{16, 32, 64} {
+
+		c, _ := NewWithParameters(w, 12, tests[0].key)
+		rc := c.(*Cipher)
+
+		st := rc.TimingProfile(300)
+
+		if st.Samples != 300 {
+			t.Errorf("RC5-%d: Samples=%d, want 300", w, st.Samples)
+		}
+
+		if !(st.Min > 0 && st.Min <= st.Mean && st.Mean <= st.Max) {
+			t.Errorf("RC5-%d: inconsistent stats %+v", w, st)
+		}
+
+		if st.StdDev < 0 || st.ZeroRotation <= 0 || st.MaxRotation <= 0 || st.Random <= 0 {
+			t.Errorf("RC5-%d: missing stats %+v", w, st)
+		}
+	}
+
+	c, _ := New(tests[0].key)
+	if st := c.(*Cipher).TimingProfile(0); st != (Stats{}) {
+		t.Errorf("TimingProfile(0)=%+v, want zero Stats", st)
+	}
+}
@@ -0,0 +1,50 @@
+package rc5
+
+import "unsafe"
+
+// EncryptTokens encrypts each 8-byte token independently, returning a new
+// slice.  The cipher must have a 32-bit word size, so that its block is eight
+// bytes.
+//
+// This is ECB: equal tokens encrypt to equal ciphertexts.  That is acceptable
+// only when every token is distinct, such as unique identifiers; otherwise use
+// a mode with an IV.
+func (c *Cipher) EncryptTokens(tokens [][8]byte) [][8]byte {
+	out := c.tokens(tokens)
+	c.EncryptBlocks(out, out)
+	return tokens8(out)
+}
+
+// DecryptTokens reverses EncryptTokens, returning a new slice.
+func (c *Cipher) DecryptTokens(tokens [][8]byte) [][8]byte {
+	out := c.tokens(tokens)
+	c.DecryptBlocks(out, out)
+	return tokens8(out)
+}
+
+// tokens returns a copy of tokens as contiguous blocks.
+func (c *Cipher) tokens(tokens [][8]byte) []byte {
+
+	if c.w != 32 {
+		panic("rc5: tokens require an 8-byte block")
+	}
+
+	out := make([][8]byte, len(tokens))
+	copy(out, tokens)
+
+	if len(out) == 0 {
+		return nil
+	}
+
+	// an array of arrays has no padding, so the tokens are already laid out
+	// as consecutive blocks
+	return unsafe.Slice(&out[0][0], 8*len(out))
+}
+
+// tokens8 reverses the view created by tokens.
+func tokens8(b []byte) [][8]byte {
+	if len(b) == 0 {
+		return [][8]byte{}
+	}
+	return unsafe.Slice((*[8]byte)(unsafe.Pointer(&b[0])), len(b)/8)
+}
@@ -0,0 +1,49 @@
+package rc5
+
+import (
+	"crypto/rand"
+	"slices"
+	"testing"
+)
+
+func TestTokens(t *testing.T) {
+
+	c, _ := New(tests[0].key)
+	rc := c.(*Cipher)
+
+	tokens := make([][8]byte, 100)
+	for i := range tokens {
+		rand.Read(tokens[i][:])
+	}
+	orig := slices.Clone(tokens)
+
+	ct := rc.EncryptTokens(tokens)
+
+	if !slices.Equal(tokens, orig) {
+		t.Fatal("EncryptTokens modified its input")
+	}
+
+	for i := range tokens {
+		var want [8]byte
+		c.Encrypt(want[:], tokens[i][:])
+		if ct[i] != want {
+			t.Errorf("token %d:\ngot : % 02x\nwant: % 02x", i, ct[i], want)
+		}
+	}
+
+	if p := rc.DecryptTokens(ct); !slices.Equal(p, tokens) {
+		t.Errorf("DecryptTokens did not round trip")
+	}
+
+	if got := rc.EncryptTokens(nil); len(got) != 0 {
+		t.Errorf("EncryptTokens(nil) returned %d tokens", len(got))
+	}
+
+	c64, _ := NewWithParameters(64, 12, tests[0].key)
+	defer func() {
+		if recover() == nil {
+			t.Error("EncryptTokens with a 16-byte block did not panic")
+		}
+	}()
+	c64.(*Cipher).EncryptTokens(tokens)
+}
@@ -0,0 +1,52 @@
+package rc5
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+var errTweakBlock = errors.New("rc5: tweaked encryption requires exactly one block")
+
+// EncryptTweaked encrypts the 8-byte block in place with RC5-32/12/16 under
+// the given tweak, such as a record or sector number, so that equal blocks at
+// different positions encrypt differently.  In the XEX style, the tweak is
+// encrypted to give a whitening value T and the block becomes E(block^T)^T.
+//
+// EncryptTweaked is experimental: the construction has not been analysed and
+// its output may change.
+func EncryptTweaked(key []byte, tweak uint64, block []byte) error {
+	return cryptTweaked(key, tweak, block, false)
+}
+
+// DecryptTweaked reverses EncryptTweaked with the same key and tweak.  It is
+// experimental, as is EncryptTweaked.
+func DecryptTweaked(key []byte, tweak uint64, block []byte) error {
+	return cryptTweaked(key, tweak, block, true)
+}
+
+func cryptTweaked(key []byte, tweak uint64, block []byte, decrypt bool) error {
+
+	b, err := New(key)
+	if err != nil {
+		return err
+	}
+
+	if len(block) != b.BlockSize() {
+		return errTweakBlock
+	}
+
+	var t [8]byte
+	binary.BigEndian.PutUint64(t[:], tweak)
+	b.Encrypt(t[:], t[:])
+
+	subtle.XORBytes(block, block, t[:])
+	if decrypt {
+		b.Decrypt(block, block)
+	} else {
+		b.Encrypt(block, block)
+	}
+	subtle.XORBytes(block, block, t[:])
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package rc5
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncryptTweaked(t *testing.T) {
+
+	key := tests[0].key
+	plain := seq(0x80, 8)
+
+	seen := make(map[string]uint64)
+
+	for _, tweak := range []uint64{0, 1, 2, 1 << 32, 1<<64 - 1} {
+
+		block := bytes.Clone(plain)
+		if err := EncryptTweaked(key, tweak, block); err != nil {
+			t.Fatalf("EncryptTweaked failed: %v", err)
+		}
+
+		if bytes.Equal(block, plain) {
+			t.Errorf("EncryptTweaked(tweak=%d) left the block unchanged", tweak)
+		}
+
+		if prev, ok := seen[string(block)]; ok {
+			t.Errorf("tweaks %d and %d give the same ciphertext % 02x", prev, tweak, block)
+		}
+		seen[string(block)] = tweak
+
+		if err := DecryptTweaked(key, tweak, block); err != nil {
+			t.Fatalf("DecryptTweaked failed: %v", err)
+		}
+
+		if !bytes.Equal(block, plain) {
+			t.Errorf("tweaked round trip (tweak=%d):\ngot : % 02x\nwant: % 02x", tweak, block, plain)
+		}
+	}
+
+	for _, l := range []int{0, 7, 16} {
+		if err := EncryptTweaked(key, 0, make([]byte, l)); err != errTweakBlock {
+			t.Errorf("EncryptTweaked with %d byte block: got %v, want %v", l, err, errTweakBlock)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package rc5
+
+// encrypt32r12 and decrypt32r12 are RC5-32/12 with the round loop fully
+// unrolled, so each round key is loaded from a constant offset.
+
+func (c *Cipher) encrypt32r12(dst, src []byte) {
+
+	rk := c.rk32[:26:26]
+
+	A := c.load32(src[:4]) + rk[0]
+	B := c.load32(src[4:8]) + rk[1]
+
+	A = rotl(A^B, B) + rk[2]
+	B = rotl(B^A, A) + rk[3]
+	A = rotl(A^B, B) + rk[4]
+	B = rotl(B^A, A) + rk[5]
+	A = rotl(A^B, B) + rk[6]
+	B = rotl(B^A, A) + rk[7]
+	A = rotl(A^B, B) + rk[8]
+	B = rotl(B^A, A) + rk[9]
+	A = rotl(A^B, B) + rk[10]
+	B = rotl(B^A, A) + rk[11]
+	A = rotl(A^B, B) + rk[12]
+	B = rotl(B^A, A) + rk[13]
+	A = rotl(A^B, B) + rk[14]
+	B = rotl(B^A, A) + rk[15]
+	A = rotl(A^B, B) + rk[16]
+	B = rotl(B^A, A) + rk[17]
+	A = rotl(A^B, B) + rk[18]
+	B = rotl(B^A, A) + rk[19]
+	A = rotl(A^B, B) + rk[20]
+	B = rotl(B^A, A) + rk[21]
+	A = rotl(A^B, B) + rk[22]
+	B = rotl(B^A, A) + rk[23]
+	A = rotl(A^B, B) + rk[24]
+	B = rotl(B^A, A) + rk[25]
+
+	c.store32(dst[:4], A)
+	c.store32(dst[4:8], B)
+}
+
+func (c *Cipher) decrypt32r12(dst, src []byte) {
+
+	rk := c.rk32[:26:26]
+
+	A := c.load32(src[:4])
+	B := c.load32(src[4:8])
+
+	B = rotr(B-rk[25], A) ^ A
+	A = rotr(A-rk[24], B) ^ B
+	B = rotr(B-rk[23], A) ^ A
+	A = rotr(A-rk[22], B) ^ B
+	B = rotr(B-rk[21], A) ^ A
+	A = rotr(A-rk[20], B) ^ B
+	B = rotr(B-rk[19], A) ^ A
+	A = rotr(A-rk[18], B) ^ B
+	B = rotr(B-rk[17], A) ^ A
+	A = rotr(A-rk[16], B) ^ B
+	B = rotr(B-rk[15], A) ^ A
+	A = rotr(A-rk[14], B) ^ B
+	B = rotr(B-rk[13], A) ^ A
+	A = rotr(A-rk[12], B) ^ B
+	B = rotr(B-rk[11], A) ^ A
+	A = rotr(A-rk[10], B) ^ B
+	B = rotr(B-rk[9], A) ^ A
+	A = rotr(A-rk[8], B) ^ B
+	B = rotr(B-rk[7], A) ^ A
+	A = rotr(A-rk[6], B) ^ B
+	B = rotr(B-rk[5], A) ^ A
+	A = rotr(A-rk[4], B) ^ B
+	B = rotr(B-rk[3], A) ^ A
+	A = rotr(A-rk[2], B) ^ B
+
+	c.store32(dst[4:8], B-rk[1])
+	c.store32(dst[:4], A-rk[0])
+}
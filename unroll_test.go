@@ -0,0 +1,49 @@
+package rc5
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"testing"
+)
+
+func TestUnrolled(t *testing.T) {
+
+	for i := 0; i < 100; i++ {
+
+		key := make([]byte, 16)
+		rand.Read(key)
+
+		for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+
+			b, _ := NewWithByteOrder(order, key)
+			c := b.(*Cipher)
+
+			src := make([]byte, 8)
+			rand.Read(src)
+
+			var want, got [8]byte
+
+			c.encrypt32(want[:], src)
+			c.encrypt32r12(got[:], src)
+			if got != want {
+				t.Fatalf("encrypt32r12(% 02x)=% 02x, want % 02x", src, got[:], want[:])
+			}
+
+			c.decrypt32(want[:], src)
+			c.decrypt32r12(got[:], src)
+			if got != want {
+				t.Fatalf("decrypt32r12(% 02x)=% 02x, want % 02x", src, got[:], want[:])
+			}
+		}
+	}
+}
+
+func BenchmarkEncryptUnrolled(b *testing.B) {
+	block, _ := New(tests[0].key)
+	c := block.(*Cipher)
+	var buf [8]byte
+	b.SetBytes(8)
+	for i := 0; i < b.N; i++ {
+		c.encrypt32r12(buf[:], buf[:])
+	}
+}
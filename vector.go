@@ -0,0 +1,18 @@
+package rc5
+
+// EncryptVector encrypts a single block with RC5-32/12/16, for checking test
+// vectors against other implementations.  The key is 16 bytes, the only key
+// length New accepts, rather than the 8 bytes of the block.
+func EncryptVector(key [16]byte, plaintext [8]byte) [8]byte {
+
+	c, err := New(key[:])
+	if err != nil {
+		// a 16-byte key is always valid
+		panic(err)
+	}
+
+	var ct [8]byte
+	c.Encrypt(ct[:], plaintext[:])
+
+	return ct
+}
@@ -0,0 +1,37 @@
+package rc5
+
+import "testing"
+
+func TestEncryptVector(t *testing.T) {
+
+	// The NESSIE vectors used throughout the tests, and the examples from
+	// Rivest's RC5 paper that SelfTest checks and OpenSSL's rc5test.c also
+	// uses.
+	type vector struct {
+		key           [16]byte
+		plain, cipher [8]byte
+	}
+
+	var vectors []vector
+	for _, tst := range tests {
+		vectors = append(vectors, vector{[16]byte(tst.key), [8]byte(tst.plain), [8]byte(tst.cipher)})
+	}
+	for _, v := range knownAnswers {
+		vectors = append(vectors, vector{v.key, v.plain, v.cipher})
+	}
+
+	for _, v := range vectors {
+
+		if got := EncryptVector(v.key, v.plain); got != v.cipher {
+			t.Errorf("EncryptVector(% 02x, % 02x):\ngot : % 02x\nwant: % 02x", v.key, v.plain, got, v.cipher)
+		}
+
+		c, _ := New(v.key[:])
+
+		var p [8]byte
+		c.Decrypt(p[:], v.cipher[:])
+		if p != v.plain {
+			t.Errorf("decrypt(% 02x, % 02x):\ngot : % 02x\nwant: % 02x", v.key, v.cipher, p, v.plain)
+		}
+	}
+}
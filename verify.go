@@ -0,0 +1,38 @@
+package rc5
+
+import (
+	"crypto/subtle"
+	"math/bits"
+)
+
+// VerifyTag reports whether actual equals expected, for checking the tag of a
+// custom authenticated mode built on this package.  Its running time depends
+// only on the length of expected, not on the contents of either slice nor on
+// whether their lengths differ, unlike subtle.ConstantTimeCompare on its own,
+// which returns early on a length mismatch.  The length of expected, the tag
+// the mode computes, is normally public.
+func VerifyTag(expected, actual []byte) bool {
+
+	// compare the contents against actual cut or zero-padded to the length
+	// of expected, so a length mismatch takes the same path as a content
+	// one.  Indexes past the end of actual read actual[0] instead and are
+	// masked out, so the loop costs the same whatever len(actual) is.
+	src := actual
+	if len(src) == 0 {
+		src = expected[:min(1, len(expected))]
+	}
+
+	var v byte
+	for i := range expected {
+		in := int(uint(i-len(actual)) >> (bits.UintSize - 1)) // 1 if i < len(actual)
+		a := src[subtle.ConstantTimeSelect(in, i, 0)]
+		v |= (expected[i] ^ a) | byte(in-1)
+	}
+	contentsEqual := subtle.ConstantTimeByteEq(v, 0)
+
+	// both halves of the 64-bit lengths, as ConstantTimeEq takes int32
+	le, la := uint64(len(expected)), uint64(len(actual))
+	lengthsEqual := subtle.ConstantTimeEq(int32(le), int32(la)) & subtle.ConstantTimeEq(int32(le>>32), int32(la>>32))
+
+	return lengthsEqual&contentsEqual == 1
+}
@@ -0,0 +1,39 @@
+package rc5
+
+import "testing"
+
+func TestVerifyTag(t *testing.T) {
+
+	tag := seq(0x40, 8)
+
+	for _, tst := range []struct {
+		name             string
+		expected, actual []byte
+		want             bool
+	}{
+		{"equal", tag, seq(0x40, 8), true},
+		{"empty", nil, []byte{}, true},
+		{"first byte", tag, append([]byte{0x41}, tag[1:]...), false},
+		{"last byte", tag, append(seq(0x40, 7), 0x00), false},
+		{"shorter", tag, tag[:7], false},
+		{"longer", tag, append(seq(0x40, 8), 0x48), false},
+		{"empty actual", tag, nil, false},
+		{"empty expected", nil, tag, false},
+		{"repeated prefix", []byte{1, 2, 1, 2}, []byte{1, 2}, false},
+		{"all zero", make([]byte, 8), nil, false},
+	} {
+		if got := VerifyTag(tst.expected, tst.actual); got != tst.want {
+			t.Errorf("VerifyTag (%s)=%v, want %v", tst.name, got, tst.want)
+		}
+	}
+}
+
+func TestVerifyTagAllocs(t *testing.T) {
+
+	tag := seq(0x40, 16)
+	actual := seq(0x40, 4)
+
+	if n := testing.AllocsPerRun(100, func() { VerifyTag(tag, actual) }); n != 0 {
+		t.Errorf("VerifyTag allocates %v times, want 0", n)
+	}
+}
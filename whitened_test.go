@@ -0,0 +1,46 @@
+package rc5
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWhitened(t *testing.T) {
+
+	for i, tst := range tests {
+
+		c, err := NewWhitened(tst.key)
+		if err != nil {
+			t.Fatalf("NewWhitened failed: %v", err)
+		}
+
+		ct := make([]byte, BlockSize)
+		c.Encrypt(ct, tst.plain)
+
+		if bytes.Equal(ct, tst.cipher) {
+			t.Errorf("%d: whitened ciphertext equals plain RC5: % 02x", i, ct)
+		}
+
+		pt := make([]byte, BlockSize)
+		c.Decrypt(pt, ct)
+
+		if !bytes.Equal(pt, tst.plain) {
+			t.Errorf("%d: whitened round trip:\ngot : % 02x\nwant: % 02x", i, pt, tst.plain)
+		}
+
+		// in place
+		b := bytes.Clone(tst.plain)
+		c.Encrypt(b, b)
+		if !bytes.Equal(b, ct) {
+			t.Errorf("%d: in-place Encrypt:\ngot : % 02x\nwant: % 02x", i, b, ct)
+		}
+		c.Decrypt(b, b)
+		if !bytes.Equal(b, tst.plain) {
+			t.Errorf("%d: in-place Decrypt:\ngot : % 02x\nwant: % 02x", i, b, tst.plain)
+		}
+	}
+
+	if _, err := NewWhitened(make([]byte, 15)); err == nil {
+		t.Errorf("NewWhitened accepted a 15-byte key")
+	}
+}
@@ -0,0 +1,99 @@
+package rc5
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+)
+
+// xtsRounds is the number of rounds of the RC5-64 cipher used by NewXTS.
+const xtsRounds = 24
+
+// XTS encrypts disk sectors in the XTS mode of IEEE 1619 using RC5-64/24 as
+// the 128-bit block cipher.
+type XTS struct {
+	k1, k2 cipher.Block
+}
+
+// NewXTS returns an XTS using RC5-64/24 keyed with key1 for the data and key2
+// for the tweak.  Each key may be between 0 and 255 bytes long, and the two
+// must be independent.
+func NewXTS(key1, key2 []byte) (*XTS, error) {
+
+	k1, err := NewWithParameters(64, xtsRounds, key1)
+	if err != nil {
+		return nil, err
+	}
+
+	k2, err := NewWithParameters(64, xtsRounds, key2)
+	if err != nil {
+		return nil, err
+	}
+
+	return newXTS(k1, k2), nil
+}
+
+func newXTS(k1, k2 cipher.Block) *XTS {
+	return &XTS{k1: k1, k2: k2}
+}
+
+// Encrypt encrypts the sector with the given number from src into dst.  The
+// length of src must be a non-zero multiple of the 16-byte block size; partial
+// blocks (ciphertext stealing) are not supported.  Dst and src must overlap
+// entirely or not at all.
+func (x *XTS) Encrypt(sector uint64, dst, src []byte) {
+	x.crypt(sector, dst, src, false)
+}
+
+// Decrypt decrypts the sector with the given number from src into dst, with
+// the same requirements as Encrypt.
+func (x *XTS) Decrypt(sector uint64, dst, src []byte) {
+	x.crypt(sector, dst, src, true)
+}
+
+func (x *XTS) crypt(sector uint64, dst, src []byte, decrypt bool) {
+
+	if len(src) < 16 || len(src)%16 != 0 {
+		panic("rc5: XTS sector not a multiple of the block size")
+	}
+
+	if len(dst) < len(src) {
+		panic("rc5: output smaller than input")
+	}
+
+	if inexactOverlap(dst[:len(src)], src) {
+		panic("rc5: invalid buffer overlap")
+	}
+
+	var tweak [16]byte
+	binary.LittleEndian.PutUint64(tweak[:8], sector)
+	x.k2.Encrypt(tweak[:], tweak[:])
+
+	for len(src) > 0 {
+		d := dst[:16]
+
+		subtle.XORBytes(d, src[:16], tweak[:])
+		if decrypt {
+			x.k1.Decrypt(d, d)
+		} else {
+			x.k1.Encrypt(d, d)
+		}
+		subtle.XORBytes(d, d, tweak[:])
+
+		mulAlpha(&tweak)
+
+		src, dst = src[16:], dst[16:]
+	}
+}
+
+// mulAlpha multiplies the little-endian GF(2^128) element t by the primitive
+// element x, reducing by x^128 + x^7 + x^2 + x + 1.
+func mulAlpha(t *[16]byte) {
+	var carry byte
+	for i := range t {
+		next := t[i] >> 7
+		t[i] = t[i]<<1 | carry
+		carry = next
+	}
+	t[0] ^= 0x87 & -carry
+}
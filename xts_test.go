@@ -0,0 +1,93 @@
+package rc5
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+func TestXTSVectors(t *testing.T) {
+
+	// IEEE 1619 vectors 1 and 2, which use AES; the XTS construction itself
+	// only depends on the 128-bit block size
+	for _, tst := range []struct {
+		key1, key2 byte
+		sector     uint64
+		plain      byte
+		out        string
+	}{
+		{0x00, 0x00, 0, 0x00, "917cf69ebd68b2ec9b9fe9a3eadda692cd43d2f59598ed858c02c2652fbf922e"},
+		{0x11, 0x22, 0x3333333333, 0x44, "c454185e6a16936e39334038acef838bfb186fff7480adc4289382ecd6d394f0"},
+	} {
+		k1, _ := aes.NewCipher(bytes.Repeat([]byte{tst.key1}, 16))
+		k2, _ := aes.NewCipher(bytes.Repeat([]byte{tst.key2}, 16))
+		x := newXTS(k1, k2)
+
+		plain := bytes.Repeat([]byte{tst.plain}, 32)
+		want, _ := hex.DecodeString(tst.out)
+
+		got := make([]byte, len(plain))
+		x.Encrypt(tst.sector, got, plain)
+		if !bytes.Equal(got, want) {
+			t.Errorf("XTS sector %x:\ngot : % 02x\nwant: % 02x", tst.sector, got, want)
+		}
+
+		x.Decrypt(tst.sector, got, got)
+		if !bytes.Equal(got, plain) {
+			t.Errorf("XTS decrypt sector %x:\ngot : % 02x\nwant: % 02x", tst.sector, got, plain)
+		}
+	}
+}
+
+func TestXTS(t *testing.T) {
+
+	x, err := NewXTS(seq(0, 16), seq(0x80, 16))
+	if err != nil {
+		t.Fatalf("NewXTS failed: %v", err)
+	}
+
+	for _, l := range []int{16, 32, 512, 4096} {
+
+		src := make([]byte, l)
+		rand.Read(src)
+
+		ct := make([]byte, l)
+		x.Encrypt(7, ct, src)
+
+		pt := make([]byte, l)
+		x.Decrypt(7, pt, ct)
+
+		if !bytes.Equal(pt, src) {
+			t.Errorf("XTS round trip (len=%d):\ngot : % 02x\nwant: % 02x", l, pt, src)
+		}
+
+		// the same plaintext in another sector encrypts differently
+		ct2 := make([]byte, l)
+		x.Encrypt(8, ct2, src)
+		if bytes.Equal(ct, ct2) {
+			t.Errorf("XTS (len=%d) sectors 7 and 8 give the same ciphertext", l)
+		}
+
+		// and so do equal blocks within a sector
+		zero := make([]byte, l)
+		x.Encrypt(7, ct, zero)
+		for i := 16; i < l; i += 16 {
+			if bytes.Equal(ct[:16], ct[i:i+16]) {
+				t.Errorf("XTS (len=%d) blocks 0 and %d give the same ciphertext", l, i/16)
+			}
+		}
+	}
+
+	for _, l := range []int{0, 8, 17, 31} {
+		func() {
+			defer func() {
+				if r := recover(); r != "rc5: XTS sector not a multiple of the block size" {
+					t.Errorf("XTS Encrypt with %d byte sector: got panic %v", l, r)
+				}
+			}()
+			x.Encrypt(0, make([]byte, l), make([]byte, l))
+		}()
+	}
+}